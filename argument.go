@@ -0,0 +1,60 @@
+package form
+
+import (
+	"reflect"
+)
+
+// ArgumentProvider is implemented by arguments of Decode routing a different argument to each registered
+// function, so that functions receive their own dependencies instead of one argument holding all of them,
+// see FuncArguments. Arguments of Encoder.EncodeWithArgument implementing ArgumentProvider[interface{}]
+// are routed the same way.
+type ArgumentProvider[Argument any] interface {
+	// FuncArgument returns the argument of the function converting a value of typ, option is the tag option
+	// of a function registered with RegisterFuncByTag and empty for functions registered with RegisterFunc.
+	// typ is the converted type without pointers, eg. int of a *int field, for functions of both types and tags.
+	FuncArgument(typ reflect.Type, option string) Argument
+}
+
+// FuncArguments routes arguments to registered functions by the tag option of the function
+// or by the converted type without pointers, other functions receive Default, eg.
+//
+//	decoder.Decode(&v, values, form.FuncArguments[any]{
+//		Types: map[reflect.Type]any{reflect.TypeOf(Money{}): rates},
+//		Tags:  map[string]any{"tz": location},
+//	})
+type FuncArguments[Argument any] struct {
+	Types   map[reflect.Type]Argument
+	Tags    map[string]Argument
+	Default Argument
+}
+
+// FuncArgument returns the argument of the tag option, the type or Default.
+func (a FuncArguments[Argument]) FuncArgument(typ reflect.Type, option string) Argument {
+	if arg, ok := a.Tags[option]; ok && option != blank {
+		return arg
+	}
+
+	if arg, ok := a.Types[typ]; ok {
+		return arg
+	}
+
+	return a.Default
+}
+
+// funcArgument returns the argument of a function converting a value of typ, see ArgumentProvider.
+func (d *decoder[DecodeFuncArgument]) funcArgument(typ reflect.Type, option string) DecodeFuncArgument {
+	if d.argumentProvider != nil {
+		return d.argumentProvider.FuncArgument(typ, option)
+	}
+
+	return d.decodeFuncArgument
+}
+
+// funcArgument returns the argument of a function encoding a value of typ, see ArgumentProvider.
+func (e *encoder) funcArgument(typ reflect.Type, option string) interface{} {
+	if e.argumentProvider != nil {
+		return e.argumentProvider.FuncArgument(typ, option)
+	}
+
+	return e.argument
+}
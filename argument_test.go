@@ -0,0 +1,166 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type argumentMoney int64
+
+type argumentDeps struct {
+	scale int64
+}
+
+func (d *argumentDeps) FuncArgument(typ reflect.Type, option string) *argumentDeps {
+	if option == "upper" {
+		return nil
+	}
+
+	return d
+}
+
+func TestDecoder_FuncArguments(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Price argumentMoney   `form:"price"`
+		Items []argumentMoney `form:"items"`
+		Code  string          `form:"code,upper"`
+		Name  string          `form:"name,trim"`
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.RegisterFunc(func(s string, arg any) (interface{}, error) {
+		n, err := strconv.ParseInt(s, 10, 64)
+
+		return argumentMoney(n * arg.(int64)), err
+	}, reflect.TypeOf(argumentMoney(0)))
+	decoder.RegisterFuncByTag("upper", func(s string, arg any) (interface{}, error) {
+		return strings.ToUpper(s) + arg.(string), nil
+	})
+	decoder.RegisterFuncByTag("trim", func(s string, arg any) (interface{}, error) {
+		return strings.Trim(s, arg.(string)), nil
+	})
+
+	var test Test
+
+	err := decoder.Decode(&test, url.Values{
+		"price": {"2"},
+		"items": {"1", "3"},
+		"code":  {"ab"},
+		"name":  {"-joe-"},
+	}, FuncArguments[any]{
+		Types:   map[reflect.Type]any{reflect.TypeOf(argumentMoney(0)): int64(100)},
+		Tags:    map[string]any{"upper": "!"},
+		Default: "-",
+	})
+	NoError(t, err)
+	Equal(t, Test{Price: 200, Items: []argumentMoney{100, 300}, Code: "AB!", Name: "joe"}, test)
+
+	// arguments implementing ArgumentProvider of the decoder argument type
+	typed := NewDecoder[*argumentDeps]()
+	typed.RegisterFunc(func(s string, arg *argumentDeps) (interface{}, error) {
+		n, err := strconv.ParseInt(s, 10, 64)
+
+		return argumentMoney(n * arg.scale), err
+	}, reflect.TypeOf(argumentMoney(0)))
+	typed.RegisterFuncByTag("upper", func(s string, arg *argumentDeps) (interface{}, error) {
+		Nil(t, arg)
+
+		return strings.ToUpper(s), nil
+	})
+
+	test = Test{}
+
+	err = typed.Decode(&test, url.Values{"price": {"2"}, "code": {"ab"}}, &argumentDeps{scale: 10})
+	NoError(t, err)
+	Equal(t, argumentMoney(20), test.Price)
+	Equal(t, "AB", test.Code)
+}
+
+func TestEncoder_EncodeWithArgument(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Price argumentMoney `form:"price"`
+		Code  string        `form:"code,upper"`
+		Name  string        `form:"name"`
+	}
+
+	encoder := NewEncoder()
+	encoder.RegisterArgumentFunc(func(x interface{}, arg interface{}) (string, error) {
+		if arg == nil {
+			return strconv.FormatInt(int64(x.(argumentMoney)), 10), nil
+		}
+
+		scale, ok := arg.(int64)
+		if !ok {
+			return "", errors.New("invalid scale")
+		}
+
+		return strconv.FormatInt(int64(x.(argumentMoney))/scale, 10), nil
+	}, argumentMoney(0))
+	encoder.RegisterArgumentFuncByTag("upper", func(x interface{}, arg interface{}) (string, error) {
+		return strings.ToUpper(x.(string)) + arg.(string), nil
+	})
+
+	test := Test{Price: 200, Code: "ab", Name: "joe"}
+
+	values, err := encoder.EncodeWithArgument(test, FuncArguments[any]{
+		Types: map[reflect.Type]any{reflect.TypeOf(argumentMoney(0)): int64(100)},
+		Tags:  map[string]any{"upper": "!"},
+	})
+	NoError(t, err)
+	Equal(t, url.Values{"price": {"2"}, "code": {"AB!"}, "name": {"joe"}}, values)
+
+	values, err = encoder.EncodeWithArgument(test, "?")
+	NotNil(t, err)
+	Nil(t, values["price"])
+
+	// functions receive nil from other encoding methods
+	encoder.RegisterArgumentFuncByTag("upper", func(x interface{}, arg interface{}) (string, error) {
+		Nil(t, arg)
+
+		return strings.ToUpper(x.(string)), nil
+	})
+
+	values, err = encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"price": {"200"}, "code": {"AB"}, "name": {"joe"}}, values)
+}
+
+func TestFuncArguments_PointerFields(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Price *argumentMoney `form:"price,cents"`
+	}
+
+	args := FuncArguments[any]{Types: map[reflect.Type]any{reflect.TypeOf(argumentMoney(0)): int64(100)}}
+
+	decoder := NewDecoder[any]()
+	decoder.RegisterFuncByTag("cents", func(s string, arg any) (interface{}, error) {
+		n, err := strconv.ParseInt(s, 10, 64)
+
+		return argumentMoney(n * arg.(int64)), err
+	})
+
+	var test Test
+	NoError(t, decoder.Decode(&test, url.Values{"price": {"2"}}, args))
+	Equal(t, argumentMoney(200), *test.Price)
+
+	encoder := NewEncoder()
+	encoder.RegisterArgumentFuncByTag("cents", func(x interface{}, arg interface{}) (string, error) {
+		return strconv.FormatInt(int64(x.(argumentMoney))/arg.(int64), 10), nil
+	})
+
+	values, err := encoder.EncodeWithArgument(test, args)
+	NoError(t, err)
+	Equal(t, url.Values{"price": {"2"}}, values)
+}
@@ -0,0 +1,116 @@
+package form
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes decoded from human-readable sizes, eg. "10MB" or "1.5GiB".
+// Units are case-insensitive, KB, MB, GB, TB and PB are powers of 1000, KiB, MiB, GiB, TiB and PiB
+// are powers of 1024, numbers without unit are bytes.
+type ByteSize uint64
+
+// Common sizes.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+	PB          = 1000 * TB
+
+	KiB ByteSize = 1 << 10
+	MiB          = KiB << 10
+	GiB          = MiB << 10
+	TiB          = GiB << 10
+	PiB          = TiB << 10
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"pb":  PB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+	"pib": PiB,
+}
+
+// ParseByteSize parses a human-readable size, see ByteSize.
+func ParseByteSize(s string) (ByteSize, error) {
+	num := strings.TrimSpace(s)
+
+	i := strings.IndexFunc(num, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+
+	unit := blank
+	if i != -1 {
+		num, unit = num[:i], strings.ToLower(strings.TrimSpace(num[i:]))
+	}
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok || num == blank {
+		return 0, newError(ErrInvalidByteSize, "invalid byte size '%s'", s)
+	}
+
+	if n, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if n > math.MaxUint64/uint64(multiplier) {
+			return 0, newError(ErrInvalidByteSize, "byte size '%s' overflows", s)
+		}
+
+		return ByteSize(n) * multiplier, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, newError(ErrInvalidByteSize, "invalid byte size '%s'", s)
+	}
+
+	f *= float64(multiplier)
+	if f >= math.MaxUint64 {
+		return 0, newError(ErrInvalidByteSize, "byte size '%s' overflows", s)
+	}
+
+	return ByteSize(f), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, sizes are emitted as bytes so that they are read back exactly.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(b), 10), nil
+}
+
+// String returns size with the largest binary unit dividing it, eg. "1.5GiB" is "1536MiB".
+func (b ByteSize) String() string {
+	units := []struct {
+		name string
+		size ByteSize
+	}{{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}}
+
+	for _, u := range units {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
@@ -0,0 +1,94 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		expected ByteSize
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"10KB", 10 * KB},
+		{"10MB", 10 * MB},
+		{"10mb", 10 * MB},
+		{"1.5GiB", 3 * GiB / 2},
+		{"2 kib", 2 * KiB},
+		{"1TB", TB},
+		{"1PiB", PiB},
+	}
+
+	for _, tt := range tests {
+		size, err := ParseByteSize(tt.value)
+		NoError(t, err)
+		Equal(t, tt.expected, size)
+	}
+
+	for _, value := range []string{"", "MB", "10XB", "-1MB", "1.2.3KB", "20000PB"} {
+		_, err := ParseByteSize(value)
+		NotNil(t, err)
+		True(t, errors.Is(err, ErrInvalidByteSize))
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	t.Parallel()
+
+	Equal(t, "0B", ByteSize(0).String())
+	Equal(t, "1000B", KB.String())
+	Equal(t, "1536MiB", (3 * GiB / 2).String())
+	Equal(t, "2KiB", (2 * KiB).String())
+}
+
+func TestDecodeDurationAndByteSize(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Timeout  time.Duration `form:"timeout"`
+		Retry    *time.Duration
+		Legacy   time.Duration
+		Limit    ByteSize `form:"limit"`
+		Sizes    []ByteSize
+		Timeouts map[string]time.Duration
+	}
+
+	var test Test
+
+	d := NewDecoder[any]()
+	err := d.Decode(&test, url.Values{
+		"timeout":      {"30s"},
+		"Retry":        {"1m30s"},
+		"Legacy":       {"1000"},
+		"limit":        {"10MB"},
+		"Sizes":        {"1KiB", "2KiB"},
+		"Timeouts[db]": {"2h"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, 30*time.Second, test.Timeout)
+	Equal(t, 90*time.Second, *test.Retry)
+	Equal(t, time.Microsecond, test.Legacy)
+	Equal(t, 10*MB, test.Limit)
+	Equal(t, []ByteSize{KiB, 2 * KiB}, test.Sizes)
+	Equal(t, map[string]time.Duration{"db": 2 * time.Hour}, test.Timeouts)
+
+	err = d.Decode(&test, url.Values{"timeout": {"soon"}, "limit": {"big"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	True(t, errors.Is(errs["timeout"], ErrInvalidDuration))
+	True(t, errors.Is(errs["limit"], ErrInvalidByteSize))
+
+	values, err := NewEncoder().Encode(Test{Limit: 10 * MB})
+	NoError(t, err)
+	Equal(t, "10000000", values.Get("limit"))
+}
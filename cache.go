@@ -3,9 +3,11 @@ package form
 import (
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 var _ sort.Interface = cacheFields{}
@@ -27,23 +29,155 @@ func (s cacheFields) Swap(i, j int) {
 type cachedField struct {
 	idx               int
 	name              string
+	formerly          string
+	aliases           []string
+	discriminator     string
+	src               string
+	defaultValue      string // `default` tag of fields set when decoding without their values, see hasDefault
+	hasDefault        bool
+	hasBoolFormat     bool
+	boolTrue          string
+	boolFalse         string
+	timeLayout        string         // `layout` tag option of time values
+	location          *time.Location // `tz` tag option of time values without zone
+	unixUnit          time.Duration  // time values are seconds, milliseconds or nanoseconds since the Unix epoch
+	options           tagOptions
 	isAnonymous       bool
 	isOmitEmpty       bool
+	isOmitNil         bool
+	isCheckbox        bool
+	isEmptyZero       bool
+	isAppend          bool
+	isReset           bool
 	isExported        bool
-	sliceSeparator    byte
+	sliceSeparator    string
+	hasStyle          bool   // serialization set by tag options, styles set on the decoder or encoder do not apply
+	isDeepObject      bool   // nested names are bracketed, eg. "filter[role]"
+	isJoinedObject    bool   // object is a key-value list, eg. "filter=role,admin"
+	invalidOption     string // invalid tag option reported when decoding values of the field, eg. "style=x"
+	maxLen            int    // -1 for invalid `maxlen` tag option
+	constraints       *fieldConstraints
+	isStream          bool
+	isRaw             bool
+	embed             embedMode
+	shadowed          map[string]struct{}
+	isFlatten         bool
+	isRecursive       bool // the field is a struct nested in itself, which is not flattened by parameter styles
+	isUnexportedPtr   bool
+	flattenPrefix     string
 	hasExportedScalar bool
 	canSet            bool
 }
 
+// elemField returns options of the field applying to its slice, array and map elements.
+func (f cachedField) elemField() cachedField {
+	return cachedField{hasBoolFormat: f.hasBoolFormat, boolTrue: f.boolTrue, boolFalse: f.boolFalse,
+		timeLayout: f.timeLayout, location: f.location, unixUnit: f.unixUnit}
+}
+
+// embedMode specifies how fields of an embedded struct are named, set with `promote` or `namespace`
+// tag options of the embedded field. Embedded structs can also be flattened, see isFlattenType.
+type embedMode uint8
+
+const (
+	// embedDefault follows the encoder anonymous mode, the decoder accepts both promoted and namespaced names
+	embedDefault embedMode = iota
+
+	// embedPromote names fields as fields of the embedding struct
+	embedPromote
+
+	// embedNamespace names fields under the name of the embedded field, eg. "Address.City"
+	embedNamespace
+)
+
+// tagOptions is a list of options following the name in a field tag,
+// eg. `form:"name,omitempty,csv"`, options may have values `form:"name,key=value"`.
+type tagOptions []tagOption
+
+type tagOption struct {
+	name  string
+	value string
+}
+
+// A `match` option takes the rest of the tag as its value, so the pattern can contain commas,
+// eg. `form:"code,match=^[a-z]{2,3}$"`.
+func parseTagOptions(s string) tagOptions {
+	var opts tagOptions
+
+	for s != blank {
+		o := s
+		s = blank
+
+		if !strings.HasPrefix(o, "match=") {
+			if idx := strings.IndexByte(o, ','); idx != -1 {
+				o, s = o[:idx], o[idx+1:]
+			}
+		}
+
+		if o == blank {
+			continue
+		}
+
+		opt := tagOption{name: o}
+
+		if idx := strings.IndexByte(o, '='); idx != -1 {
+			opt.name = o[:idx]
+			opt.value = o[idx+1:]
+		}
+
+		opts = append(opts, opt)
+	}
+
+	return opts
+}
+
+func (o tagOptions) has(name string) bool {
+	_, ok := o.get(name)
+
+	return ok
+}
+
+func (o tagOptions) get(name string) (string, bool) {
+	for _, opt := range o {
+		if opt.name == name {
+			return opt.value, true
+		}
+	}
+
+	return blank, false
+}
+
 type cachedStruct struct {
 	hasExportedScalar bool
 	fields            cacheFields
+
+	// url.Values field with `remainder` option, it is not in fields
+	hasRemainder bool
+	remainderIdx int
+
+	// hooks implemented by the struct or pointer to it, see AfterDecoder and BeforeEncoder
+	hasAfterDecode  bool
+	hasBeforeEncode bool
+
+	// namespace of keys of the struct as the decoded or encoded value, see Namespace
+	namespace string
+}
+
+// cacheKey identifies parsed struct, the same type is parsed differently depending on mode and tag name.
+type cacheKey struct {
+	typ     reflect.Type
+	mode    Mode
+	tagName string
 }
 
 type structCacheMap struct {
-	m     atomic.Value // map[reflect.Type]*cachedStruct
-	lock  sync.Mutex
-	tagFn TagNameFunc
+	m      atomic.Value // map[cacheKey]*cachedStruct
+	lock   sync.Mutex
+	tagFn  TagNameFunc
+	nameFn NameTransform
+
+	// structs being parsed, recursive embedding gets the struct parsed so far
+	parsing map[cacheKey]*cachedStruct
 }
 
 // TagNameFunc allows for adding of a custom tag name parser.
@@ -51,21 +185,21 @@ type TagNameFunc func(field reflect.StructField) string
 
 func newStructCacheMap() *structCacheMap {
 	sc := new(structCacheMap)
-	sc.m.Store(make(map[reflect.Type]*cachedStruct))
+	sc.m.Store(make(map[cacheKey]*cachedStruct))
 
 	return sc
 }
 
-func (s *structCacheMap) Get(key reflect.Type) (value *cachedStruct, ok bool) {
-	value, ok = s.m.Load().(map[reflect.Type]*cachedStruct)[key]
+func (s *structCacheMap) Get(mode Mode, typ reflect.Type, tagName string) (value *cachedStruct, ok bool) {
+	value, ok = s.m.Load().(map[cacheKey]*cachedStruct)[cacheKey{typ: typ, mode: mode, tagName: tagName}]
 
 	return
 }
 
-func (s *structCacheMap) Set(key reflect.Type, value *cachedStruct) {
-	m := s.m.Load().(map[reflect.Type]*cachedStruct) //nolint:errcheck
+func (s *structCacheMap) Set(key cacheKey, value *cachedStruct) {
+	m := s.m.Load().(map[cacheKey]*cachedStruct) //nolint:errcheck
 
-	nm := make(map[reflect.Type]*cachedStruct, len(m)+1)
+	nm := make(map[cacheKey]*cachedStruct, len(m)+1)
 
 	for k, v := range m {
 		nm[k] = v
@@ -76,6 +210,50 @@ func (s *structCacheMap) Set(key reflect.Type, value *cachedStruct) {
 	s.m.Store(nm)
 }
 
+// precompute parses struct types reachable from typ.
+func (s *structCacheMap) precompute(mode Mode, typ reflect.Type, tagName string, seen map[reflect.Type]bool) {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+
+	if seen[typ] {
+		return
+	}
+
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Map:
+		s.precompute(mode, typ.Key(), tagName, seen)
+		s.precompute(mode, typ.Elem(), tagName, seen)
+
+	case reflect.Struct:
+		if isValueStruct(typ) {
+			return
+		}
+
+		cs, ok := s.Get(mode, typ, tagName)
+		if !ok {
+			cs = s.parseStruct(mode, typ, tagName)
+		}
+
+		for _, f := range cs.fields {
+			s.precompute(mode, typ.Field(f.idx).Type, tagName, seen)
+		}
+	}
+}
+
+// isParsing reports whether struct type or pointer to it is being parsed.
+func (s *structCacheMap) isParsing(mode Mode, typ reflect.Type, tagName string) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	_, ok := s.parsing[cacheKey{typ: typ, mode: mode, tagName: tagName}]
+
+	return ok
+}
+
 func (s *structCacheMap) parseStruct(mode Mode, typ reflect.Type, tagName string) *cachedStruct {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -86,21 +264,42 @@ func (s *structCacheMap) parseStruct(mode Mode, typ reflect.Type, tagName string
 func (s *structCacheMap) ps(mode Mode, typ reflect.Type, tagName string) (cs *cachedStruct) {
 	// could have been multiple trying to access, but once first is done this ensures struct
 	// isn't parsed again.
-	cs, ok := s.Get(typ)
+	cs, ok := s.Get(mode, typ, tagName)
 	if ok {
 		return cs
 	}
 
-	cs = &cachedStruct{}
-	defer s.Set(typ, cs)
+	key := cacheKey{typ: typ, mode: mode, tagName: tagName}
+
+	if cs, ok = s.parsing[key]; ok {
+		return cs
+	}
 
 	if typ.Kind() == reflect.Ptr {
-		s := s.ps(mode, typ.Elem(), tagName)
-		*cs = *s
+		elem := s.ps(mode, typ.Elem(), tagName)
+
+		// a pointer to a struct embedded in itself is not cached with the incomplete struct
+		if s.isParsing(mode, typ.Elem(), tagName) {
+			return elem
+		}
+
+		cs = &cachedStruct{}
+		*cs = *elem
+		s.Set(key, cs)
 
 		return cs
 	}
 
+	cs = &cachedStruct{}
+	defer s.Set(key, cs)
+
+	if s.parsing == nil {
+		s.parsing = make(map[cacheKey]*cachedStruct)
+	}
+
+	s.parsing[key] = cs
+	defer delete(s.parsing, key)
+
 	if typ.Kind() == reflect.Interface {
 		cs.hasExportedScalar = true
 
@@ -111,6 +310,10 @@ func (s *structCacheMap) ps(mode Mode, typ reflect.Type, tagName string) (cs *ca
 		return cs
 	}
 
+	cs.hasAfterDecode = hasAfterDecode(typ)
+	cs.hasBeforeEncode = hasBeforeEncode(typ)
+	cs.namespace = typeNamespace(typ)
+
 	numFields := typ.NumField()
 
 	var (
@@ -118,14 +321,16 @@ func (s *structCacheMap) ps(mode Mode, typ reflect.Type, tagName string) (cs *ca
 		name           string
 		idx            int
 		isOmitEmpty    bool
-		sliceSeparator byte
+		sliceSeparator string
+		options        tagOptions
 	)
 
 	hasExportedScalar := false
 
 	for i := 0; i < numFields; i++ {
 		isOmitEmpty = false
-		sliceSeparator = 0
+		sliceSeparator = blank
+		options = nil
 		fld = typ.Field(i)
 
 		if fld.PkgPath != blank && !fld.Anonymous {
@@ -146,46 +351,169 @@ func (s *structCacheMap) ps(mode Mode, typ reflect.Type, tagName string) (cs *ca
 			continue
 		}
 
-		// check for omitempty
-		if idx = strings.LastIndexByte(name, ','); idx != -1 {
-			isOmitEmpty = name[idx+1:] == "omitempty"
+		// check for omitempty and other options
+		if idx = strings.IndexByte(name, ','); idx != -1 {
+			options = parseTagOptions(name[idx+1:])
+			isOmitEmpty = options.has("omitempty")
 			name = name[:idx]
 		}
 
+		// a Namespace field only sets the namespace of the struct
+		if fld.Type == namespaceType {
+			if len(name) > 0 {
+				cs.namespace = name
+			}
+
+			continue
+		}
+
 		// add support for OAS Swagger 2.0 collectionFormat
 		// https://github.com/OAI/OpenAPI-Specification/blob/master/schemas/v2.0/schema.json#L1528
 		if cf := fld.Tag.Get("collectionFormat"); cf != "" {
 			switch cf {
 			case "csv":
-				sliceSeparator = ','
+				sliceSeparator = ","
 			case "tsv":
-				sliceSeparator = '\t'
+				sliceSeparator = "\t"
 			case "ssv":
-				sliceSeparator = ' '
+				sliceSeparator = " "
 			case "pipes":
-				sliceSeparator = '|'
+				sliceSeparator = "|"
 			}
 		}
 
 		if len(name) == 0 {
 			name = fld.Name
+
+			if s.nameFn != nil {
+				name = s.nameFn(name)
+			}
 		}
 
 		cf := cachedField{}
 		cf.idx = i
 		cf.name = name
+		cf.options = options
+		cf.formerly, _ = options.get("formerly")
+		cf.aliases = fieldAliases(fld, options)
+		cf.discriminator, _ = options.get("discriminator")
+		cf.src, _ = options.get("src")
+		cf.defaultValue, cf.hasDefault = fld.Tag.Lookup(defaultTag)
+
+		// `bool=on|off` sets strings the encoder emits for true and false, the decoder accepts them as well
+		if format, ok := options.get("bool"); ok {
+			if i := strings.IndexByte(format, '|'); i != -1 {
+				cf.hasBoolFormat = true
+				cf.boolTrue = format[:i]
+				cf.boolFalse = format[i+1:]
+			}
+		}
+
+		// `layout` and `tz` set the format and zone of time values, eg. "2006-01-02T15:04" of HTML
+		// datetime-local inputs
+		cf.timeLayout, _ = options.get("layout")
+
+		if tz, ok := options.get("tz"); ok {
+			if loc, err := time.LoadLocation(tz); err == nil {
+				cf.location = loc
+			} else {
+				cf.invalidOption = "tz=" + tz
+			}
+		}
+
+		// `unix`, `unixmilli` and `unixnano` convert time values to and from integer timestamps
+		switch {
+		case options.has("unix"):
+			cf.unixUnit = time.Second
+		case options.has("unixmilli"):
+			cf.unixUnit = time.Millisecond
+		case options.has("unixnano"):
+			cf.unixUnit = time.Nanosecond
+		}
+
+		if ml, ok := options.get("maxlen"); ok {
+			// invalid limits are reported when decoding instead of being ignored
+			if cf.maxLen, _ = strconv.Atoi(ml); cf.maxLen <= 0 {
+				cf.maxLen = -1
+			}
+		}
+
+		cf.constraints = parseConstraints(fld.Type, options)
+		cf.isStream = fld.Type.Kind() == reflect.Func && options.has("stream")
+		cf.isRaw = options.has("raw") && isRawType(fld.Type)
+
+		if fld.Anonymous {
+			switch {
+			case options.has("promote"):
+				cf.embed = embedPromote
+			case options.has("namespace"):
+				cf.embed = embedNamespace
+			}
+		}
+
+		// struct fields with `prefix=` or `flatten` are named as fields of the parent, eg. "addr_city"
+		if isFlattenType(fld.Type) {
+			if prefix, ok := options.get("prefix"); ok {
+				cf.isFlatten = true
+				cf.flattenPrefix = prefix
+			} else if options.has("flatten") {
+				cf.isFlatten = true
+				cf.flattenPrefix = name + "_"
+			}
+		}
 		cf.isAnonymous = fld.Anonymous
 		cf.isExported = fld.PkgPath == ""
 		cf.isOmitEmpty = isOmitEmpty
+		cf.isOmitNil = options.has("omitnil")
+		cf.isCheckbox = options.has("checkbox") && isBoolType(fld.Type)
+		cf.isEmptyZero = options.has("emptyzero") && isNumberType(fld.Type)
+		cf.isAppend = options.has("append") && isSliceType(fld.Type)
+		cf.isReset = options.has("reset") && isSliceType(fld.Type)
 		cf.sliceSeparator = sliceSeparator
+		cf.hasStyle = sliceSeparator != blank
 		cf.canSet = true
+		cf.isRecursive = isFlattenType(fld.Type) && isRecursiveType(fld.Type)
+
+		// OpenAPI parameter styles, eg. `form:"ids,explode=false"` for comma-separated values,
+		// other delimiters are set with `delim`
+		switch style, explode, valid := parseParamStyle(options); {
+		case !valid:
+			styleName, _ := options.get("style")
+			cf.invalidOption = "style=" + styleName
+		case style != ParamNative:
+			cf = styleField(cf, fld.Type, style, explode)
+			cf.hasStyle = true
+		}
+
+		if delim, ok := options.get("delim"); ok && delim != blank {
+			elem := fld.Type
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+
+			// only slices and arrays are split, values of other fields would be truncated
+			if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+				cf.sliceSeparator = delim
+				cf.hasStyle = true
+			} else {
+				cf.invalidOption = "delim=" + delim
+			}
+		}
 
 		//if fld.Type.Kind() == reflect.Interface && fld.Type.NumMethod() > 0 {
 		//	cf.canSet = false
 		//}
 
+		// pointers to unexported embedded structs cannot be set, fields are promoted through existing ones
 		if cf.isAnonymous && !cf.isExported && fld.Type.Kind() == reflect.Ptr {
-			cf.canSet = false
+			cf.isUnexportedPtr = true
+		}
+
+		if cf.isExported && fld.Type == urlValuesType && options.has("remainder") {
+			cs.hasRemainder = true
+			cs.remainderIdx = i
+
+			continue
 		}
 
 		if cf.isAnonymous && !cf.hasExportedScalar {
@@ -193,6 +521,11 @@ func (s *structCacheMap) ps(mode Mode, typ reflect.Type, tagName string) (cs *ca
 			if cs.hasExportedScalar {
 				cf.hasExportedScalar = true
 			}
+
+			// a struct embedded in itself cannot be promoted, its fields are namespaced
+			if s.isParsing(mode, fld.Type, tagName) {
+				cf.embed = embedNamespace
+			}
 		}
 
 		if (len(name) > 0 && cf.isExported && !cf.isAnonymous) || cf.hasExportedScalar {
@@ -204,5 +537,95 @@ func (s *structCacheMap) ps(mode Mode, typ reflect.Type, tagName string) (cs *ca
 
 	cs.hasExportedScalar = hasExportedScalar
 
+	// names of promoted fields of embedded structs which are also names of fields of the struct
+	for i := range cs.fields {
+		if f := &cs.fields[i]; f.isAnonymous && !f.isFlatten && f.embed != embedNamespace {
+			f.shadowed = s.shadowedNames(mode, typ.Field(f.idx).Type, tagName, cs.fields, nil)
+		}
+	}
+
 	return cs
 }
+
+// fieldAliases returns alternative names the decoder accepts for a field, from `formalias:"uid,userId"` tag,
+// `alt=` tag options, eg. `form:"user_id,alt=uid,alt=userId"`, and the former name of `formerly=` tag option.
+func fieldAliases(fld reflect.StructField, options tagOptions) []string {
+	var aliases []string
+
+	if tag := fld.Tag.Get("formalias"); tag != blank {
+		for _, alias := range strings.Split(tag, ",") {
+			if alias = strings.TrimSpace(alias); alias != blank {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+
+	for _, opt := range options {
+		if (opt.name == "alt" || opt.name == "formerly") && opt.value != blank {
+			aliases = append(aliases, opt.value)
+		}
+	}
+
+	return aliases
+}
+
+// isFlattenType reports whether type can be flattened into its parent: a struct or pointer to struct
+// other than time.Time and other value structs.
+func isFlattenType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !isValueStruct(t)
+}
+
+// isBoolType reports whether type is bool or a pointer to bool.
+func isBoolType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Bool
+}
+
+// isSliceType reports whether type is a slice or a pointer to one.
+func isSliceType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Slice
+}
+
+// isNumberType reports whether type is an integer, a float or a pointer to one of them.
+func isNumberType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRawType reports whether type can hold values of `raw` fields: string, []byte, eg. json.RawMessage,
+// []string or a pointer to one of them.
+func isRawType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8 || t.Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
@@ -3,6 +3,7 @@ package form
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/stretchr/testify/assert"
 )
@@ -35,3 +36,41 @@ func TestDecoderMultipleSimultaniousParseStructRequests(t *testing.T) {
 
 	close(proceed)
 }
+
+func TestStructCacheMap_precompute(t *testing.T) {
+	t.Parallel()
+
+	type Leaf struct {
+		Name string
+	}
+
+	type Node struct {
+		Children []Node
+		Leaves   map[string]*Leaf
+		Arr      [2]Leaf
+		Time     time.Time
+		Ignored  Leaf `form:"-"`
+	}
+
+	type Unreachable struct {
+		Leaf
+	}
+
+	sc := newStructCacheMap()
+	sc.precompute(ModeImplicit, reflect.TypeOf(&Node{}), "form", map[reflect.Type]bool{})
+
+	_, ok := sc.Get(ModeImplicit, reflect.TypeOf(Node{}), "form")
+	True(t, ok)
+
+	_, ok = sc.Get(ModeImplicit, reflect.TypeOf(Leaf{}), "form")
+	True(t, ok)
+
+	_, ok = sc.Get(ModeImplicit, reflect.TypeOf(time.Time{}), "form")
+	False(t, ok)
+
+	_, ok = sc.Get(ModeExplicit, reflect.TypeOf(Node{}), "form")
+	False(t, ok)
+
+	_, ok = sc.Get(ModeImplicit, reflect.TypeOf(Unreachable{}), "form")
+	False(t, ok)
+}
@@ -0,0 +1,81 @@
+package form
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CanonicalOption configures CanonicalEncode.
+type CanonicalOption func(o *canonicalOptions)
+
+type canonicalOptions struct {
+	escape     func(s string) string
+	sortValues bool
+}
+
+// WithEscaper sets a function to escape keys and values, default is url.QueryEscape.
+func WithEscaper(escape func(s string) string) CanonicalOption {
+	return func(o *canonicalOptions) {
+		o.escape = escape
+	}
+}
+
+// WithRFC3986Escaping escapes spaces as "%20" instead of "+",
+// as required by most request signature schemes.
+func WithRFC3986Escaping() CanonicalOption {
+	return WithEscaper(func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	})
+}
+
+// WithSortedValues sorts multiple values of a key, by default their order is kept.
+func WithSortedValues() CanonicalOption {
+	return func(o *canonicalOptions) {
+		o.sortValues = true
+	}
+}
+
+// CanonicalEncode encodes values into a canonical query string, eg. for signature computation.
+//
+// Keys are sorted, values of a key keep their order unless WithSortedValues is used.
+func CanonicalEncode(values url.Values, opts ...CanonicalOption) string {
+	o := canonicalOptions{escape: url.QueryEscape}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	keys := make([]string, 0, len(values))
+
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+
+	for _, k := range keys {
+		vals := values[k]
+
+		if o.sortValues && len(vals) > 1 {
+			vals = append([]string(nil), vals...)
+			sort.Strings(vals)
+		}
+
+		key := o.escape(k)
+
+		for _, v := range vals {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+
+			b.WriteString(key)
+			b.WriteByte('=')
+			b.WriteString(o.escape(v))
+		}
+	}
+
+	return b.String()
+}
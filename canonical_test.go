@@ -0,0 +1,35 @@
+package form
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalEncode(t *testing.T) {
+	t.Parallel()
+
+	values := url.Values{
+		"b":      {"2", "1"},
+		"a":      {"x y"},
+		"c[0]":   {"~+"},
+		"empty":  {},
+		"blank":  {""},
+		"Upper":  {"U"},
+		"a b":    {"k"},
+		"sorted": {"z", "a", "m"},
+	}
+
+	Equal(t, "Upper=U&a=x+y&a+b=k&b=2&b=1&blank=&c%5B0%5D=~%2B&sorted=z&sorted=a&sorted=m",
+		CanonicalEncode(values))
+
+	Equal(t, "Upper=U&a=x%20y&a%20b=k&b=1&b=2&blank=&c%5B0%5D=~%2B&sorted=a&sorted=m&sorted=z",
+		CanonicalEncode(values, WithRFC3986Escaping(), WithSortedValues()))
+
+	Equal(t, []string{"z", "a", "m"}, values["sorted"])
+
+	Equal(t, "A=X", CanonicalEncode(url.Values{"a": {"x"}}, WithEscaper(strings.ToUpper)))
+	Equal(t, "", CanonicalEncode(nil))
+}
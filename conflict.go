@@ -0,0 +1,176 @@
+package form
+
+import (
+	"reflect"
+)
+
+const errAmbiguousField = "field name '%s' is also a name of a field of an embedded struct"
+
+// shadow is a promoted embedded struct being traversed at namespace of length depth,
+// names are names of its promoted fields which are also names of fields of the embedding struct.
+type shadow struct {
+	name  string
+	depth int
+	names map[string]struct{}
+}
+
+// shadowedNames returns names of fields promoted from embedded struct typ, including fields of structs
+// embedded in it, which are also names of fields, visited guards against recursive embedding.
+func (s *structCacheMap) shadowedNames(mode Mode, typ reflect.Type, tagName string, fields cacheFields, visited map[reflect.Type]struct{}) map[string]struct{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if _, ok := visited[typ]; ok {
+		return nil
+	}
+
+	if visited == nil {
+		visited = make(map[reflect.Type]struct{})
+	}
+
+	visited[typ] = struct{}{}
+
+	var names map[string]struct{}
+
+	add := func(name string) {
+		if names == nil {
+			names = make(map[string]struct{})
+		}
+
+		names[name] = struct{}{}
+	}
+
+	for _, f := range s.ps(mode, typ, tagName).fields {
+		switch {
+		case f.isFlatten:
+		case !f.isAnonymous:
+			if hasFieldName(fields, f.name) {
+				add(f.name)
+			}
+		case f.embed != embedNamespace:
+			for name := range s.shadowedNames(mode, typ.Field(f.idx).Type, tagName, fields, visited) {
+				add(name)
+			}
+		}
+	}
+
+	return names
+}
+
+// hasFieldName reports whether fields have a named field, which is neither embedded nor flattened.
+func hasFieldName(fields cacheFields, name string) bool {
+	for _, f := range fields {
+		if !f.isAnonymous && !f.isFlatten && f.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findShadow returns the embedded struct traversed at namespace of length depth whose field name is shadowed.
+func findShadow(shadows []shadow, depth int, name string) (shadow, bool) {
+	for i := len(shadows) - 1; i >= 0; i-- {
+		if shadows[i].depth != depth {
+			continue
+		}
+
+		if _, ok := shadows[i].names[name]; ok {
+			return shadows[i], true
+		}
+	}
+
+	return shadow{}, false
+}
+
+// isShadowing reports whether field name of struct s shadows a field of an embedded struct the promoted
+// function reports as promoted.
+func isShadowing(s *cachedStruct, name string, promoted func(f cachedField) bool) bool {
+	for _, f := range s.fields {
+		if _, ok := f.shadowed[name]; ok && promoted(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pushesShadows reports whether fields of promoted embedded structs shadowed by fields of the embedding struct
+// are tracked for the mode.
+func pushesShadows(mode ConflictMode) bool {
+	return mode == ConflictOuter || mode == ConflictError || mode == ConflictNamespace
+}
+
+// pushShadow starts traversal of promoted embedded field f at namespace of length depth and reports
+// whether its shadowed fields are tracked.
+func (d *decoder[DecodeFuncArgument]) pushShadow(f cachedField, depth int, namePrefix string) bool {
+	if len(f.shadowed) == 0 || namePrefix != blank || !pushesShadows(d.opts.ConflictMode) {
+		return false
+	}
+
+	d.shadows = append(d.shadows, shadow{name: f.name, depth: depth, names: f.shadowed})
+
+	return true
+}
+
+// conflict returns whether field f of struct s at namespace of length depth is skipped by the conflict mode
+// and whether its value is ambiguous.
+func (d *decoder[DecodeFuncArgument]) conflict(s *cachedStruct, f cachedField, depth int, namePrefix string) (skip, ambiguous bool) {
+	if d.opts.ConflictMode == ConflictShared || f.isAnonymous || namePrefix != blank {
+		return false, false
+	}
+
+	if _, ok := findShadow(d.shadows, depth, f.name); ok {
+		return true, false
+	}
+
+	if !isShadowing(s, f.name, func(f cachedField) bool { return f.hasExportedScalar }) {
+		return false, false
+	}
+
+	return d.opts.ConflictMode == ConflictInner, d.opts.ConflictMode == ConflictError
+}
+
+// pushShadow starts traversal of promoted embedded field f at namespace of length depth and reports
+// whether its shadowed fields are tracked.
+func (e *encoder) pushShadow(f cachedField, depth int, namePrefix string) bool {
+	if len(f.shadowed) == 0 || namePrefix != blank || !pushesShadows(e.e.conflictMode) {
+		return false
+	}
+
+	e.shadows = append(e.shadows, shadow{name: f.name, depth: depth, names: f.shadowed})
+
+	return true
+}
+
+// conflict returns whether field f of struct s at namespace of length depth is skipped by the conflict mode
+// and the name of the embedded field to encode it under instead of its promoted name.
+func (e *encoder) conflict(s *cachedStruct, f cachedField, namespace []byte, namePrefix string) (skip bool, embedded string) {
+	if e.e.conflictMode == ConflictShared || f.isAnonymous || namePrefix != blank {
+		return false, blank
+	}
+
+	if sh, ok := findShadow(e.shadows, len(namespace), f.name); ok {
+		return e.e.conflictMode != ConflictNamespace, sh.name
+	}
+
+	promoted := func(f cachedField) bool {
+		return f.hasExportedScalar && (f.embed == embedPromote || e.e.embedAnonymous)
+	}
+
+	if !isShadowing(s, f.name, promoted) {
+		return false, blank
+	}
+
+	if e.e.conflictMode == ConflictError {
+		e.setError(e.appendName(namespace, len(namespace) == 0, f.name),
+			newError(ErrAmbiguousField, errAmbiguousField, f.name))
+	}
+
+	return e.e.conflictMode == ConflictInner || e.e.conflictMode == ConflictError, blank
+}
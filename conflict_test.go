@@ -0,0 +1,142 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type conflictInner struct {
+	Field string
+	Other string
+}
+
+type conflictMiddle struct {
+	conflictInner
+}
+
+type conflictOuter struct {
+	conflictInner
+	Field string
+}
+
+type conflictDeep struct {
+	conflictMiddle
+	Field string
+}
+
+func TestConflictModeDecode(t *testing.T) {
+	t.Parallel()
+
+	values := url.Values{
+		"Field":               {"outer"},
+		"Other":               {"other"},
+		"conflictInner.Field": {"inner"},
+	}
+
+	tests := []struct {
+		mode     ConflictMode
+		expected conflictOuter
+	}{
+		{mode: ConflictShared, expected: conflictOuter{conflictInner{"inner", "other"}, "outer"}},
+		{mode: ConflictOuter, expected: conflictOuter{conflictInner{"inner", "other"}, "outer"}},
+		{mode: ConflictInner, expected: conflictOuter{conflictInner: conflictInner{"inner", "other"}}},
+		{mode: ConflictNamespace, expected: conflictOuter{conflictInner{"inner", "other"}, "outer"}},
+	}
+
+	for _, tt := range tests {
+		decoder := NewDecoder[any]()
+		decoder.SetConflictMode(tt.mode)
+
+		var test conflictOuter
+
+		err := decoder.Decode(&test, values, nil)
+		NoError(t, err)
+		Equal(t, tt.expected, test, tt.mode)
+	}
+
+	// the promoted name is not decoded into the shadowed field
+	decoder := NewDecoder[any]()
+	decoder.SetConflictMode(ConflictOuter)
+
+	var test conflictDeep
+
+	err := decoder.Decode(&test, url.Values{"Field": {"outer"}}, nil)
+	NoError(t, err)
+	Equal(t, conflictDeep{Field: "outer"}, test)
+
+	decoder.SetConflictMode(ConflictShared)
+
+	err = decoder.Decode(&test, url.Values{"Field": {"outer"}}, nil)
+	NoError(t, err)
+	Equal(t, "outer", test.conflictMiddle.Field)
+
+	decoder.SetConflictMode(ConflictError)
+
+	err = decoder.Decode(&conflictOuter{}, url.Values{"Other": {"other"}}, nil)
+	NoError(t, err)
+
+	err = decoder.Decode(&conflictOuter{}, url.Values{"Field": {"outer"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrAmbiguousField))
+	Contains(t, err.(DecodeErrors), "Field")
+}
+
+func TestConflictModeEncode(t *testing.T) {
+	t.Parallel()
+
+	value := conflictOuter{conflictInner{"inner", "other"}, "outer"}
+
+	tests := []struct {
+		mode     ConflictMode
+		expected url.Values
+	}{
+		{mode: ConflictShared, expected: url.Values{"Field": {"inner", "outer"}, "Other": {"other"}}},
+		{mode: ConflictOuter, expected: url.Values{"Field": {"outer"}, "Other": {"other"}}},
+		{mode: ConflictInner, expected: url.Values{"Field": {"inner"}, "Other": {"other"}}},
+		{mode: ConflictNamespace, expected: url.Values{"Field": {"outer"}, "conflictInner.Field": {"inner"}, "Other": {"other"}}},
+	}
+
+	for _, tt := range tests {
+		encoder := NewEncoder()
+		encoder.SetConflictMode(tt.mode)
+
+		values, err := encoder.Encode(value)
+		NoError(t, err)
+		Equal(t, tt.expected, values, tt.mode)
+
+		decoder := NewDecoder[any]()
+		decoder.SetConflictMode(tt.mode)
+
+		if tt.mode == ConflictNamespace {
+			var decoded conflictOuter
+
+			err = decoder.Decode(&decoded, values, nil)
+			NoError(t, err)
+			Equal(t, value, decoded)
+		}
+	}
+
+	encoder := NewEncoder()
+	encoder.SetConflictMode(ConflictNamespace)
+
+	values, err := encoder.Encode(conflictDeep{conflictMiddle{conflictInner{"inner", "other"}}, "outer"})
+	NoError(t, err)
+	Equal(t, url.Values{"Field": {"outer"}, "conflictMiddle.Field": {"inner"}, "Other": {"other"}}, values)
+
+	// fields of separated embedded structs do not conflict
+	encoder.SetAnonymousMode(AnonymousSeparate)
+
+	values, err = encoder.Encode(value)
+	NoError(t, err)
+	Equal(t, []string{"inner"}, values["conflictInner.Field"])
+
+	encoder = NewEncoder()
+	encoder.SetConflictMode(ConflictError)
+
+	_, err = encoder.Encode(value)
+	NotNil(t, err)
+	True(t, errors.Is(err.(EncodeErrors)["Field"], ErrAmbiguousField))
+}
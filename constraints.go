@@ -0,0 +1,258 @@
+package form
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConstraintError is reported for decoded values violating `min`, `max`, `len` or `match` tag options,
+// eg. `form:"age,min=0,max=150"`, and for `enum` and `required` tag options enforced with
+// Decoder.SetEnforceEnumRequired.
+type ConstraintError struct {
+	// Constraint is the name of the violated tag option, eg. "min".
+	Constraint string
+
+	// Param is the value of the tag option, eg. "0".
+	Param string
+
+	// length reports whether min, max or len applies to the length instead of the value
+	length bool
+}
+
+func (e *ConstraintError) Error() string {
+	subject := "value"
+	if e.length && e.Constraint != "match" && e.Constraint != "enum" {
+		subject = "length"
+	}
+
+	switch e.Constraint {
+	case "min":
+		return subject + " must be at least " + e.Param
+	case "max":
+		return subject + " must be at most " + e.Param
+	case "len":
+		return subject + " must be " + e.Param
+	case "enum":
+		return subject + " must be one of '" + strings.ReplaceAll(e.Param, "|", "', '") + "'"
+	case "required":
+		return "value is required"
+	default:
+		return subject + " must match '" + e.Param + "'"
+	}
+}
+
+// fieldConstraints are constraints of a field parsed from its tag options.
+type fieldConstraints struct {
+	min, max       float64
+	hasMin, hasMax bool
+	length         int // -1 without `len` option
+	match          *regexp.Regexp
+	enum           map[string]struct{}
+	enumParam      string // value of `enum` tag option, eg. "asc|desc"
+	invalidEnum    string // `enum` tag option of a type other than strings, reported when enforced
+	required       bool   // reported when a field has no values, see traverseStruct
+	isLength       bool   // min and max apply to the length of strings, slices, arrays and maps
+	invalid        string // invalid option reported when decoding, eg. "min=x"
+	options        tagOptions
+}
+
+// parseConstraints returns constraints of a field of the given type, nil if it has none.
+//
+// Numbers are constrained by value, strings by number of characters and slices, arrays and maps
+// by number of elements, `match` and `enum` apply to strings and string elements. Options that can not be
+// parsed or do not apply to the type are reported as invalid when decoding. `enum` and `required` describe
+// fields, eg. in OpenAPIParameters, and are checked only when enforced with Decoder.SetEnforceEnumRequired.
+func parseConstraints(typ reflect.Type, options tagOptions) *fieldConstraints {
+	var c *fieldConstraints
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	kind := typ.Kind()
+
+	for _, o := range options {
+		switch o.name {
+		case "min", "max", "len", "match":
+		case "required":
+			if c == nil {
+				c = &fieldConstraints{length: -1}
+			}
+
+			c.required = true
+
+			continue
+		case "enum":
+			if c == nil {
+				c = &fieldConstraints{length: -1}
+			}
+
+			c.enum, c.enumParam = make(map[string]struct{}), o.value
+
+			for _, v := range strings.Split(o.value, "|") {
+				c.enum[v] = struct{}{}
+			}
+
+			if !constraintApplies(o.name, kind, typ) {
+				c.invalidEnum = o.name + "=" + o.value
+			}
+
+			continue
+		default:
+			continue
+		}
+
+		if c == nil {
+			c = &fieldConstraints{length: -1}
+		}
+
+		c.options = append(c.options, o)
+
+		if c.invalid != blank {
+			continue
+		}
+
+		var err error
+
+		switch o.name {
+		case "min":
+			c.min, err = strconv.ParseFloat(o.value, 64)
+			c.hasMin = true
+		case "max":
+			c.max, err = strconv.ParseFloat(o.value, 64)
+			c.hasMax = true
+		case "len":
+			if c.length, err = strconv.Atoi(o.value); err == nil && c.length < 0 {
+				err = strconv.ErrRange
+			}
+		case "match":
+			c.match, err = regexp.Compile(o.value)
+		}
+
+		if err != nil || !constraintApplies(o.name, kind, typ) {
+			c.invalid = o.name + "=" + o.value
+		}
+	}
+
+	if c != nil {
+		c.isLength = kind == reflect.String || kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+	}
+
+	return c
+}
+
+// constraintApplies reports whether a constraint option applies to values of the kind.
+func constraintApplies(name string, kind reflect.Kind, typ reflect.Type) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return name == "min" || name == "max"
+	case reflect.String:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return name != "match" && name != "enum" || typ.Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+// checkConstraints reports a decoded field value violating constraints of the field.
+func (d *decoder[DecodeFuncArgument]) checkConstraints(fv reflect.Value, c *fieldConstraints, namespace []byte) {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return
+		}
+
+		fv = fv.Elem()
+	}
+
+	var raw string
+
+	if arr, _ := d.lookup(namespace); len(arr) > 0 {
+		raw = arr[0]
+	}
+
+	invalid := c.invalid
+	if invalid == blank && d.opts.EnforceEnumRequired {
+		invalid = c.invalidEnum
+	}
+
+	if invalid != blank {
+		d.setValueError(namespace, fv.Kind(), raw, newError(ErrInvalidTagOption, "invalid tag option '%s' namespace '%s'",
+			invalid, string(namespace)))
+
+		return
+	}
+
+	var n float64
+
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = fv.Float()
+	case reflect.String:
+		n = float64(utf8.RuneCountInString(fv.String()))
+	default:
+		n = float64(fv.Len())
+	}
+
+	for _, o := range c.options {
+		var ok bool
+
+		switch o.name {
+		case "min":
+			ok = n >= c.min
+		case "max":
+			ok = n <= c.max
+		case "len":
+			ok = int(n) == c.length
+		case "match":
+			ok = allStrings(fv, c.match.MatchString)
+		}
+
+		if !ok {
+			d.setValueError(namespace, fv.Kind(), raw, &ConstraintError{Constraint: o.name, Param: o.value, length: c.isLength})
+
+			return
+		}
+	}
+
+	if c.enum != nil && d.opts.EnforceEnumRequired && !allStrings(fv, func(s string) bool {
+		_, ok := c.enum[s]
+
+		return ok
+	}) {
+		d.setValueError(namespace, fv.Kind(), raw, &ConstraintError{Constraint: "enum", Param: c.enumParam, length: c.isLength})
+	}
+}
+
+// allStrings reports whether fn accepts a string or all string elements of a slice, array or map.
+func allStrings(v reflect.Value, fn func(s string) bool) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return fn(v.String())
+	case reflect.Map:
+		iter := v.MapRange()
+
+		for iter.Next() {
+			if !fn(iter.Value().String()) {
+				return false
+			}
+		}
+	default:
+		for i := 0; i < v.Len(); i++ {
+			if !fn(v.Index(i).String()) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,193 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDecoder_Constraints(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Age   int      `form:"age,min=0,max=150"`
+		Score *float64 `form:"score,min=0.5"`
+		Name  string   `form:"name,min=2,max=5"`
+		Code  string   `form:"code,len=3"`
+		Slug  string   `form:"slug,match=^[a-z-]+$"`
+		Lang  string   `form:"lang,match=^[a-z]{2,3}$"`
+		Tags  []string `form:"tags,max=2,match=^#"`
+		Count uint     `form:"count,max=10"`
+	}
+
+	var test Test
+
+	err := NewDecoder[any]().Decode(&test, url.Values{
+		"age":   {"30"},
+		"score": {"0.5"},
+		"name":  {"jürg"},
+		"code":  {"abc"},
+		"slug":  {"a-b"},
+		"lang":  {"deu"},
+		"tags":  {"#a", "#b"},
+		"count": {"10"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, 30, test.Age)
+	Equal(t, "deu", test.Lang)
+
+	test = Test{}
+
+	err = NewDecoder[any]().Decode(&test, url.Values{
+		"age":   {"-1"},
+		"score": {"0.1"},
+		"name":  {"j"},
+		"code":  {"abcd"},
+		"slug":  {"A"},
+		"lang":  {"english"},
+		"tags":  {"#a", "b"},
+		"count": {"11"},
+	}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 8, len(errs))
+	Equal(t, "value must be at least 0", errs["age"].Error())
+	Equal(t, "value must be at least 0.5", errs["score"].Error())
+	Equal(t, "length must be at least 2", errs["name"].Error())
+	Equal(t, "length must be 3", errs["code"].Error())
+	Equal(t, "value must match '^[a-z-]+$'", errs["slug"].Error())
+	Equal(t, "value must match '^[a-z]{2,3}$'", errs["lang"].Error())
+	Equal(t, "value must match '^#'", errs["tags"].Error())
+	Equal(t, "value must be at most 10", errs["count"].Error())
+
+	var ce *ConstraintError
+
+	True(t, errors.As(errs["age"], &ce))
+	Equal(t, "min", ce.Constraint)
+	Equal(t, "0", ce.Param)
+
+	fe := errs["age"].(*FieldError)
+	Equal(t, "-1", fe.RawValue())
+
+	// missing values are not checked
+	test = Test{}
+
+	err = NewDecoder[any]().Decode(&test, url.Values{"tags": {"#a", "#b", "#c"}}, nil)
+	NotNil(t, err)
+	Equal(t, "length must be at most 2", err.(DecodeErrors)["tags"].Error())
+}
+
+func TestDecoder_ConstraintsEnumRequired(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city,required"`
+		Zip  string `form:"zip"`
+	}
+
+	type Test struct {
+		Sort    string   `form:"sort,required,enum=asc|desc"`
+		Fields  []string `form:"fields,enum=id|name"`
+		Name    string   `form:"name,alt=n,required"`
+		Home    *Address `form:"home"`
+		Work    Address  `form:"work"`
+		Billing *Address `form:"billing"`
+	}
+
+	var test Test
+
+	// the options only describe fields unless enforced
+	err := NewDecoder[any]().Decode(&test, url.Values{"sort": {"up"}}, nil)
+	NoError(t, err)
+	Equal(t, "up", test.Sort)
+
+	decoder := NewDecoder[any]()
+	decoder.SetEnforceEnumRequired(true)
+
+	test = Test{}
+
+	err = decoder.Decode(&test, url.Values{
+		"sort":     {"desc"},
+		"fields":   {"id", "name"},
+		"n":        {"joe"},
+		"home.zip": {"1000"},
+	}, nil)
+	NotNil(t, err)
+
+	// required fields of nested structs without values are not reported
+	errs := err.(DecodeErrors)
+	Equal(t, 1, len(errs))
+	Equal(t, "value is required", errs["home.city"].Error())
+	Equal(t, "joe", test.Name)
+	Nil(t, test.Billing)
+
+	var ce *ConstraintError
+
+	True(t, errors.As(errs["home.city"], &ce))
+	Equal(t, "required", ce.Constraint)
+
+	test = Test{}
+
+	err = decoder.Decode(&test, url.Values{
+		"sort":   {"up"},
+		"fields": {"id", "age"},
+	}, nil)
+	NotNil(t, err)
+
+	errs = err.(DecodeErrors)
+	Equal(t, 3, len(errs))
+	Equal(t, "value must be one of 'asc', 'desc'", errs["sort"].Error())
+	Equal(t, "value must be one of 'id', 'name'", errs["fields"].Error())
+	Equal(t, "value is required", errs["name"].Error())
+
+	type Invalid struct {
+		Num int `form:"num,enum=1|2"`
+	}
+
+	var invalid Invalid
+
+	NoError(t, NewDecoder[any]().Decode(&invalid, url.Values{"num": {"1"}}, nil))
+
+	err = decoder.Decode(&invalid, url.Values{"num": {"1"}}, nil)
+	NotNil(t, err)
+	Equal(t, "invalid tag option 'enum=1|2' namespace 'num'", err.(DecodeErrors)["num"].Error())
+}
+
+func TestDecoder_ConstraintsInvalid(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Min    int    `form:"min,min=x"`
+		Active bool   `form:"active,min=1"`
+		Num    int    `form:"num,match=^1"`
+		Re     string `form:"re,match=("`
+	}
+
+	var test Test
+
+	err := NewDecoder[any]().Decode(&test, url.Values{
+		"min":    {"1"},
+		"active": {"true"},
+		"num":    {"1"},
+		"re":     {"x"},
+	}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 4, len(errs))
+	Equal(t, "invalid tag option 'min=x' namespace 'min'", errs["min"].Error())
+	Equal(t, "invalid tag option 'min=1' namespace 'active'", errs["active"].Error())
+	Equal(t, "invalid tag option 'match=^1' namespace 'num'", errs["num"].Error())
+	Equal(t, "invalid tag option 'match=(' namespace 're'", errs["re"].Error())
+	True(t, errors.Is(err, ErrInvalidTagOption))
+}
+
+func TestParseTagOptions(t *testing.T) {
+	t.Parallel()
+
+	Equal(t, tagOptions{{name: "omitempty"}, {name: "match", value: "^a{1,2},b$"}}, parseTagOptions("omitempty,,match=^a{1,2},b$"))
+	Equal(t, tagOptions{{name: "min", value: "1"}, {name: "csv"}}, parseTagOptions("min=1,csv,"))
+}
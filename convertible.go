@@ -0,0 +1,30 @@
+package form
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterStringConvertible registers decoding of T with parse and encoding with its String method,
+// eg. of decimal types: form.RegisterStringConvertible(decoder, encoder, decimal.NewFromString).
+// T is converted in fields, pointers, slice and array elements, and map keys and values,
+// it should be a value type as pointers are dereferenced before looking up registered functions.
+// Either decoder or encoder may be nil.
+//
+// Types implementing encoding.TextMarshaler and encoding.TextUnmarshaler, eg. big.Int, big.Float and big.Rat,
+// are converted without registration.
+func RegisterStringConvertible[T fmt.Stringer, A any](d *Decoder[A], e *Encoder, parse func(s string) (T, error)) {
+	var zero T
+
+	if d != nil {
+		d.RegisterFunc(func(s string, _ A) (interface{}, error) {
+			return parse(s)
+		}, reflect.TypeOf(zero))
+	}
+
+	if e != nil {
+		e.RegisterFunc(func(x interface{}) (string, error) {
+			return x.(T).String(), nil
+		}, zero)
+	}
+}
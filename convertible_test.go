@@ -0,0 +1,134 @@
+package form
+
+import (
+	"errors"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// testDecimal is a fixed-point number with two decimal places, as provided by decimal libraries.
+type testDecimal struct {
+	cents int64
+}
+
+var errTestDecimal = errors.New("invalid decimal")
+
+func parseTestDecimal(s string) (testDecimal, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || len(frac) > 2 {
+		return testDecimal{}, errTestDecimal
+	}
+
+	f, _ := strconv.ParseInt((frac + "00")[:2], 10, 64)
+
+	return testDecimal{cents: w*100 + f}, nil
+}
+
+func (d testDecimal) String() string {
+	return strconv.FormatInt(d.cents/100, 10) + "." + strconv.FormatInt(d.cents%100+100, 10)[1:]
+}
+
+func TestRegisterStringConvertible(t *testing.T) {
+	t.Parallel()
+
+	type Order struct {
+		Total    testDecimal            `form:"total"`
+		Discount *testDecimal           `form:"discount"`
+		Lines    []testDecimal          `form:"lines"`
+		Taxes    map[string]testDecimal `form:"taxes"`
+		Refunds  map[testDecimal]string `form:"refunds"`
+	}
+
+	d := NewDecoder[any]()
+	e := NewEncoder()
+	RegisterStringConvertible(d, e, parseTestDecimal)
+
+	values := url.Values{
+		"total":         {"12.50"},
+		"discount":      {"1.5"},
+		"lines":         {"10", "2.50"},
+		"taxes[vat]":    {"2.10"},
+		"refunds[0.99]": {"late"},
+	}
+
+	var order Order
+
+	NoError(t, d.Decode(&order, values, nil))
+	Equal(t, Order{
+		Total:    testDecimal{1250},
+		Discount: &testDecimal{150},
+		Lines:    []testDecimal{{1000}, {250}},
+		Taxes:    map[string]testDecimal{"vat": {210}},
+		Refunds:  map[testDecimal]string{{99}: "late"},
+	}, order)
+
+	encoded, err := e.Encode(order)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"total":         {"12.50"},
+		"discount":      {"1.50"},
+		"lines[0]":      {"10.00"},
+		"lines[1]":      {"2.50"},
+		"taxes[vat]":    {"2.10"},
+		"refunds[0.99]": {"late"},
+	}, encoded)
+
+	err = d.Decode(&order, url.Values{"total": {"1.234"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err.(DecodeErrors)["total"], errTestDecimal))
+}
+
+func TestBigTypes(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Int    big.Int               `form:"int"`
+		IntPtr *big.Int              `form:"int_ptr"`
+		Float  *big.Float            `form:"float"`
+		Rat    *big.Rat              `form:"rat"`
+		Ints   []*big.Int            `form:"ints"`
+		Floats map[string]*big.Float `form:"floats"`
+	}
+
+	var test Test
+
+	err := NewDecoder[any]().Decode(&test, url.Values{
+		"int":       {"123456789012345678901234567890"},
+		"int_ptr":   {"-5"},
+		"float":     {"1.5"},
+		"rat":       {"1/3"},
+		"ints":      {"1", "2"},
+		"floats[a]": {"2.25"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, "123456789012345678901234567890", test.Int.String())
+	Equal(t, int64(-5), test.IntPtr.Int64())
+	Equal(t, "1.5", test.Float.String())
+	Equal(t, "1/3", test.Rat.String())
+	Equal(t, 2, len(test.Ints))
+	Equal(t, int64(2), test.Ints[1].Int64())
+	Equal(t, "2.25", test.Floats["a"].String())
+
+	// fields of structs passed by value are copied to use pointer receiver methods
+	values, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"int":       {"123456789012345678901234567890"},
+		"int_ptr":   {"-5"},
+		"float":     {"1.5"},
+		"rat":       {"1/3"},
+		"ints[0]":   {"1"},
+		"ints[1]":   {"2"},
+		"floats[a]": {"2.25"},
+	}, values)
+
+	err = NewDecoder[any]().Decode(&test, url.Values{"int": {"1.5"}}, nil)
+	NotNil(t, err)
+}
@@ -0,0 +1,146 @@
+package form
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultMaxRecursion is the default number of times a struct type can be nested in itself.
+	defaultMaxRecursion = 64
+
+	// cycleCheckDepth is the nesting depth of structs from which the encoder checks for cycles.
+	cycleCheckDepth = 16
+)
+
+// CycleError is reported when a recursive struct type is nested in itself more times than the limit set
+// with Decoder.SetMaxRecursion, eg. by keys like "Next.Next.Next", or when the encoder reaches a struct
+// it is already encoding through pointers.
+type CycleError struct {
+	Type  reflect.Type
+	Depth int
+}
+
+func (e *CycleError) Error() string {
+	return "form: cycle of recursive type '" + e.Type.String() + "' at nesting depth " + strconv.Itoa(e.Depth)
+}
+
+// enterStruct records traversal of a struct type and reports whether it is nested in itself within the limit.
+func (d *decoder[DecodeFuncArgument]) enterStruct(typ reflect.Type, namespace []byte) bool {
+	max := d.opts.MaxRecursion
+	if max <= 0 {
+		max = defaultMaxRecursion
+	}
+
+	depth := 0
+
+	for _, t := range d.path {
+		if t == typ {
+			depth++
+		}
+	}
+
+	if depth > max {
+		// nested pointers are probed for values, a cycle is reported only for keys under the namespace
+		if d.hasKeysUnder(namespace) {
+			d.setError(namespace, &CycleError{Type: typ, Depth: depth})
+		}
+
+		return false
+	}
+
+	d.path = append(d.path, typ)
+
+	return true
+}
+
+// hasKeysUnder reports whether there are keys of values nested under namespace, no keys are nested
+// under the empty namespace of structs flattened into the root.
+func (d *decoder[DecodeFuncArgument]) hasKeysUnder(namespace []byte) bool {
+	if len(namespace) == 0 {
+		return false
+	}
+
+	for k := range d.values {
+		if len(k) > len(namespace) && strings.HasPrefix(k, string(namespace)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRecursiveType reports whether a struct type is nested in itself through its fields, eg. `Next *Node`.
+func isRecursiveType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return reachesType(typ, typ, map[reflect.Type]struct{}{})
+}
+
+// reachesType reports whether target is reachable from fields and elements of typ.
+func reachesType(typ, target reflect.Type, seen map[reflect.Type]struct{}) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return isType(typ.Elem(), target, seen)
+	case reflect.Map:
+		return isType(typ.Key(), target, seen) || isType(typ.Elem(), target, seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if isType(typ.Field(i).Type, target, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isType reports whether typ is target or reaches it, types are visited once.
+func isType(typ, target reflect.Type, seen map[reflect.Type]struct{}) bool {
+	if typ == target {
+		return true
+	}
+
+	if _, ok := seen[typ]; ok {
+		return false
+	}
+
+	seen[typ] = struct{}{}
+
+	return reachesType(typ, target, seen)
+}
+
+// visit is a struct being encoded at an address.
+type visit struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// enterStruct records encoding of a struct and reports whether it is not already being encoded,
+// structs nested deeper than cycleCheckDepth are checked only as a cycle is endless.
+func (e *encoder) enterStruct(v reflect.Value, namespace []byte) bool {
+	if !v.CanAddr() {
+		e.path = append(e.path, visit{})
+
+		return true
+	}
+
+	current := visit{addr: v.UnsafeAddr(), typ: v.Type()}
+
+	if len(e.path) >= cycleCheckDepth {
+		for _, p := range e.path {
+			if p == current {
+				e.setError(namespace, &CycleError{Type: current.typ, Depth: len(e.path)})
+
+				return false
+			}
+		}
+	}
+
+	e.path = append(e.path, current)
+
+	return true
+}
@@ -0,0 +1,105 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type cycleNode struct {
+	Name string
+	Next *cycleNode
+}
+
+type RecursiveEmbedded struct {
+	*RecursiveEmbedded
+	Name string
+}
+
+func TestDecoder_SetMaxRecursion(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder[any]()
+	decoder.SetMaxRecursion(3)
+
+	var node cycleNode
+
+	err := decoder.Decode(&node, url.Values{"Next.Next.Next.Name": {"d"}}, nil)
+	NoError(t, err)
+	Equal(t, "d", node.Next.Next.Next.Name)
+
+	err = decoder.Decode(&cycleNode{}, url.Values{"Next.Next.Next.Next.Name": {"e"}}, nil)
+	NotNil(t, err)
+
+	var cycleErr *CycleError
+
+	True(t, errors.As(err, &cycleErr))
+	Equal(t, 4, cycleErr.Depth)
+	Equal(t, "form: cycle of recursive type 'form.cycleNode' at nesting depth 4", cycleErr.Error())
+	Contains(t, err.(DecodeErrors), "Next.Next.Next.Next")
+
+	// keys of attacker-chosen depth are limited by default
+	err = NewDecoder[any]().Decode(&cycleNode{}, url.Values{strings.Repeat("Next.", 1000) + "Name": {"x"}}, nil)
+	NotNil(t, err)
+	True(t, errors.As(err, &cycleErr))
+}
+
+func TestDecoder_RecursiveFlatten(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name string `form:"name"`
+		Next *Node  `form:",prefix="`
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.SetMaxRecursion(2)
+
+	var node Node
+
+	NoError(t, decoder.Decode(&node, url.Values{"name": {"a"}}, nil))
+	Equal(t, Node{Name: "a", Next: &Node{Name: "a", Next: &Node{Name: "a"}}}, node)
+}
+
+func TestRecursiveEmbedding(t *testing.T) {
+	t.Parallel()
+
+	var test RecursiveEmbedded
+
+	err := NewDecoder[any]().Decode(&test, url.Values{"Name": {"a"}, "RecursiveEmbedded.Name": {"b"}}, nil)
+	NoError(t, err)
+	Equal(t, "a", test.Name)
+	Equal(t, "b", test.RecursiveEmbedded.Name)
+
+	values, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"a"}, "RecursiveEmbedded.Name": {"b"}}, values)
+}
+
+func TestEncoder_Cycle(t *testing.T) {
+	t.Parallel()
+
+	node := &cycleNode{Name: "a"}
+	node.Next = &cycleNode{Name: "b", Next: node}
+
+	_, err := NewEncoder().Encode(node)
+	NotNil(t, err)
+
+	var cycleErr *CycleError
+
+	True(t, errors.As(err.(EncodeErrors)[strings.Repeat("Next.", cycleCheckDepth-1)+"Next"], &cycleErr))
+	Equal(t, cycleCheckDepth, cycleErr.Depth)
+
+	// shared pointers are not cycles
+	shared := &cycleNode{Name: "c"}
+
+	values, err := NewEncoder().Encode(struct {
+		A *cycleNode
+		B *cycleNode
+	}{A: shared, B: shared})
+	NoError(t, err)
+	Equal(t, []string{"c"}, values["B.Name"])
+}
@@ -3,11 +3,15 @@ package form
 import (
 	"encoding"
 	"fmt"
+	"math/big"
+	"net/textproto"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -15,26 +19,448 @@ const (
 		"see SetMaxArraySize(size uint)"
 	errMissingStartBracket = "invalid formatting for key '%s' missing '[' bracket"
 	errMissingEndBracket   = "invalid formatting for key '%s' missing ']' bracket"
+	errConflictingKeys     = "value and nested values of the same key"
+
+	// maxExponentDigits bounds exponents of permissive integers, larger ones overflow 64 bits
+	maxExponentDigits = 4
 )
 
 type decoder[DecodeFuncArgument any] struct {
 	d                  *Decoder[DecodeFuncArgument]
+	opts               DecodeOptions
+	funcs              *decodeFuncs[DecodeFuncArgument]
+	structCache        *structCacheMap
 	errs               DecodeErrors
 	dm                 dataMap
 	dmDone             bool
 	values             url.Values
+	valuesOwned        bool
+	isHeader           bool
 	goValues           map[string]interface{}
+	collectNested      bool // goValues of nested fields are collected by namespace
+	fieldSet           FieldSet
+	source             string
+	provenance         map[string]Provenance
+	origKeys           map[string]string
+	discriminator      string
+	unixUnit           time.Duration // unit of timestamps of the field being decoded, see cachedField.unixUnit
+	hasBoolFormat      bool          // strings of booleans of the field being decoded, see cachedField.hasBoolFormat
+	boolTrue           string
+	boolFalse          string
+	declType           reflect.Type
+	fieldPre           []Preprocessor
+	consumed           map[string]struct{}
+	shadows            []shadow
+	path               []reflect.Type
+	sources            map[string]url.Values
+	pathParams         PathParamSource
+	sourcesOnly        bool // only fields of sources other than the body are decoded, see BindRequest
 	maxKeyLen          int
 	namespace          []byte
 	decodeFuncArgument DecodeFuncArgument
+	argumentProvider   ArgumentProvider[DecodeFuncArgument]
+	deadline           time.Time
+	steps              int
+	expired            bool
+}
+
+// timeoutCheckInterval is a number of decoding steps between deadline checks.
+const timeoutCheckInterval = 64
+
+// reset clears per-call state before returning decoder to the pool.
+func (d *decoder[DecodeFuncArgument]) reset() {
+	var zeroArgument DecodeFuncArgument
+
+	d.errs = nil
+	d.funcs = nil
+	d.structCache = nil
+	d.dmDone = false
+	d.values = nil
+	d.valuesOwned = false
+	d.isHeader = false
+	d.goValues = nil
+	d.collectNested = false
+	d.fieldSet = nil
+	d.source = blank
+	d.provenance = nil
+	d.origKeys = nil
+	d.discriminator = blank
+	d.unixUnit = 0
+	d.hasBoolFormat, d.boolTrue, d.boolFalse = false, blank, blank
+	d.declType = nil
+	d.fieldPre = nil
+	d.consumed = nil
+	d.shadows = d.shadows[:0]
+	d.path = d.path[:0]
+	d.sources = nil
+	d.pathParams = nil
+	d.sourcesOnly = false
+	d.decodeFuncArgument = zeroArgument
+	d.argumentProvider = nil
+	d.deadline = time.Time{}
+	d.steps = 0
+	d.expired = false
+}
+
+// timedOut reports whether decoding deadline is exceeded, clock is checked
+// periodically to keep overhead low.
+func (d *decoder[DecodeFuncArgument]) timedOut() bool {
+	if d.expired {
+		return true
+	}
+
+	if d.deadline.IsZero() {
+		return false
+	}
+
+	d.steps++
+	if d.steps%timeoutCheckInterval != 0 {
+		return false
+	}
+
+	d.expired = time.Now().After(d.deadline)
+
+	return d.expired
+}
+
+// lookup returns values of a namespace, preprocessed when there are preprocessors.
+func (d *decoder[DecodeFuncArgument]) lookup(namespace []byte) ([]string, bool) {
+	var (
+		arr []string
+		ok  bool
+	)
+
+	if d.isHeader {
+		arr, ok = d.values[textproto.CanonicalMIMEHeaderKey(string(namespace))]
+	} else {
+		arr, ok = d.values[string(namespace)]
+
+		if ok && d.consumed != nil {
+			d.consumed[string(namespace)] = struct{}{}
+		}
+	}
+
+	if ok && (d.fieldPre != nil || d.funcs.preprocessors != nil) {
+		arr = d.preprocess(string(namespace), arr)
+	}
+
+	return arr, ok
+}
+
+// replaceValues replaces values of a namespace, values are copied before
+// the first replacement to keep the caller's map intact.
+func (d *decoder[DecodeFuncArgument]) replaceValues(namespace string, vals []string) {
+	if !d.valuesOwned {
+		values := make(url.Values, len(d.values))
+
+		for k, v := range d.values {
+			values[k] = v
+		}
+
+		d.values = values
+		d.valuesOwned = true
+	}
+
+	if d.isHeader {
+		namespace = textproto.CanonicalMIMEHeaderKey(namespace)
+	}
+
+	d.values[namespace] = vals
+}
+
+// checkLimits checks values against configured limits of keys and values.
+func (d *decoder[DecodeFuncArgument]) checkLimits() error {
+	if d.opts.MaxKeys > 0 && len(d.values) > d.opts.MaxKeys {
+		return &LimitError{Max: d.opts.MaxKeys, Count: len(d.values)}
+	}
+
+	if d.opts.MaxValuesPerKey > 0 {
+		for k, vals := range d.values {
+			if len(vals) > d.opts.MaxValuesPerKey {
+				return &LimitError{Key: k, Max: d.opts.MaxValuesPerKey, Count: len(vals)}
+			}
+		}
+	}
+
+	if d.opts.MaxDepth > 0 {
+		for k := range d.values {
+			if depth := keyDepth(k); depth > d.opts.MaxDepth {
+				return &DepthError{Key: k, Max: d.opts.MaxDepth, Depth: depth}
+			}
+		}
+	}
+
+	return nil
+}
+
+// keyDepth returns nesting depth of a key as a number of field separators and brackets.
+func keyDepth(k string) int {
+	depth := 0
+
+	for i := 0; i < len(k); i++ {
+		if k[i] == '.' || k[i] == '[' {
+			depth++
+		}
+	}
+
+	return depth
+}
+
+// run decodes values into v.
+func (d *decoder[DecodeFuncArgument]) run(v reflect.Value) error {
+	if len(d.opts.IgnoredKeys) > 0 {
+		d.dropIgnoredKeys()
+	}
+
+	if d.opts.ArraySyntax == ArrayDots {
+		d.bracketDottedIndexes()
+	}
+
+	if d.opts.KeyStyle == StyleQS {
+		d.qsKeys()
+	}
+
+	if d.opts.KeyStyle == StyleRails || d.opts.KeyStyle == StyleQS {
+		d.indexEmptyBrackets()
+	}
+
+	if d.opts.BracketStyle == StylePHP {
+		d.mergeEmptyBrackets()
+	}
+
+	if d.opts.Timeout > 0 {
+		d.deadline = time.Now().Add(d.opts.Timeout)
+	}
+
+	d.declType = v.Type()
+
+	// values decoded from the body are kept when fields of other sources are decoded, see BindRequest
+	if d.opts.MergeMode == MergeReplace && !d.sourcesOnly {
+		v.Set(reflect.Zero(v.Type()))
+	}
+
+	if typ := v.Type(); typ == dynamicMapType {
+		d.decodeDynamic(v)
+	} else if v.Kind() == reflect.Struct && !isValueStruct(typ) {
+		d.traverseStruct(v, typ, d.rootNamespace(typ), blank)
+	} else {
+		d.setFieldByType(v, false, d.namespace[0:0], 0)
+	}
+
+	switch {
+	case d.expired:
+		return &TimeoutError{Errs: d.errs}
+	case len(d.errs) > 0:
+		return d.errs
+	}
+
+	return nil
+}
+
+// mergeEmptyBrackets merges values of PHP-style `key[]` into `key`.
+func (d *decoder[DecodeFuncArgument]) mergeEmptyBrackets() {
+	var keys []string
+
+	// keys are collected first as replaceValues may write into the ranged values,
+	// sorted so that "a[][]" is merged into "a[]" after "a[]" is merged into "a"
+	for k := range d.values {
+		if strings.HasSuffix(k, "[]") {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name := k[:len(k)-2]
+		existing := d.values[name]
+
+		d.replaceValues(name, append(existing[:len(existing):len(existing)], d.values[k]...))
+		d.setOrigKey(name, k)
+		delete(d.values, k)
+	}
+}
+
+// indexEmptyBrackets replaces empty brackets within Rack-style keys of arrays of hashes with indexes,
+// eg. "items[][name]=a&items[][name]=b" is decoded as "items[0][name]=a&items[1][name]=b". Elements are
+// matched by position of values as the order of different keys is not known.
+func (d *decoder[DecodeFuncArgument]) indexEmptyBrackets() {
+	var pending []string
+
+	for k := range d.values {
+		if innerEmptyBrackets(k) != -1 {
+			pending = append(pending, k)
+		}
+	}
+
+	for len(pending) > 0 {
+		k := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		vals := d.values[k]
+		i := innerEmptyBrackets(k)
+		prefix, rest := k[:i], k[i+2:]
+
+		for n, v := range vals {
+			name := prefix + "[" + strconv.Itoa(n+d.opts.IndexBase) + "]" + rest
+			existing := d.values[name]
+
+			d.replaceValues(name, append(existing[:len(existing):len(existing)], v))
+			d.setOrigKey(name, k)
+
+			if innerEmptyBrackets(name) != -1 {
+				pending = append(pending, name)
+			}
+		}
+
+		// values are owned after replacement, the caller's map is not modified
+		d.replaceValues(k, nil)
+		delete(d.values, k)
+	}
+}
+
+// innerEmptyBrackets returns the index of the first empty brackets of a key followed by more segments, or -1.
+func innerEmptyBrackets(k string) int {
+	if i := strings.Index(k, "[]"); i != -1 && i+2 < len(k) {
+		return i
+	}
+
+	return -1
+}
+
+// compactIndexes returns positions of distinct indexes in ascending order if they have gaps
+// within length l, or nil otherwise.
+func compactIndexes(keys []key, l int) map[int]int {
+	indexes := make([]int, 0, len(keys))
+
+	for _, k := range keys {
+		if k.ivalue != -1 {
+			indexes = append(indexes, k.ivalue)
+		}
+	}
+
+	sort.Ints(indexes)
+
+	positions := make(map[int]int, len(indexes))
+
+	for _, idx := range indexes {
+		if _, ok := positions[idx]; !ok {
+			positions[idx] = len(positions)
+		}
+	}
+
+	if len(positions) == 0 || len(positions) == l {
+		return nil
+	}
+
+	return positions
+}
+
+// bracketDottedIndexes rewrites dotted indexes to brackets, eg. `items.0.name` to `items[0].name`.
+func (d *decoder[DecodeFuncArgument]) bracketDottedIndexes() {
+	values := d.values
+
+	for k, vals := range values {
+		name, ok := bracketIndexes(k)
+		if !ok {
+			continue
+		}
+
+		existing := d.values[name]
+
+		d.replaceValues(name, append(existing[:len(existing):len(existing)], vals...))
+		d.setOrigKey(name, k)
+		delete(d.values, k)
+	}
+}
+
+// bracketIndexes converts numeric dotted path segments of a key to bracketed indexes.
+func bracketIndexes(k string) (string, bool) {
+	if strings.IndexByte(k, '.') == -1 {
+		return k, false
+	}
+
+	segments := strings.Split(k, ".")
+	changed := false
+
+	var b strings.Builder
+
+	b.Grow(len(k) + len(segments))
+	b.WriteString(segments[0])
+
+	for _, seg := range segments[1:] {
+		if isDigits(seg) {
+			b.WriteByte('[')
+			b.WriteString(seg)
+			b.WriteByte(']')
+
+			changed = true
+
+			continue
+		}
+
+		b.WriteByte('.')
+		b.WriteString(seg)
+	}
+
+	return b.String(), changed
+}
+
+func isDigits(s string) bool {
+	if s == blank {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setOrigKey remembers the received key of values moved to a rewritten key.
+func (d *decoder[DecodeFuncArgument]) setOrigKey(name, k string) {
+	if d.provenance == nil {
+		return
+	}
+
+	if d.origKeys == nil {
+		d.origKeys = make(map[string]string)
+	}
+
+	if orig, ok := d.origKeys[k]; ok {
+		k = orig
+	}
+
+	d.origKeys[name] = k
+}
+
+// setProvenance records the source and received key of values of a namespace.
+func (d *decoder[DecodeFuncArgument]) setProvenance(ns string) {
+	key := ns
+
+	if orig, ok := d.origKeys[ns]; ok {
+		key = orig
+	}
+
+	d.provenance[ns] = Provenance{Source: d.source, Key: key}
 }
 
 func (d *decoder[DecodeFuncArgument]) setError(namespace []byte, err error) {
+	d.setValueError(namespace, reflect.Invalid, blank, err)
+}
+
+// setValueError records error of converting raw value into a value of the given kind.
+func (d *decoder[DecodeFuncArgument]) setValueError(namespace []byte, kind reflect.Kind, raw string, err error) {
 	if d.errs == nil {
 		d.errs = make(DecodeErrors)
 	}
 
-	d.errs[string(namespace)] = err
+	ns := string(namespace)
+
+	d.errs[ns] = &FieldError{namespace: ns, kind: kind, raw: raw, err: err, format: d.funcs.formatError}
 }
 
 func (d *decoder[DecodeFuncArgument]) findAlias(ns string) *recursiveData {
@@ -62,24 +488,45 @@ func (d *decoder[DecodeFuncArgument]) parseMapData() error {
 		idx           int
 		l             int
 		insideBracket bool
+		nested        int
 		rd            *recursiveData
 		isNum         bool
 	)
 
 	for k := range d.values {
+		if d.timedOut() {
+			break
+		}
+
 		if len(k) > d.maxKeyLen {
 			d.maxKeyLen = len(k)
 		}
 
+		nested = 0
+
 		for i = 0; i < len(k); i++ {
 			switch k[i] {
 			case '[':
+				// brackets nested in a key are part of it, eg. "[b]" of "a[[b]]"
+				if insideBracket {
+					nested++
+					isNum = false
+
+					continue
+				}
+
 				idx = i
 				insideBracket = true
 				isNum = true
 			case ']':
 				if !insideBracket {
-					return fmt.Errorf(errMissingStartBracket, k)
+					return newError(ErrInvalidKey, errMissingStartBracket, k)
+				}
+
+				if nested > 0 {
+					nested--
+
+					continue
 				}
 
 				if rd = d.findAlias(k[:idx]); rd == nil {
@@ -118,8 +565,10 @@ func (d *decoder[DecodeFuncArgument]) parseMapData() error {
 					var err error
 
 					ke.ivalue, err = strconv.Atoi(ke.value)
-					if err != nil {
+					if err != nil || ke.ivalue < d.opts.IndexBase {
 						ke.ivalue = -1
+					} else {
+						ke.ivalue -= d.opts.IndexBase
 					}
 
 					if ke.ivalue > rd.sliceLen {
@@ -141,74 +590,835 @@ func (d *decoder[DecodeFuncArgument]) parseMapData() error {
 
 		// if still inside bracket, that means no ending bracket was ever specified
 		if insideBracket {
-			return fmt.Errorf(errMissingEndBracket, k)
+			return newError(ErrInvalidKey, errMissingEndBracket, k)
 		}
 	}
 
 	return nil
 }
 
-func (d *decoder[DecodeFuncArgument]) traverseStruct(v reflect.Value, typ reflect.Type, namespace []byte) (set bool) {
+// traverseStruct decodes fields of a struct, namePrefix is prepended to field names of flattened structs.
+func (d *decoder[DecodeFuncArgument]) traverseStruct(v reflect.Value, typ reflect.Type, namespace []byte, namePrefix string) (set bool) {
 	l := len(namespace)
 	first := l == 0
 
 	// anonymous structs will still work for caching as the whole definition is stored
 	// including tags
-	s, ok := d.d.structCache.Get(typ)
+	s, ok := d.structCache.Get(d.opts.Mode, typ, d.opts.TagName)
 	if !ok {
-		s = d.d.structCache.parseStruct(d.d.mode, typ, d.d.tagName)
+		s = d.structCache.parseStruct(d.opts.Mode, typ, d.opts.TagName)
+	}
+
+	if !d.enterStruct(typ, namespace) {
+		return false
+	}
+
+	defer func() {
+		d.path = d.path[:len(d.path)-1]
+	}()
+
+	var missing []string
+
+	// keys are tracked only when there is a remainder field to collect unmatched keys
+	if s.hasRemainder && d.consumed == nil {
+		d.consumed = make(map[string]struct{}, len(d.values))
 	}
 
 	for _, f := range s.fields {
-		if !f.canSet {
+		if d.timedOut() {
+			return set
+		}
+
+		if !f.canSet || d.sourcesOnly && !isSourceField(f, typ.Field(f.idx).Type) {
 			continue
 		}
 
 		namespace = namespace[:l]
 
-		if f.isAnonymous && f.hasExportedScalar {
+		if d.opts.ParamStyle != ParamNative && !f.hasStyle && !f.isAnonymous {
+			f = styleField(f, typ.Field(f.idx).Type, d.opts.ParamStyle, d.opts.ParamExplode)
+		}
+
+		if d.opts.KeyStyle == StyleQS && d.opts.QS.ArrayFormat == QSComma && !f.hasStyle {
+			f = qsCommaField(f, typ.Field(f.idx).Type)
+		}
+
+		if f.isUnexportedPtr {
+			if d.setUnexportedEmbedded(v.Field(f.idx), namespace, f, namePrefix) {
+				set = true
+			}
+
+			continue
+		}
+
+		if f.isFlatten {
+			if d.setFlattened(v.Field(f.idx), namespace, namePrefix+f.flattenPrefix) {
+				set = true
+			}
+
+			continue
+		}
+
+		if f.isAnonymous && f.hasExportedScalar && f.embed != embedNamespace {
+			pushed := d.pushShadow(f, l, namePrefix)
+
 			if d.setFieldByType(v.Field(f.idx), false, namespace, 0) {
 				set = true
 			}
+
+			if pushed {
+				d.shadows = d.shadows[:len(d.shadows)-1]
+			}
 		}
 
-		if first {
-			namespace = append(namespace, f.name...)
-		} else {
-			namespace = append(namespace, d.d.namespacePrefix...)
-			namespace = append(namespace, f.name...)
-			namespace = append(namespace, d.d.namespaceSuffix...)
+		if f.isAnonymous && f.embed == embedPromote {
+			continue
+		}
+
+		skip, ambiguous := d.conflict(s, f, l, namePrefix)
+		if skip {
+			continue
+		}
+
+		name := f.name
+		if namePrefix != blank {
+			name = namePrefix + name
+		}
+
+		namespace = d.appendName(namespace, first, name)
+		collected := len(d.goValues)
+		fieldSet := d.setField(v.Field(f.idx), f, namespace)
+
+		var required string
+
+		if !fieldSet && f.constraints != nil && f.constraints.required && d.opts.EnforceEnumRequired {
+			if _, failed := d.errs[string(namespace)]; !failed {
+				required = string(namespace)
+			}
+		}
+
+		// aliases are tried in order only when the field is not set by its name
+		for i := 0; !fieldSet && i < len(f.aliases); i++ {
+			namespace = d.appendName(namespace[:l], first, namePrefix+f.aliases[i])
+			fieldSet = d.setField(v.Field(f.idx), f, namespace)
+		}
+
+		if required != blank && !fieldSet && !d.expired {
+			missing = append(missing, required)
+		}
+
+		if fieldSet && ambiguous {
+			d.setError(namespace, newError(ErrAmbiguousField, errAmbiguousField, f.name))
+		}
+
+		// browsers send nothing for unchecked checkboxes, a missing key unchecks the field
+		// without counting as set so that parent pointers are not allocated for it
+		if !fieldSet && f.isCheckbox && !d.expired {
+			uncheck(v.Field(f.idx))
+		}
+
+		// fields without values get their defaults, which do not count as set either
+		if !fieldSet && !f.isCheckbox && !d.expired && (f.hasDefault || d.funcs.typeDefaults != nil) {
+			ns := d.appendName(namespace[:l], first, name)
+
+			if _, failed := d.errs[string(ns)]; !failed {
+				d.setDefault(v.Field(f.idx), f, ns)
+			}
+		}
+
+		if fieldSet {
+			if d.fieldSet != nil {
+				d.fieldSet[string(namespace)] = struct{}{}
+			}
+
+			// a field is collected by namespace unless values of its nested fields were collected
+			switch {
+			case d.goValues == nil:
+			case d.collectNested:
+				if len(d.goValues) == collected {
+					d.goValues[string(namespace)] = collectedValue(v.Field(f.idx))
+				}
+			case first:
+				d.goValues[name] = v.Field(f.idx).Interface()
+			}
+
+			set = true
+		}
+	}
+
+	if s.hasRemainder && !d.expired && !d.sourcesOnly && d.setRemainder(v.Field(s.remainderIdx), namespace[:l]) {
+		set = true
+	}
+
+	// required fields of optional nested structs are reported only when the struct has values
+	if set || first {
+		for _, ns := range missing {
+			d.setError([]byte(ns), &ConstraintError{Constraint: "required"})
+		}
+	}
+
+	// nested structs without decoded fields may be discarded, eg. of nil pointers, hooks are not called for them
+	if s.hasAfterDecode && !d.expired && (set || first && namePrefix == blank) {
+		d.afterDecode(v, namespace[:l])
+	}
+
+	return set
+}
+
+// collectedValue returns the value of a field collected by namespace, pointers are dereferenced.
+func collectedValue(fv reflect.Value) interface{} {
+	for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+
+	return fv.Interface()
+}
+
+// resetSlice sets a slice field with `reset` tag option to nil, a nil pointer is left as is.
+func resetSlice(fv reflect.Value) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return
 		}
 
-		if f.sliceSeparator != 0 {
-			if len(d.values[f.name]) > 0 {
-				d.values[f.name] = strings.Split(d.values[f.name][0], string(f.sliceSeparator))
-			}
+		fv = fv.Elem()
+	}
+
+	fv.Set(reflect.Zero(fv.Type()))
+}
+
+// uncheck sets a bool field with `checkbox` tag option to false, a nil pointer is left as is.
+func uncheck(fv reflect.Value) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return
+		}
+
+		fv = fv.Elem()
+	}
+
+	fv.SetBool(false)
+}
+
+// setRemainder collects keys within namespace that were not matched by any field into a `remainder` field,
+// keys and values are kept as they were decoded.
+func (d *decoder[DecodeFuncArgument]) setRemainder(fv reflect.Value, namespace []byte) bool {
+	ns := string(namespace)
+
+	var rest url.Values
+
+	for k, vals := range d.values {
+		if _, ok := d.consumed[k]; ok {
+			continue
+		}
+
+		if ns != blank && (!strings.HasPrefix(k, ns) || len(k) == len(ns) ||
+			k[len(ns)] != '[' && !strings.HasPrefix(k[len(ns):], d.opts.NamespacePrefix)) {
+			continue
+		}
+
+		if rest == nil {
+			rest = make(url.Values)
+		}
+
+		rest[k] = append([]string(nil), vals...)
+	}
+
+	if rest == nil {
+		return false
+	}
+
+	fv.Set(reflect.ValueOf(rest))
+
+	return true
+}
+
+// appendName appends field name to the namespace of its struct.
+func (d *decoder[DecodeFuncArgument]) appendName(namespace []byte, first bool, name string) []byte {
+	if first {
+		return append(namespace, name...)
+	}
+
+	namespace = append(namespace, d.opts.NamespacePrefix...)
+	namespace = append(namespace, name...)
+
+	return append(namespace, d.opts.NamespaceSuffix...)
+}
+
+// setUnexportedEmbedded decodes fields promoted through an embedded pointer to an unexported struct,
+// which reflection can traverse but not allocate, so fields are decoded only when the pointer is not nil.
+func (d *decoder[DecodeFuncArgument]) setUnexportedEmbedded(fv reflect.Value, namespace []byte, f cachedField, namePrefix string) bool {
+	if fv.IsNil() || fv.Type().Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	switch {
+	case f.isFlatten:
+		return d.traverseStruct(fv.Elem(), fv.Type().Elem(), namespace, namePrefix+f.flattenPrefix)
+	case f.hasExportedScalar && f.embed != embedNamespace:
+		return d.traverseStruct(fv.Elem(), fv.Type().Elem(), namespace, blank)
+	}
+
+	return false
+}
+
+// setFlattened decodes fields of a flattened struct with names prefixed,
+// a nil struct pointer is allocated only when a field is set.
+func (d *decoder[DecodeFuncArgument]) setFlattened(fv reflect.Value, namespace []byte, prefix string) bool {
+	if fv.Kind() != reflect.Ptr {
+		return d.traverseStruct(fv, fv.Type(), namespace, prefix)
+	}
+
+	if !fv.IsNil() {
+		return d.traverseStruct(fv.Elem(), fv.Type().Elem(), namespace, prefix)
+	}
+
+	// a struct flattened into itself, eg. `Next *Node` with `flatten`, is probed only for keys
+	// with its prefix as its fields are named as fields of the parent
+	if d.isTraversing(fv.Type().Elem()) && !d.hasFlattenedKeys(namespace, prefix) {
+		return false
+	}
+
+	newVal := reflect.New(fv.Type().Elem())
+
+	if d.traverseStruct(newVal.Elem(), fv.Type().Elem(), namespace, prefix) {
+		fv.Set(newVal)
+
+		return true
+	}
+
+	return false
+}
+
+// isTraversing reports whether a struct of the type is being decoded.
+func (d *decoder[DecodeFuncArgument]) isTraversing(typ reflect.Type) bool {
+	for _, t := range d.path {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasFlattenedKeys reports whether there are keys of fields flattened with prefix into namespace.
+func (d *decoder[DecodeFuncArgument]) hasFlattenedKeys(namespace []byte, prefix string) bool {
+	key := string(namespace)
+	if key != blank {
+		key += d.opts.NamespacePrefix
+	}
+
+	key += prefix
+
+	for k := range d.values {
+		if strings.HasPrefix(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setField decodes values of namespace into struct field fv applying the field tag options.
+func (d *decoder[DecodeFuncArgument]) setField(fv reflect.Value, f cachedField, namespace []byte) bool {
+	// discriminator applies to interface values of the field, including slice elements,
+	// but not to fields of the decoded variants
+	prevDiscriminator, prevType, prevPre := d.discriminator, d.declType, d.fieldPre
+	d.discriminator, d.declType = f.discriminator, fv.Type()
+
+	// preprocessors of the field apply to its elements and nested fields without their own
+	if len(f.options) > 0 {
+		if pre := d.funcs.fieldPreprocessors(f.options); pre != nil {
+			d.fieldPre = pre
+		}
+	}
+
+	// `reset` clears a slice even when no values are present, `append` accumulates values of repeated decodes
+	// regardless of the merge mode
+	prevMerge, prevPrefix, prevSuffix := d.opts.MergeMode, d.opts.NamespacePrefix, d.opts.NamespaceSuffix
+	prevLayout, prevLayouts, prevLocation, prevUnit := d.opts.TimeLayout, d.opts.TimeLayouts, d.opts.Location, d.unixUnit
+	prevBoolFormat, prevTrue, prevFalse := d.hasBoolFormat, d.boolTrue, d.boolFalse
+	d.unixUnit = f.unixUnit
+	d.hasBoolFormat, d.boolTrue, d.boolFalse = f.hasBoolFormat, f.boolTrue, f.boolFalse
+
+	if f.timeLayout != blank {
+		d.opts.TimeLayout, d.opts.TimeLayouts = f.timeLayout, nil
+	}
+
+	if f.location != nil {
+		d.opts.Location = f.location
+	}
+
+	if f.isReset {
+		resetSlice(fv)
+	}
+
+	if f.isAppend {
+		d.opts.MergeMode = MergeAppend
+	}
+
+	// names of nested fields of deepObject style are bracketed, eg. "filter[role]"
+	if f.isDeepObject {
+		d.opts.NamespacePrefix, d.opts.NamespaceSuffix = "[", "]"
+	}
+
+	var set bool
+
+	if f.src != blank && d.sources != nil {
+		set = d.setFieldFromSource(fv, f, namespace)
+	} else {
+		set = d.setFieldValue(fv, f, namespace)
+	}
+
+	d.discriminator, d.declType, d.fieldPre = prevDiscriminator, prevType, prevPre
+	d.opts.MergeMode, d.opts.NamespacePrefix, d.opts.NamespaceSuffix = prevMerge, prevPrefix, prevSuffix
+	d.opts.TimeLayout, d.opts.TimeLayouts, d.opts.Location, d.unixUnit = prevLayout, prevLayouts, prevLocation, prevUnit
+	d.hasBoolFormat, d.boolTrue, d.boolFalse = prevBoolFormat, prevTrue, prevFalse
+
+	return set
+}
+
+// isSourceField reports whether a field is decoded when only fields of sources other than the body are,
+// fields with such `src` tag option and structs which may have them.
+func isSourceField(f cachedField, typ reflect.Type) bool {
+	if f.src != blank {
+		return f.src != srcBody
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind() == reflect.Struct && !isValueStruct(typ)
+}
+
+// setFieldFromSource decodes a field with `src` tag option from values of the named source,
+// eg. `form:"id,src=query"` of DecodeRequest, unknown sources have no values.
+func (d *decoder[DecodeFuncArgument]) setFieldFromSource(fv reflect.Value, f cachedField, namespace []byte) bool {
+	values, owned, dm, dmDone, maxKeyLen, isHeader := d.values, d.valuesOwned, d.dm, d.dmDone, d.maxKeyLen, d.isHeader
+
+	// parsed map data is not reused as it refers to keys of values
+	d.values, d.valuesOwned, d.dm, d.dmDone = d.sources[f.src], false, nil, false
+
+	// path parameters are looked up by name, they are scalar values of a single key
+	if f.src == srcPath && d.pathParams != nil {
+		d.values = nil
+
+		if v, ok := d.pathParams.PathParam(string(namespace)); ok {
+			d.values = url.Values{string(namespace): {v}}
+		}
+	}
+	d.isHeader = f.src == srcHeader
+	set := d.setFieldValue(fv, f, namespace)
+	d.values, d.valuesOwned, d.dm, d.dmDone, d.maxKeyLen, d.isHeader = values, owned, dm, dmDone, maxKeyLen, isHeader
+
+	return set
+}
+
+// setFieldValue decodes a struct field, see setField, and checks constraints of the decoded value.
+func (d *decoder[DecodeFuncArgument]) setFieldValue(fv reflect.Value, f cachedField, namespace []byte) bool {
+	set := d.decodeFieldValue(fv, f, namespace)
+
+	if set && f.constraints != nil {
+		d.checkConstraints(fv, f.constraints, namespace)
+	}
+
+	return set
+}
+
+// decodeFieldValue decodes a struct field applying its tag options.
+func (d *decoder[DecodeFuncArgument]) decodeFieldValue(fv reflect.Value, f cachedField, namespace []byte) bool {
+	if f.invalidOption != blank {
+		if arr, _ := d.lookup(namespace); len(arr) > 0 {
+			d.setValueError(namespace, fv.Kind(), arr[0], newError(ErrInvalidTagOption, "invalid tag option '%s' namespace '%s'",
+				f.invalidOption, string(namespace)))
+
+			return false
+		}
+	}
+
+	if f.isJoinedObject {
+		return d.setJoinedObject(fv, namespace)
+	}
+
+	if f.sliceSeparator != blank {
+		if arr, _ := d.lookup(namespace); len(arr) > 0 {
+			d.replaceValues(string(namespace), strings.Split(arr[0], f.sliceSeparator))
+		}
+	} else if d.isHeader {
+		d.splitHeaderList(fv.Type(), namespace)
+	}
+
+	if f.maxLen != 0 && !d.checkMaxLen(namespace, f) {
+		return false
+	}
+
+	if f.isStream {
+		return d.streamField(fv, namespace)
+	}
+
+	if f.isEmptyZero && d.setEmptyZero(fv, namespace) {
+		return true
+	}
+
+	if f.isRaw {
+		return d.setRawField(fv, namespace)
+	}
+
+	if cf, option := d.fieldTagFunc(f); cf != nil {
+		return d.setFieldByFunc(fv, namespace, cf, option)
+	}
+
+	if d.opts.LooseInterfaces && fv.Kind() == reflect.Interface && fv.IsNil() && fv.NumMethod() == 0 {
+		if set, ok := d.setLooseInterface(fv, namespace); ok {
+			return set
+		}
+	}
+
+	return d.setFieldByType(fv, false, namespace, 0)
+}
+
+// setLooseInterface decodes repeated values of a nil empty interface field as []string, ok is false when
+// the field is decoded by a registered factory or function or has a single value.
+func (d *decoder[DecodeFuncArgument]) setLooseInterface(fv reflect.Value, namespace []byte) (set, ok bool) {
+	if _, found := d.funcs.ifaceFactories[fv.Type()]; found {
+		return false, false
+	}
+
+	if _, found := d.funcs.customTypeFuncs[fv.Type()]; found {
+		return false, false
+	}
+
+	arr, found := d.lookup(namespace)
+	if !found || len(arr) < 2 {
+		return false, false
+	}
+
+	values := make([]string, len(arr))
+
+	for i, raw := range arr {
+		s, err := d.checkUTF8(raw)
+		if err != nil {
+			d.setValueError(namespace, reflect.Interface, raw, err)
+
+			return false, true
+		}
+
+		values[i] = s
+	}
+
+	fv.Set(reflect.ValueOf(values))
+
+	if d.provenance != nil {
+		d.setProvenance(string(namespace))
+	}
+
+	return true, true
+}
+
+// setEmptyZero sets a number field with `emptyzero` tag option to zero when its value is empty,
+// eg. of an HTML number input left blank, a nil pointer is set to a zero number.
+func (d *decoder[DecodeFuncArgument]) setEmptyZero(fv reflect.Value, namespace []byte) bool {
+	arr, ok := d.lookup(namespace)
+	if !ok || len(arr) == 0 || arr[0] != blank {
+		return false
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		fv.Set(reflect.New(fv.Type().Elem()))
+	} else {
+		fv.Set(reflect.Zero(fv.Type()))
+	}
+
+	return true
+}
+
+// setVariant decodes an interface field into a registered variant selected by the discriminator key
+// of the field, eg. "payment.type=card".
+func (d *decoder[DecodeFuncArgument]) setVariant(current reflect.Value, namespace []byte, idx int, variants map[string]reflect.Type) bool {
+	key := d.appendName(append([]byte(nil), namespace...), len(namespace) == 0, d.discriminator)
+
+	arr, ok := d.lookup(key)
+	if !ok || len(arr) == 0 || arr[0] == blank {
+		return false
+	}
+
+	t, ok := variants[arr[0]]
+	if !ok {
+		d.setValueError(key, reflect.String, arr[0], newError(ErrUnsupportedType, "unknown variant '%s' of interface '%v' namespace '%s'",
+			arr[0], current.Type(), string(key)))
+
+		return false
+	}
+
+	var val interface{}
+
+	switch {
+	case t.Kind() == reflect.Ptr:
+		val = reflect.New(t.Elem()).Interface()
+	case t.Implements(current.Type()):
+		val = reflect.New(t).Elem().Interface()
+	default:
+		val = reflect.New(t).Interface()
+	}
+
+	return d.setInterface(current, namespace, idx, val)
+}
+
+// setInterface decodes an interface field into val, a value returned by a factory or a new variant.
+func (d *decoder[DecodeFuncArgument]) setInterface(current reflect.Value, namespace []byte, idx int, val interface{}) bool {
+	if val == nil {
+		return false
+	}
+
+	rv := reflect.ValueOf(val)
+
+	if !rv.Type().Implements(current.Type()) {
+		d.setError(namespace, newError(ErrUnsupportedType, "type '%v' returned for interface '%v' does not implement it namespace '%s'",
+			rv.Type(), current.Type(), string(namespace)))
+
+		return false
+	}
+
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		d.setFieldByType(rv.Elem(), false, namespace, idx)
+	} else {
+		// values are copied to be addressable
+		nv := reflect.New(rv.Type()).Elem()
+		nv.Set(rv)
+		d.setFieldByType(nv, false, namespace, idx)
+		rv = nv
+	}
+
+	current.Set(rv)
+
+	return true
+}
+
+// setRawField assigns values of a `raw` field without conversion, several values are joined with comma
+// for string and []byte fields.
+func (d *decoder[DecodeFuncArgument]) setRawField(fv reflect.Value, namespace []byte) bool {
+	arr, ok := d.lookup(namespace)
+	if !ok || len(arr) == 0 {
+		return false
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+
+		fv = fv.Elem()
+	}
+
+	switch {
+	case fv.Kind() == reflect.String:
+		fv.SetString(strings.Join(arr, ","))
+	case fv.Type().Elem().Kind() == reflect.Uint8:
+		fv.SetBytes([]byte(strings.Join(arr, ",")))
+	default:
+		fv.Set(reflect.ValueOf(append([]string(nil), arr...)).Convert(fv.Type()))
+	}
+
+	return true
+}
+
+// streamField passes values of a namespace one at a time to the function of a `stream` field,
+// the function takes the element value and returns nothing, a bool to continue or an error.
+func (d *decoder[DecodeFuncArgument]) streamField(fn reflect.Value, namespace []byte) bool {
+	arr, ok := d.lookup(namespace)
+	if !ok || len(arr) == 0 || fn.IsNil() {
+		return false
+	}
+
+	typ := fn.Type()
+
+	if typ.NumIn() != 1 || typ.NumOut() > 1 ||
+		(typ.NumOut() == 1 && typ.Out(0).Kind() != reflect.Bool && typ.Out(0) != errorType) {
+		d.setError(namespace, newError(ErrUnsupportedType, "invalid stream function type '%v'", typ))
+
+		return false
+	}
+
+	elemType := typ.In(0)
+	elem := reflect.New(elemType).Elem()
+	zero := reflect.Zero(elemType)
+	args := []reflect.Value{elem}
+	set := false
+
+	for i := range arr {
+		elem.Set(zero)
+
+		errs := len(d.errs)
+
+		if !d.setFieldByType(elem, false, namespace, i) {
+			if len(d.errs) > errs || d.expired {
+				return set
+			}
+
+			continue
+		}
+
+		out := fn.Call(args)
+		set = true
+
+		if len(out) == 0 {
+			continue
+		}
+
+		if out[0].Kind() == reflect.Bool {
+			if !out[0].Bool() {
+				return set
+			}
+
+			continue
+		}
+
+		if !out[0].IsNil() {
+			d.setValueError(namespace, elem.Kind(), arr[i], out[0].Interface().(error)) //nolint:forcetypeassert
+
+			return set
+		}
+	}
+
+	return set
+}
+
+// checkMaxLen reports whether values of a namespace fit the maximum length.
+func (d *decoder[DecodeFuncArgument]) checkMaxLen(namespace []byte, f cachedField) bool {
+	arr, _ := d.lookup(namespace)
+
+	if f.maxLen < 0 && len(arr) > 0 {
+		ml, _ := f.options.get("maxlen")
+		d.setValueError(namespace, reflect.String, arr[0], newError(ErrInvalidTagOption, "invalid tag option 'maxlen=%s' namespace '%s'",
+			ml, string(namespace)))
+
+		return false
+	}
+
+	for _, s := range arr {
+		if len(s) > f.maxLen {
+			d.setValueError(namespace, reflect.String, s, &TooLongError{MaxLen: f.maxLen, Len: len(s)})
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *decoder[DecodeFuncArgument]) fieldTagFunc(f cachedField) (DecodeFunc[DecodeFuncArgument], string) {
+	if d.funcs.customTagFuncs == nil || len(f.options) == 0 {
+		return nil, blank
+	}
+
+	return d.funcs.tagFunc(f.options)
+}
+
+// setFieldByFunc assigns the whole field with a result of custom function of the tag option.
+func (d *decoder[DecodeFuncArgument]) setFieldByFunc(current reflect.Value, namespace []byte, cf DecodeFunc[DecodeFuncArgument], option string) bool {
+	arr, ok := d.lookup(namespace)
+	if !ok || len(arr) == 0 {
+		return false
+	}
+
+	// functions of types receive the type they are registered for, so do functions of tags
+	typ := current.Type()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	val, err := cf(arr[0], d.funcArgument(typ, option))
+	if err != nil {
+		d.setValueError(namespace, current.Kind(), arr[0], err)
+
+		return false
+	}
+
+	rv, ok := funcResult(reflect.ValueOf(val), current.Type())
+	if !ok {
+		d.setValueError(namespace, current.Kind(), arr[0], newError(ErrUnsupportedType, "unsupported result type '%T' of custom func for type '%v' namespace '%s'",
+			val, current.Type(), string(namespace)))
+
+		return false
+	}
+
+	current.Set(rv)
+
+	if d.provenance != nil {
+		d.setProvenance(string(namespace))
+	}
+
+	return true
+}
+
+// funcResult returns result of custom function assignable to field type, results of pointer fields
+// may be values of the pointer element, results of the same kind are converted, eg. []int to a named slice type.
+func funcResult(rv reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	if !rv.IsValid() {
+		return rv, false
+	}
+
+	if rv.Type().AssignableTo(typ) {
+		return rv, true
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		elem, ok := funcResult(rv, typ.Elem())
+		if !ok {
+			return rv, false
 		}
 
-		if d.setFieldByType(v.Field(f.idx), false, namespace, 0) {
-			if d.goValues != nil && f.name == string(namespace) {
-				d.goValues[f.name] = v.Field(f.idx).Interface()
-			}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
 
-			set = true
-		}
+		return ptr, true
 	}
 
-	return set
+	if rv.Kind() == typ.Kind() && rv.Type().ConvertibleTo(typ) {
+		return rv.Convert(typ), true
+	}
+
+	return rv, false
 }
 
 //nolint:maintidx // This function is indeed a bit large, but sequentially structured.
-func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPtr bool, namespace []byte, idx int) bool {
+func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPtr bool, namespace []byte, idx int) (fieldSet bool) {
+	if d.timedOut() {
+		return false
+	}
+
+	if current.Kind() == reflect.Interface {
+		if fn, found := d.funcs.ifaceFactories[current.Type()]; found {
+			return d.setInterface(current, namespace, idx, fn(d.values, string(namespace)))
+		}
+
+		if d.discriminator != blank {
+			if variants, found := d.funcs.variants[current.Type()]; found {
+				return d.setVariant(current, namespace, idx, variants)
+			}
+		}
+	}
+
 	v, kind := ExtractType(current)
-	arr, ok := d.values[string(namespace)]
+	arr, ok := d.lookup(namespace)
+
+	if ok && d.provenance != nil {
+		ns := string(namespace)
 
-	if d.d.customTypeFuncs != nil {
-		if ok {
-			if cf, ok := d.d.customTypeFuncs[v.Type()]; ok {
-				val, err := cf(arr[idx], d.decodeFuncArgument)
+		defer func() {
+			if fieldSet {
+				d.setProvenance(ns)
+			}
+		}()
+	}
+
+	if d.funcs.customTypeFuncs != nil && (d.opts.FuncScope == FuncScopeDerived || current.Type() == d.declType) {
+		if ok && idx < len(arr) {
+			if cf, ok := d.funcs.customTypeFuncs[v.Type()]; ok {
+				val, err := cf(arr[idx], d.funcArgument(v.Type(), blank))
 				if err != nil {
-					d.setError(namespace, err)
+					d.setValueError(namespace, kind, arr[idx], err)
 
 					return false
 				}
@@ -220,6 +1430,16 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 		}
 	}
 
+	if d.opts.ReaderMode == ReaderLazy && (current.Type() == readerType || current.Type() == stringsReaderType) {
+		if !ok || idx >= len(arr) {
+			return false
+		}
+
+		current.Set(reflect.ValueOf(strings.NewReader(arr[idx])))
+
+		return true
+	}
+
 	if v.Type() == timeType {
 		if !ok || len(arr[idx]) == 0 {
 			return false
@@ -229,9 +1449,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		t, err := time.Parse(time.RFC3339, arr[idx])
+		t, err := d.parseTime(arr[idx])
 		if err != nil {
-			d.setError(namespace, err)
+			d.setValueError(namespace, kind, arr[idx], err)
 
 			return false
 		}
@@ -241,10 +1461,69 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 		return true
 	}
 
-	if ok {
+	if vt, found := valueTypes[v.Type()]; found {
+		if !ok || idx >= len(arr) || len(arr[idx]) == 0 {
+			return false
+		}
+
+		val, err := vt.decode(arr[idx])
+		if err != nil {
+			d.setValueError(namespace, kind, arr[idx], fmt.Errorf("%w '%s' type '%v' namespace '%s': %w",
+				ErrInvalidValue, arr[idx], v.Type(), string(namespace), err))
+
+			return false
+		}
+
+		v.Set(reflect.ValueOf(val))
+
+		return true
+	}
+
+	// text methods are preferred, [16]byte types implementing only fmt.Stringer are decoded as UUID
+	if isUUIDType(v.Type()) && !(current.CanAddr() && reflect.PointerTo(v.Type()).Implements(textUnmarshalerType)) {
+		if !ok || idx >= len(arr) || len(arr[idx]) == 0 {
+			return false
+		}
+
+		u, err := ParseUUID(arr[idx])
+		if err != nil {
+			d.setValueError(namespace, kind, arr[idx], fmt.Errorf("invalid value '%s' type '%v' namespace '%s': %w",
+				arr[idx], v.Type(), string(namespace), err))
+
+			return false
+		}
+
+		v.Set(reflect.ValueOf(u).Convert(v.Type()))
+
+		return true
+	}
+
+	if v.Type() == durationType {
+		if !ok || idx >= len(arr) || len(arr[idx]) == 0 {
+			return false
+		}
+
+		dur, err := parseDuration(arr[idx])
+		if err != nil {
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidDuration, "invalid duration value '%s' type '%v' namespace '%s'",
+				arr[idx], v.Type(), string(namespace)))
+
+			return false
+		}
+
+		v.SetInt(int64(dur))
+
+		return true
+	}
+
+	if ok && idx < len(arr) && current.CanAddr() {
+		if o, ok := current.Addr().Interface().(optional); ok {
+			return d.setOptional(o, namespace, idx, arr[idx])
+		}
+
 		if tu, ok := current.Addr().Interface().(encoding.TextUnmarshaler); ok {
 			if err := tu.UnmarshalText([]byte(arr[idx])); err != nil {
-				d.setError(namespace, err)
+				d.setValueError(namespace, kind, arr[idx], err)
 
 				return false
 			}
@@ -255,7 +1534,8 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 
 	switch kind {
 	case reflect.Interface:
-		if !ok || idx == len(arr) {
+		// interfaces with methods, eg. io.Reader, can not hold a string
+		if !ok || idx == len(arr) || v.NumMethod() > 0 {
 			return false
 		}
 
@@ -276,7 +1556,14 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		v.SetString(arr[idx])
+		s, err := d.checkUTF8(arr[idx])
+		if err != nil {
+			d.setValueError(namespace, kind, arr[idx], err)
+
+			return false
+		}
+
+		v.SetString(s)
 
 		return true
 
@@ -285,9 +1572,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		u64, err := strconv.ParseUint(arr[idx], 10, 64)
+		u64, err := d.parseUint(arr[idx], 64)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -302,9 +1589,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		u64, err := strconv.ParseUint(arr[idx], 10, 8)
+		u64, err := d.parseUint(arr[idx], 8)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -319,9 +1606,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		u64, err := strconv.ParseUint(arr[idx], 10, 16)
+		u64, err := d.parseUint(arr[idx], 16)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -336,9 +1623,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		u64, err := strconv.ParseUint(arr[idx], 10, 32)
+		u64, err := d.parseUint(arr[idx], 32)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -353,9 +1640,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		i64, err := strconv.ParseInt(arr[idx], 10, 64)
+		i64, err := d.parseInt(arr[idx], 64)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -370,9 +1657,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		i64, err := strconv.ParseInt(arr[idx], 10, 8)
+		i64, err := d.parseInt(arr[idx], 8)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -387,9 +1674,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		i64, err := strconv.ParseInt(arr[idx], 10, 16)
+		i64, err := d.parseInt(arr[idx], 16)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -404,9 +1691,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		i64, err := strconv.ParseInt(arr[idx], 10, 32)
+		i64, err := d.parseInt(arr[idx], 32)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -421,9 +1708,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		f, err := strconv.ParseFloat(arr[idx], 32)
+		f, err := d.parseFloat(arr[idx], 32)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid float value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidFloat, "invalid float value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -438,9 +1725,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		f, err := strconv.ParseFloat(arr[idx], 64)
+		f, err := d.parseFloat(arr[idx], 64)
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid float value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidFloat, "invalid float value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -455,9 +1742,9 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			return false
 		}
 
-		b, err := parseBool(arr[idx])
+		b, err := d.parseBool(arr[idx])
 		if err != nil {
-			d.setError(namespace, fmt.Errorf("invalid boolean value '%s' type '%v' namespace '%s'",
+			d.setValueError(namespace, kind, arr[idx], newError(ErrInvalidBool, "invalid boolean value '%s' type '%v' namespace '%s'",
 				arr[idx], v.Type(), string(namespace)))
 
 			return false
@@ -477,6 +1764,7 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 		// slice elements could be mixed eg. number and non-numbers Value[0]=[]string{"10"} and Value=[]string{"10","20"}
 
 		set := false
+		overlay := d.opts.MergeMode == MergeOverlay
 
 		if ok && len(arr) > 0 {
 			var varr reflect.Value
@@ -485,7 +1773,7 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 
 			l := len(arr)
 
-			if v.IsNil() {
+			if v.IsNil() || overlay {
 				varr = reflect.MakeSlice(v.Type(), len(arr), len(arr))
 			} else {
 				ol = v.Len()
@@ -510,7 +1798,10 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 				}
 			}
 
-			v.Set(varr)
+			// existing elements are not replaced by a slice without valid values
+			if set || !overlay {
+				v.Set(varr)
+			}
 		}
 
 		// maybe it's an numbered array i.e. Phone[0].Number
@@ -522,14 +1813,37 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 
 			sl := rd.sliceLen + 1
 
+			var positions map[int]int
+
+			// indexed values are appended after existing elements, including values decoded above
+			var ol int
+
+			if d.opts.MergeMode == MergeAppend {
+				ol = v.Len()
+			}
+
+			if d.opts.SparseMode != SparseKeep {
+				if positions = compactIndexes(rd.keys, sl); positions != nil {
+					if d.opts.SparseMode == SparseError {
+						d.setError(namespace, newError(ErrSparseSlice, "sparse slice indexes, %d values for length %d", len(positions), sl))
+
+						return false
+					}
+
+					sl = len(positions)
+				}
+			}
+
+			sl += ol
+
 			// checking below for defaultMaxArraySize, but if array exists and already
 			// has sufficient capacity allocated then we do not check as the code
 			// obviously allows a capacity greater than the defaultMaxArraySize.
 
 			switch {
-			case v.IsNil():
-				if sl > d.d.maxArraySize {
-					d.setError(namespace, fmt.Errorf(errArraySize, sl, d.d.maxArraySize))
+			case v.IsNil(), overlay && !set:
+				if sl > d.opts.MaxArraySize {
+					d.setError(namespace, newError(ErrArrayIndexOutOfBounds, errArraySize, sl, d.opts.MaxArraySize))
 
 					return false
 				}
@@ -537,8 +1851,8 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 				varr = reflect.MakeSlice(v.Type(), sl, sl)
 			case v.Len() < sl:
 				if v.Cap() <= sl {
-					if sl > d.d.maxArraySize {
-						d.setError(namespace, fmt.Errorf(errArraySize, sl, d.d.maxArraySize))
+					if sl > d.opts.MaxArraySize {
+						d.setError(namespace, newError(ErrArrayIndexOutOfBounds, errArraySize, sl, d.opts.MaxArraySize))
 
 						return false
 					}
@@ -558,15 +1872,22 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 				newVal := reflect.New(varr.Type().Elem()).Elem()
 
 				if kv.ivalue == -1 {
-					d.setError(namespace, fmt.Errorf("invalid slice index '%s'", kv.value))
+					d.setError(namespace, newError(ErrInvalidIndex, "invalid slice index '%s'", kv.value))
 
 					continue
 				}
 
+				i := kv.ivalue
+				if positions != nil {
+					i = positions[kv.ivalue]
+				}
+
+				i += ol
+
 				if d.setFieldByType(newVal, false, append(namespace, kv.searchValue...), 0) {
 					set = true
 
-					varr.Index(kv.ivalue).Set(newVal)
+					varr.Index(i).Set(newVal)
 				}
 			}
 
@@ -590,21 +1911,23 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 
 		// array elements could be mixed eg. number and non-numbers Value[0]=[]string{"10"} and Value=[]string{"10","20"}
 		set := false
+		overlay := d.opts.MergeMode == MergeOverlay
 
 		if ok && len(arr) > 0 {
 			var varr reflect.Value
 
 			l := len(arr)
-			overCapacity := v.Len() < l
 
-			if overCapacity {
-				// more values than array capacity, ignore values over capacity as it's possible some would just want
-				// to grab the first x number of elements; in the future strict mode logic should return an error
-				fmt.Println("warning number of post form array values is larger than array capacity, ignoring overflow values")
+			// values over the array length are reported, the elements that fit are still decoded
+			if v.Len() < l {
+				d.setError(namespace, newError(ErrArrayIndexOutOfBounds, "%d values exceed array length %d", l, v.Len()))
 			}
 
 			varr = reflect.Indirect(reflect.New(reflect.ArrayOf(v.Len(), v.Type().Elem())))
-			reflect.Copy(varr, v)
+
+			if !overlay {
+				reflect.Copy(varr, v)
+			}
 
 			if v.Len() < len(arr) {
 				l = v.Len()
@@ -619,7 +1942,10 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 					varr.Index(i).Set(newVal)
 				}
 			}
-			v.Set(varr)
+
+			if set || !overlay {
+				v.Set(varr)
+			}
 		}
 
 		// maybe it's an numbered array i.e. Phone[0].Number
@@ -629,26 +1955,26 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 				kv   key
 			)
 
-			overCapacity := rd.sliceLen >= v.Len()
-			if overCapacity {
-				// more values than array capacity, ignore values over capacity as it's possible some would just want
-				// to grab the first x number of elements; in the future strict mode logic should return an error
-				fmt.Println("warning number of post form array values is larger than array capacity, ignoring overflow values")
-			}
-
 			varr = reflect.Indirect(reflect.New(reflect.ArrayOf(v.Len(), v.Type().Elem())))
-			reflect.Copy(varr, v)
+
+			if !overlay || set {
+				reflect.Copy(varr, v)
+			}
 
 			for i := 0; i < len(rd.keys); i++ {
 				kv = rd.keys[i]
-				if kv.ivalue >= v.Len() {
-					continue
-				}
-
 				newVal := reflect.New(varr.Type().Elem()).Elem()
 
 				if kv.ivalue == -1 {
-					d.setError(namespace, fmt.Errorf("invalid array index '%s'", kv.value))
+					d.setError(namespace, newError(ErrInvalidIndex, "invalid array index '%s'", kv.value))
+
+					continue
+				}
+
+				// elements out of range are reported for their own key, other elements are still decoded
+				if kv.ivalue >= v.Len() {
+					d.setError(append(namespace, kv.searchValue...), newError(ErrArrayIndexOutOfBounds,
+						"array index '%s' out of bounds for length %d", kv.value, v.Len()))
 
 					continue
 				}
@@ -694,7 +2020,7 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 
 		typ := v.Type()
 
-		if v.IsNil() {
+		if v.IsNil() || d.opts.MergeMode == MergeOverlay {
 			mp = reflect.MakeMap(typ)
 		} else {
 			existing = true
@@ -703,17 +2029,41 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 
 		set := false
 
+		// the same key is listed for every sub-path, eg. Accounts[alice].Name and Accounts[alice].Address.City,
+		// while value is decoded with all of them at once
+		var seen map[string]struct{}
+
+		if len(rd.keys) > 1 {
+			seen = make(map[string]struct{}, len(rd.keys))
+		}
+
 		for i := 0; i < len(rd.keys); i++ {
+			kv = rd.keys[i]
+
+			if seen != nil {
+				if _, ok := seen[kv.value]; ok {
+					continue
+				}
+
+				seen[kv.value] = struct{}{}
+			}
+
 			newVal := reflect.New(typ.Elem()).Elem()
 			mk = reflect.New(typ.Key()).Elem()
-			kv = rd.keys[i]
 
 			if err := d.getMapKey(kv.value, mk, namespace); err != nil {
-				d.setError(namespace, err)
+				d.setValueError(namespace, mk.Kind(), kv.value, err)
 
 				continue
 			}
 
+			// merge into existing value, so that fields not present in values are kept
+			if existing {
+				if ev := mp.MapIndex(mk); ev.IsValid() {
+					newVal.Set(ev)
+				}
+			}
+
 			if d.setFieldByType(newVal, false, append(namespace, kv.searchValue...), 0) {
 				set = true
 
@@ -721,11 +2071,13 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 			}
 		}
 
-		if !set || existing {
+		if !set {
 			return false
 		}
 
-		v.Set(mp)
+		if !existing {
+			v.Set(mp)
+		}
 
 		return true
 
@@ -737,22 +2089,265 @@ func (d *decoder[DecodeFuncArgument]) setFieldByType(current reflect.Value, isPt
 		}
 
 		// we must be recursing infinitely...but that's ok we caught it on the very first overrun.
+		// Structs without values are not traversed, their fields still get their defaults.
 		if len(namespace) > d.maxKeyLen {
+			if d.funcs.typeDefaults != nil || hasDefaultTags(v.Type()) {
+				d.setDefaults(v, namespace, false)
+			}
+
 			return false
 		}
 
-		return d.traverseStruct(v, v.Type(), namespace)
+		return d.traverseStruct(v, v.Type(), namespace, blank)
 	}
 
 	return false
 }
 
+// setOptional decodes the value of an Optional like a value of its type, an empty value clears it.
+func (d *decoder[DecodeFuncArgument]) setOptional(o optional, namespace []byte, idx int, s string) bool {
+	if len(s) == 0 {
+		o.Clear()
+
+		return true
+	}
+
+	if !d.setFieldByType(o.optionalValue(), false, namespace, idx) {
+		return false
+	}
+
+	o.markSet()
+
+	return true
+}
+
+// parseDuration parses durations, eg. "30s", integers are nanoseconds as they were before durations were parsed.
+func parseDuration(s string) (time.Duration, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(n), nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// parseBool parses strings of `bool` tag option of the field, eg. "on" and "off" of `bool=on|off`,
+// and strings set by Decoder.SetBoolStrings or the default ones.
+func (d *decoder[DecodeFuncArgument]) parseBool(s string) (bool, error) {
+	if d.hasBoolFormat {
+		switch s {
+		case d.boolTrue:
+			return true, nil
+		case d.boolFalse:
+			return false, nil
+		}
+	}
+
+	if d.funcs.boolStrings == nil {
+		return parseBool(s)
+	}
+
+	if b, ok := d.funcs.boolStrings[s]; ok {
+		return b, nil
+	}
+
+	if s == blank {
+		return false, nil
+	}
+
+	return false, &strconv.NumError{Func: "ParseBool", Num: s, Err: strconv.ErrSyntax}
+}
+
+func (d *decoder[DecodeFuncArgument]) parseInt(s string, bitSize int) (int64, error) {
+	if d.funcs.parseInt != nil {
+		return d.funcs.parseInt(s, bitSize)
+	}
+
+	n, ok := d.normalizeNumber(s)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrSyntax}
+	}
+
+	i64, err := strconv.ParseInt(n, d.intBase(n), bitSize)
+	if err != nil && d.opts.PermissiveNumbers {
+		if i, ok := parseExponent(n); ok {
+			if i == nil || !i.IsInt64() || i.Int64()<<(64-bitSize)>>(64-bitSize) != i.Int64() {
+				return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+			}
+
+			return i.Int64(), nil
+		}
+	}
+
+	return i64, err
+}
+
+func (d *decoder[DecodeFuncArgument]) parseUint(s string, bitSize int) (uint64, error) {
+	if d.funcs.parseUint != nil {
+		return d.funcs.parseUint(s, bitSize)
+	}
+
+	n, ok := d.normalizeNumber(s)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrSyntax}
+	}
+
+	u64, err := strconv.ParseUint(n, d.intBase(n), bitSize)
+	if err != nil && d.opts.PermissiveNumbers {
+		if i, ok := parseExponent(n); ok {
+			if i == nil || !i.IsUint64() || bitSize < 64 && i.Uint64() >= 1<<bitSize {
+				return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+			}
+
+			return i.Uint64(), nil
+		}
+	}
+
+	return u64, err
+}
+
+func (d *decoder[DecodeFuncArgument]) parseFloat(s string, bitSize int) (float64, error) {
+	if d.funcs.parseFloat != nil {
+		return d.funcs.parseFloat(s, bitSize)
+	}
+
+	n, ok := d.normalizeNumber(s)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: s, Err: strconv.ErrSyntax}
+	}
+
+	return strconv.ParseFloat(n, bitSize)
+}
+
+// intBase returns base of an integer literal, 0 lets strconv detect prefixed literals of permissive numbers,
+// other literals are decimal so that leading zeros of eg. "010" are not read as octal.
+func (d *decoder[DecodeFuncArgument]) intBase(s string) int {
+	if !d.opts.PermissiveNumbers {
+		return 10
+	}
+
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+
+	if len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X', 'o', 'O', 'b', 'B':
+			return 0
+		}
+	}
+
+	return 10
+}
+
+// parseExponent parses a whole number in scientific notation, eg. "1e3" or "2.5e2",
+// numbers that can not fit in 64 bits are reported as nil.
+func parseExponent(s string) (*big.Int, bool) {
+	e := strings.IndexAny(s, "eE")
+	if e == -1 {
+		return nil, false
+	}
+
+	// exponent is bounded before parsing, eg. "1e600000000" would otherwise allocate a huge integer
+	exp := s[e+1:]
+	negative := strings.HasPrefix(exp, "-")
+	exp = strings.TrimLeft(exp, "+-")
+
+	if !isDigits(exp) {
+		return nil, false
+	}
+
+	if len(strings.TrimLeft(exp, "0")) > maxExponentDigits {
+		return nil, !negative
+	}
+
+	f, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	if err != nil || !f.IsInt() {
+		return nil, false
+	}
+
+	if f.MantExp(nil) > 64 {
+		return nil, true
+	}
+
+	i, _ := f.Int(nil)
+
+	return i, true
+}
+
+// normalizeNumber removes thousands separators and replaces decimal separator set by Decoder.SetNumberSeparators,
+// eg. "1.234,56" to "1234.56", it reports false when digits between thousands separators are not grouped by three.
+func (d *decoder[DecodeFuncArgument]) normalizeNumber(s string) (string, bool) {
+	thousands, decimal := d.opts.ThousandsSeparator, d.opts.DecimalSeparator
+	if decimal == 0 {
+		decimal = '.'
+	}
+
+	if thousands == 0 && decimal == '.' {
+		return s, true
+	}
+
+	var (
+		b       strings.Builder
+		digits  int
+		grouped bool
+		intPart = true
+	)
+
+	b.Grow(len(s))
+
+	for _, r := range s {
+		switch {
+		case intPart && thousands != 0 && r == thousands:
+			if digits == 0 || digits > 3 || grouped && digits != 3 {
+				return s, false
+			}
+
+			grouped = true
+			digits = 0
+
+			continue
+
+		case intPart && r == decimal:
+			if grouped && digits != 3 {
+				return s, false
+			}
+
+			intPart = false
+			r = '.'
+
+		case r >= '0' && r <= '9':
+			digits++
+		}
+
+		b.WriteRune(r)
+	}
+
+	if intPart && grouped && digits != 3 {
+		return s, false
+	}
+
+	return b.String(), true
+}
+
+// checkUTF8 validates string value according to UTF-8 mode.
+func (d *decoder[DecodeFuncArgument]) checkUTF8(s string) (string, error) {
+	if d.opts.UTF8 == UTF8Accept || utf8.ValidString(s) {
+		return s, nil
+	}
+
+	if d.opts.UTF8 == UTF8Replace {
+		return strings.ToValidUTF8(s, string(utf8.RuneError)), nil
+	}
+
+	return s, ErrInvalidUTF8
+}
+
 func (d *decoder[DecodeFuncArgument]) getMapKey(key string, current reflect.Value, namespace []byte) (err error) {
 	v, kind := ExtractType(current)
 
-	if d.d.customTypeFuncs != nil {
-		if cf, ok := d.d.customTypeFuncs[v.Type()]; ok {
-			val, er := cf(key, d.decodeFuncArgument)
+	if d.funcs.customTypeFuncs != nil && d.opts.FuncScope == FuncScopeDerived {
+		if cf, ok := d.funcs.customTypeFuncs[v.Type()]; ok {
+			val, er := cf(key, d.funcArgument(v.Type(), blank))
 			if er != nil {
 				err = er
 
@@ -765,6 +2360,27 @@ func (d *decoder[DecodeFuncArgument]) getMapKey(key string, current reflect.Valu
 		}
 	}
 
+	if v.Type() == timeType {
+		t, e := d.parseTime(key)
+		if e != nil {
+			return fmt.Errorf("invalid time value '%s' type '%v' namespace '%s': %w", key, v.Type(), string(namespace), e)
+		}
+
+		v.Set(reflect.ValueOf(t))
+
+		return nil
+	}
+
+	if v.CanAddr() {
+		if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if e := tu.UnmarshalText([]byte(key)); e != nil {
+				return fmt.Errorf("invalid value '%s' type '%v' namespace '%s': %w", key, v.Type(), string(namespace), e)
+			}
+
+			return nil
+		}
+	}
+
 	switch kind {
 	case reflect.Interface:
 		// If interface would have been set on the struct before decoding,
@@ -779,98 +2395,105 @@ func (d *decoder[DecodeFuncArgument]) getMapKey(key string, current reflect.Valu
 		}
 
 	case reflect.String:
-		v.SetString(key)
+		s, e := d.checkUTF8(key)
+		if e != nil {
+			err = e
+
+			return
+		}
+
+		v.SetString(s)
 
 	case reflect.Uint, reflect.Uint64:
-		u64, e := strconv.ParseUint(key, 10, 64)
+		u64, e := d.parseUint(key, 64)
 		if e != nil {
-			return fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetUint(u64)
 
 	case reflect.Uint8:
-		u64, e := strconv.ParseUint(key, 10, 8)
+		u64, e := d.parseUint(key, 8)
 		if e != nil {
-			return fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetUint(u64)
 
 	case reflect.Uint16:
-		u64, e := strconv.ParseUint(key, 10, 16)
+		u64, e := d.parseUint(key, 16)
 		if e != nil {
-			return fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetUint(u64)
 
 	case reflect.Uint32:
-		u64, e := strconv.ParseUint(key, 10, 32)
+		u64, e := d.parseUint(key, 32)
 		if e != nil {
-			return fmt.Errorf("invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidUint, "invalid unsigned integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetUint(u64)
 
 	case reflect.Int, reflect.Int64:
-		i64, e := strconv.ParseInt(key, 10, 64)
+		i64, e := d.parseInt(key, 64)
 		if e != nil {
-			return fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetInt(i64)
 
 	case reflect.Int8:
-		i64, e := strconv.ParseInt(key, 10, 8)
+		i64, e := d.parseInt(key, 8)
 		if e != nil {
-			return fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetInt(i64)
 
 	case reflect.Int16:
-		i64, e := strconv.ParseInt(key, 10, 16)
+		i64, e := d.parseInt(key, 16)
 		if e != nil {
-			return fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetInt(i64)
 
 	case reflect.Int32:
-		i64, e := strconv.ParseInt(key, 10, 32)
+		i64, e := d.parseInt(key, 32)
 		if e != nil {
-			return fmt.Errorf("invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidInt, "invalid integer value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetInt(i64)
 
 	case reflect.Float32:
-		f, e := strconv.ParseFloat(key, 32)
+		f, e := d.parseFloat(key, 32)
 		if e != nil {
-			return fmt.Errorf("invalid float value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidFloat, "invalid float value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetFloat(f)
 
 	case reflect.Float64:
-		f, e := strconv.ParseFloat(key, 64)
+		f, e := d.parseFloat(key, 64)
 		if e != nil {
-			return fmt.Errorf("invalid float value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidFloat, "invalid float value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetFloat(f)
 
 	case reflect.Bool:
-		b, e := parseBool(key)
+		b, e := d.parseBool(key)
 		if e != nil {
-			return fmt.Errorf("invalid boolean value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+			return newError(ErrInvalidBool, "invalid boolean value '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 		}
 
 		v.SetBool(b)
 
 	default:
-		return fmt.Errorf("unsupported map key '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
+		return newError(ErrUnsupportedType, "unsupported map key '%s' type '%v' namespace '%s'", key, v.Type(), string(namespace))
 	}
 
 	return nil
@@ -36,6 +36,42 @@ func TestDecoder_Decode_deep_embed(t *testing.T) {
 	assert.Equal(t, map[string]interface{}{"header": "foo"}, collect)
 }
 
+func TestDecoder_Decode_unexported_embed(t *testing.T) {
+	type S struct {
+		internal.DeeperEmbedded
+
+		Header string `form:"header"`
+	}
+
+	dec := form.NewDecoder[any]()
+
+	dec.SetMode(form.ModeExplicit)
+
+	s := S{}
+	s.SetDeeplyEmbedded("")
+
+	vals := url.Values{"deeply-embedded": []string{"baz"}, "header": []string{"foo"}}
+
+	require.NoError(t, dec.Decode(&s, vals, nil))
+	assert.Equal(t, "foo", s.Header)
+	assert.Equal(t, "baz", s.DeeplyEmbedded)
+
+	// nil pointers to unexported structs cannot be allocated and are skipped
+	s = S{}
+
+	require.NoError(t, dec.Decode(&s, vals, nil))
+	assert.Equal(t, "foo", s.Header)
+
+	plan := form.PlanFor[S](dec)
+
+	require.NoError(t, plan.Set(&s, "deeply-embedded", []string{"qux"}, nil))
+
+	s.SetDeeplyEmbedded("")
+
+	require.NoError(t, plan.Set(&s, "deeply-embedded", []string{"qux"}, nil))
+	assert.Equal(t, "qux", s.DeeplyEmbedded)
+}
+
 func TestDecoder_Decode_nested(t *testing.T) {
 	type S struct {
 		Foo    string `form:"foo"`
@@ -2,10 +2,14 @@ package form
 
 import (
 	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/netip"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -1059,7 +1063,7 @@ func TestDecoderErrors(t *testing.T) {
 			NotEqual(t, e, "")
 
 			k = err["BadMapKey"]
-			Equal(t, k.Error(), "unsupported map key 'badtime' type 'time.Time' namespace 'BadMapKey'")
+			Equal(t, k.Error(), "invalid time value 'badtime' type 'time.Time' namespace 'BadMapKey': parsing time \"badtime\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"badtime\" as \"2006\"")
 		})
 	}
 }
@@ -1822,7 +1826,13 @@ func TestDecodeArrayBug(t *testing.T) {
 		"G[2]": {"20"},
 	}, nil)
 	NotEqual(t, err, nil)
-	Equal(t, err.Error(), "Field Namespace:C ERROR:invalid array index 'q'")
+
+	errs := err.(DecodeErrors)
+	Equal(t, len(errs), 3)
+	Equal(t, errs["C"].Error(), "invalid array index 'q'")
+	Equal(t, errs["B"].Error(), "3 values exceed array length 2")
+	Equal(t, errs["B[2]"].Error(), "array index '2' out of bounds for length 2")
+	True(t, errors.Is(errs["B[2]"], ErrArrayIndexOutOfBounds))
 	Equal(t, data.A[0], "10")
 	Equal(t, data.A[1], "20")
 	Equal(t, data.B[0], "10")
@@ -2102,3 +2112,2387 @@ func TestDecoder_DecodeFuncArgument(t *testing.T) {
 	Equal(t, arguments["10"], v.Data)
 
 }
+
+func TestDecoder_Check(t *testing.T) {
+	t.Parallel()
+
+	type Request struct {
+		ID   int      `form:"id"`
+		Tags []string `form:"tags"`
+	}
+
+	d := NewDecoder[any]()
+
+	err := d.Check(Request{}, url.Values{"id": {"10"}, "tags": {"a", "b"}}, nil)
+	NoError(t, err)
+
+	err = d.Check(new(Request), url.Values{"id": {"abc"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:id ERROR:invalid integer value 'abc' type 'int' namespace 'id'", err.Error())
+
+	err = d.Check(reflect.TypeOf(Request{}), url.Values{"id": {"abc"}}, nil)
+	NotNil(t, err)
+
+	err = d.Check(nil, url.Values{}, nil)
+	Equal(t, "form: Decode(nil)", err.Error())
+}
+
+func TestDecoder_RegisterFuncByTag(t *testing.T) {
+	t.Parallel()
+
+	type Request struct {
+		IDs    []int  `form:"ids,csv"`
+		Ptr    *[]int `form:"ptr,csv"`
+		Others []int  `form:"others"`
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFuncByTag("csv", func(s string, _ any) (interface{}, error) {
+		var res []int
+
+		for _, p := range strings.Split(s, ",") {
+			i, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, i)
+		}
+
+		return res, nil
+	})
+
+	var r Request
+
+	err := d.Decode(&r, url.Values{"ids": {"1,2,3"}, "ptr": {"4,5"}, "others": {"6", "7"}}, nil)
+	NoError(t, err)
+	Equal(t, []int{1, 2, 3}, r.IDs)
+	Equal(t, []int{4, 5}, *r.Ptr)
+	Equal(t, []int{6, 7}, r.Others)
+
+	err = d.Decode(&r, url.Values{"ids": {"1,a"}}, nil)
+	NotNil(t, err)
+	Contains(t, err.Error(), "Field Namespace:ids ERROR:")
+}
+
+func TestDecoder_RegisterFuncByTagResultTypes(t *testing.T) {
+	t.Parallel()
+
+	type IDs []int
+
+	type Request struct {
+		Nil   []int `form:"nil,nil"`
+		Wrong []int `form:"wrong,wrong"`
+		Ptr   *int  `form:"ptr,num"`
+		Named IDs   `form:"named,ids"`
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFuncByTag("nil", func(s string, _ any) (interface{}, error) {
+		return nil, nil
+	})
+	d.RegisterFuncByTag("wrong", func(s string, _ any) (interface{}, error) {
+		return "not a slice", nil
+	})
+	d.RegisterFuncByTag("num", func(s string, _ any) (interface{}, error) {
+		return strconv.Atoi(s)
+	})
+	d.RegisterFuncByTag("ids", func(s string, _ any) (interface{}, error) {
+		return []int{len(s)}, nil
+	})
+
+	r := Request{Nil: []int{1}, Wrong: []int{2}}
+
+	err := d.Decode(&r, url.Values{"nil": {"x"}, "wrong": {"y"}, "ptr": {"5"}, "named": {"abc"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 2, len(errs))
+	True(t, errors.Is(errs["nil"], ErrUnsupportedType))
+	True(t, errors.Is(errs["wrong"], ErrUnsupportedType))
+	Equal(t, []int{1}, r.Nil)
+	Equal(t, []int{2}, r.Wrong)
+	NotNil(t, r.Ptr)
+	Equal(t, 5, *r.Ptr)
+	Equal(t, IDs{3}, r.Named)
+}
+
+func TestDecoder_Decode_textUnmarshalElements(t *testing.T) {
+	t.Parallel()
+
+	var data struct {
+		List  []textMarshaler          `form:"list"`
+		Ptr   *textMarshaler           `form:"ptr"`
+		Addrs []netip.Addr             `form:"addrs"`
+		Map   map[string]textMarshaler `form:"map"`
+		Empty textMarshaler            `form:"empty"`
+	}
+
+	decoder := NewDecoder[any]()
+	err := decoder.Decode(&data, url.Values{
+		"list":   {"a", "b"},
+		"ptr":    {"p"},
+		"addrs":  {"1.2.3.4", "::1"},
+		"map[k]": {"v"},
+		"empty":  {},
+	}, nil)
+
+	NoError(t, err)
+	Equal(t, []textMarshaler{"unmarshaled:a", "unmarshaled:b"}, data.List)
+	Equal(t, textMarshaler("unmarshaled:p"), *data.Ptr)
+	Equal(t, []netip.Addr{netip.MustParseAddr("1.2.3.4"), netip.MustParseAddr("::1")}, data.Addrs)
+	Equal(t, map[string]textMarshaler{"k": "unmarshaled:v"}, data.Map)
+	Equal(t, textMarshaler(""), data.Empty)
+
+	err = decoder.Decode(&data, url.Values{"addrs": {"bad"}}, nil)
+	NotNil(t, err)
+}
+
+func TestDecoder_DecodeWithOptions(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string `json:"name"`
+	}
+
+	type Test struct {
+		Name  string `json:"name"`
+		Inner Inner  `json:"inner"`
+		Arr   []int  `json:"arr"`
+		Plain string
+	}
+
+	d := NewDecoder[any]()
+
+	opts := d.Options()
+	Equal(t, DecodeOptions{TagName: "form", Mode: ModeImplicit, MaxArraySize: 10000, NamespacePrefix: "."}, opts)
+
+	opts.TagName = "json"
+	opts.Mode = ModeExplicit
+	opts.MaxArraySize = 2
+	opts.NamespacePrefix = "["
+	opts.NamespaceSuffix = "]"
+
+	values := url.Values{
+		"name":        {"n"},
+		"inner[name]": {"i"},
+		"Plain":       {"p"},
+	}
+
+	var v Test
+	err := d.DecodeWithOptions(&v, values, nil, opts)
+	NoError(t, err)
+	Equal(t, Test{Name: "n", Inner: Inner{Name: "i"}}, v)
+
+	err = d.DecodeWithOptions(&v, url.Values{"arr[5]": {"1"}}, nil, opts)
+	NotNil(t, err)
+
+	// decoder settings are not affected by per-call options
+	v = Test{}
+	err = d.Decode(&v, url.Values{"Name": {"n"}, "Inner.Name": {"i"}, "Plain": {"p"}, "arr[5]": {"1"}}, nil)
+	NoError(t, err)
+	Equal(t, Test{Name: "n", Inner: Inner{Name: "i"}, Plain: "p"}, v)
+	Equal(t, ModeImplicit, d.Options().Mode)
+
+	// settings a partial literal leaves empty are taken from the decoder
+	v = Test{}
+	err = d.DecodeWithOptions(&v, url.Values{"Name": {"n"}, "Inner.Name": {"i"}, "Arr[1]": {"2"}}, nil, DecodeOptions{Mode: ModeImplicit})
+	NoError(t, err)
+	Equal(t, Test{Name: "n", Inner: Inner{Name: "i"}, Arr: []int{0, 2}}, v)
+}
+
+func TestDecoder_SetTimeout(t *testing.T) {
+	t.Parallel()
+
+	values := url.Values{}
+	for i := 0; i < 1000; i++ {
+		values.Add("Values", strconv.Itoa(i))
+		values.Add("Indexed["+strconv.Itoa(i)+"]", strconv.Itoa(i))
+	}
+
+	type Test struct {
+		Values  []int
+		Indexed []int
+	}
+
+	d := NewDecoder[any]()
+	d.SetTimeout(time.Nanosecond)
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	True(t, errors.Is(err, ErrDecodeTimeout))
+
+	// errors of fields decoded before the deadline are returned with the timeout
+	type Invalid struct {
+		Count int
+		Test
+	}
+
+	values.Set("Count", "x")
+
+	var invalid Invalid
+	err = d.Decode(&invalid, values, nil)
+
+	var te *TimeoutError
+	True(t, errors.As(err, &te))
+	True(t, errors.Is(err, ErrDecodeTimeout))
+	True(t, errors.Is(err, ErrInvalidInt))
+	Len(t, te.Errs, 1)
+	Contains(t, err.Error(), "Field Namespace:Count")
+
+	opts := d.Options()
+	opts.Timeout = 0
+
+	v = Test{}
+	err = d.DecodeWithOptions(&v, values, nil, opts)
+	NoError(t, err)
+	Len(t, v.Values, 1000)
+	Len(t, v.Indexed, 1000)
+
+	// pooled decoder state is reset after timeout
+	d.SetTimeout(time.Minute)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NoError(t, err)
+	Len(t, v.Values, 1000)
+}
+
+func TestDecoder_RegisterFunc_concurrent(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Value int
+		Time  time.Time
+	}
+
+	d := NewDecoder[any]()
+	values := url.Values{"Value": {"1"}, "Time": {"2006-01-02"}}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			d.RegisterFunc(func(s string, _ any) (interface{}, error) {
+				return time.Parse("2006-01-02", s)
+			}, reflect.TypeOf(time.Time{}))
+			d.RegisterFuncByTag("opt"+strconv.Itoa(i), func(s string, _ any) (interface{}, error) {
+				return s, nil
+			})
+			d.RegisterTagNameFunc(func(field reflect.StructField) string {
+				return field.Name
+			})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		var v Test
+		_ = d.Decode(&v, values, nil)
+		Equal(t, 1, v.Value)
+	}
+
+	<-done
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), v.Time)
+}
+
+func TestDecoder_Clone(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name  string `json:"name"`
+		Value int
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFunc(func(s string, _ any) (interface{}, error) {
+		return len(s), nil
+	}, reflect.TypeOf(0))
+
+	c := d.Clone()
+	c.SetTagName("json")
+	c.SetMode(ModeExplicit)
+	c.RegisterFuncByTag("opt", func(s string, _ any) (interface{}, error) {
+		return s, nil
+	})
+
+	Equal(t, d.structCache.Load(), c.structCache.Load())
+	Nil(t, d.funcs.Load().customTagFuncs)
+	NotNil(t, c.funcs.Load().customTagFuncs)
+
+	values := url.Values{"name": {"n"}, "Name": {"N"}, "Value": {"abc"}}
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, Test{Name: "N", Value: 3}, v)
+
+	v = Test{}
+	err = c.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, Test{Name: "n"}, v)
+
+	c.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Name
+	})
+	NotEqual(t, d.structCache.Load(), c.structCache.Load())
+	Nil(t, d.structCache.Load().tagFn)
+}
+
+func TestDecoder_SetUTF8Mode(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name  string
+		Names []string
+		Map   map[string]string
+		Int   int
+	}
+
+	values := url.Values{
+		"Name":       {"a\xffb"},
+		"Names":      {"ok", "c\xfe"},
+		"Map[k\xff]": {"v"},
+		"Int":        {"1"},
+	}
+
+	d := NewDecoder[any]()
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, "a\xffb", v.Name)
+
+	d.SetUTF8Mode(UTF8Replace)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, "a�b", v.Name)
+	Equal(t, []string{"ok", "c�"}, v.Names)
+	Equal(t, map[string]string{"k�": "v"}, v.Map)
+
+	d.SetUTF8Mode(UTF8Reject)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NotNil(t, err)
+
+	errs, ok := err.(DecodeErrors)
+	True(t, ok)
+	True(t, errors.Is(errs["Name"], ErrInvalidUTF8))
+	True(t, errors.Is(errs["Names"], ErrInvalidUTF8))
+	True(t, errors.Is(errs["Map"], ErrInvalidUTF8))
+	Equal(t, "", v.Name)
+	Equal(t, []string{"ok", ""}, v.Names)
+	Equal(t, 1, v.Int)
+}
+
+func TestDecoder_Precompute(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string `json:"name"`
+	}
+
+	type Test struct {
+		Inner []Inner `json:"inner"`
+	}
+
+	d := NewDecoder[any]()
+	d.SetTagName("json")
+	d.Precompute(reflect.TypeOf(Test{}))
+
+	_, ok := d.structCache.Load().Get(ModeImplicit, reflect.TypeOf(Inner{}), "json")
+	True(t, ok)
+
+	var v Test
+	err := d.Decode(&v, url.Values{"inner[0].name": {"n"}}, nil)
+	NoError(t, err)
+	Equal(t, []Inner{{Name: "n"}}, v.Inner)
+}
+
+func TestDecoder_Decode_maxLen(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Bio  string   `form:"bio,maxlen=4"`
+		Tags []string `form:"tags,maxlen=2"`
+		Name string   `form:"name"`
+	}
+
+	d := NewDecoder[any]()
+
+	var v Test
+	err := d.Decode(&v, url.Values{"bio": {"abcd"}, "tags": {"a", "bc"}, "name": {"n"}}, nil)
+	NoError(t, err)
+	Equal(t, Test{Bio: "abcd", Tags: []string{"a", "bc"}, Name: "n"}, v)
+
+	v = Test{}
+	err = d.Decode(&v, url.Values{"bio": {"abcde"}, "tags": {"a", "bcd"}, "name": {"n"}}, nil)
+	NotNil(t, err)
+	Equal(t, Test{Name: "n"}, v)
+
+	errs, ok := err.(DecodeErrors)
+	True(t, ok)
+
+	var tooLong *TooLongError
+
+	True(t, errors.As(errs["bio"], &tooLong))
+	Equal(t, &TooLongError{MaxLen: 4, Len: 5}, tooLong)
+	Equal(t, "value length 5 exceeds maximum of 4", tooLong.Error())
+
+	True(t, errors.As(errs["tags"], &tooLong))
+	Equal(t, &TooLongError{MaxLen: 2, Len: 3}, tooLong)
+}
+
+func TestDecoder_Decode_invalidMaxLen(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Text     string `form:"text,maxlen=abc"`
+		Negative string `form:"negative,maxlen=-1"`
+		Zero     string `form:"zero,maxlen=0"`
+		Missing  string `form:"missing,maxlen=x"`
+	}
+
+	var v Test
+
+	err := NewDecoder[any]().Decode(&v, url.Values{"text": {"a"}, "negative": {"b"}, "zero": {"c"}}, nil)
+	NotNil(t, err)
+	Equal(t, Test{}, v)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 3, len(errs))
+	True(t, errors.Is(errs["text"], ErrInvalidTagOption))
+	True(t, errors.Is(errs["negative"], ErrInvalidTagOption))
+	True(t, errors.Is(errs["zero"], ErrInvalidTagOption))
+	Contains(t, errs["text"].Error(), "invalid tag option 'maxlen=abc' namespace 'text'")
+}
+
+func TestDecoder_Decode_mapKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Ints   map[int]string                `form:"ints"`
+		Enums  map[textMarshaler]int         `form:"enums"`
+		Addrs  map[netip.Addr]string         `form:"addrs"`
+		Times  map[time.Time]string          `form:"times"`
+		PtrKey map[*textMarshaler]string     `form:"ptr"`
+		Nested map[netip.Addr]map[int]string `form:"nested"`
+	}
+
+	d := NewDecoder[any]()
+
+	var v Test
+	err := d.Decode(&v, url.Values{
+		"ints[1]":                     {"a"},
+		"enums[x]":                    {"2"},
+		"addrs[10.0.0.1]":             {"b"},
+		"times[2006-01-02T15:04:05Z]": {"c"},
+		"ptr[p]":                      {"d"},
+		"nested[::1][3]":              {"e"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, map[int]string{1: "a"}, v.Ints)
+	Equal(t, map[textMarshaler]int{"unmarshaled:x": 2}, v.Enums)
+	Equal(t, map[netip.Addr]string{netip.MustParseAddr("10.0.0.1"): "b"}, v.Addrs)
+	Equal(t, map[time.Time]string{time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC): "c"}, v.Times)
+	Equal(t, map[netip.Addr]map[int]string{netip.MustParseAddr("::1"): {3: "e"}}, v.Nested)
+
+	Len(t, v.PtrKey, 1)
+
+	for k, val := range v.PtrKey {
+		Equal(t, textMarshaler("unmarshaled:p"), *k)
+		Equal(t, "d", val)
+	}
+
+	v = Test{}
+	err = d.Decode(&v, url.Values{"addrs[bad]": {"b"}}, nil)
+	NotNil(t, err)
+	Contains(t, err.Error(), "invalid value 'bad' type 'netip.Addr' namespace 'addrs'")
+}
+
+func TestDecoder_SetIndexBase(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string
+	}
+
+	type Test struct {
+		Items []Item
+		Arr   [2]int
+		Map   map[int]string
+	}
+
+	d := NewDecoder[any]()
+	d.SetIndexBase(1)
+
+	var v Test
+	err := d.Decode(&v, url.Values{
+		"Items[1].Name": {"a"},
+		"Items[2].Name": {"b"},
+		"Arr[2]":        {"2"},
+		"Map[0]":        {"m"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, Test{
+		Items: []Item{{Name: "a"}, {Name: "b"}},
+		Arr:   [2]int{0, 2},
+		Map:   map[int]string{0: "m"},
+	}, v)
+
+	v = Test{}
+	err = d.Decode(&v, url.Values{"Items[0].Name": {"a"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:Items ERROR:invalid slice index '0'", err.Error())
+}
+
+func TestDecoder_Decode_mapOfNestedStructs(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string
+		Zip  string
+	}
+
+	type Account struct {
+		Name    string
+		Address Address
+		Backup  *Address
+		Labels  map[string]Address
+	}
+
+	type Test struct {
+		Accounts map[string]Account
+		Ptrs     map[string]*Account
+		Nested   map[string]map[int]Account
+	}
+
+	values := url.Values{
+		"Accounts[alice].Name":              {"Alice"},
+		"Accounts[alice].Address.City":      {"Paris"},
+		"Accounts[alice].Backup.Zip":        {"75001"},
+		"Accounts[alice].Labels[home].City": {"Lyon"},
+		"Accounts[bob].Address.City":        {"Rome"},
+		"Ptrs[carol].Address.City":          {"Oslo"},
+		"Nested[x][1].Address.Zip":          {"1"},
+	}
+
+	d := NewDecoder[any]()
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, map[string]Account{
+		"alice": {
+			Name:    "Alice",
+			Address: Address{City: "Paris"},
+			Backup:  &Address{Zip: "75001"},
+			Labels:  map[string]Address{"home": {City: "Lyon"}},
+		},
+		"bob": {Address: Address{City: "Rome"}},
+	}, v.Accounts)
+	Equal(t, map[string]*Account{"carol": {Address: Address{City: "Oslo"}}}, v.Ptrs)
+	Equal(t, map[string]map[int]Account{"x": {1: {Address: Address{Zip: "1"}}}}, v.Nested)
+
+	// existing entries are merged
+	v = Test{Accounts: map[string]Account{
+		"alice": {Name: "Alice", Address: Address{Zip: "75001"}},
+		"dave":  {Name: "Dave"},
+	}}
+
+	err = d.Decode(&v, url.Values{"Accounts[alice].Address.City": {"Paris"}}, nil)
+	NoError(t, err)
+	Equal(t, map[string]Account{
+		"alice": {Name: "Alice", Address: Address{City: "Paris", Zip: "75001"}},
+		"dave":  {Name: "Dave"},
+	}, v.Accounts)
+}
+
+func TestDecoder_SetMetricsRecorder(t *testing.T) {
+	t.Parallel()
+
+	type Request struct {
+		ID int
+	}
+
+	var events []DecodeEvent
+
+	d := NewDecoder[any]()
+	d.SetMetricsRecorder(func(e DecodeEvent) {
+		events = append(events, e)
+	})
+
+	var v Request
+	err := d.Decode(&v, url.Values{"ID": {"1"}}, nil)
+	NoError(t, err)
+
+	err = d.Decode(&v, url.Values{"ID": {"a"}}, nil)
+	NotNil(t, err)
+
+	var n int
+	err = d.Decode(&n, url.Values{"": {"1"}}, nil)
+	NoError(t, err)
+
+	err = d.Decode(v, url.Values{}, nil)
+	NotNil(t, err)
+
+	Len(t, events, 3)
+	Equal(t, "form.Request", events[0].Type)
+	Nil(t, events[0].Err)
+	Equal(t, "form.Request", events[1].Type)
+	NotNil(t, events[1].Err)
+	Equal(t, "int", events[2].Type)
+
+	c := d.Clone()
+	err = c.Decode(&v, url.Values{}, nil)
+	NoError(t, err)
+	Len(t, events, 4)
+}
+
+func TestDecoder_SetBracketStyle(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Tags  []string          `form:"tags"`
+		IDs   []int             `form:"ids"`
+		Attrs map[string]string `form:"attrs"`
+		Name  string            `form:"name"`
+	}
+
+	type Test struct {
+		Item
+		Nested Item `form:"nested"`
+	}
+
+	values := url.Values{
+		"tags[]":        {"a", "b"},
+		"ids":           {"1"},
+		"ids[]":         {"2"},
+		"attrs[color]":  {"red"},
+		"attrs[size]":   {"L"},
+		"name":          {"n"},
+		"nested.tags[]": {"c"},
+	}
+
+	d := NewDecoder[any]()
+	d.SetBracketStyle(StylePHP)
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, []string{"a", "b"}, v.Tags)
+	Equal(t, []int{1, 2}, v.IDs)
+	Equal(t, map[string]string{"color": "red", "size": "L"}, v.Attrs)
+	Equal(t, "n", v.Name)
+	Equal(t, []string{"c"}, v.Nested.Tags)
+
+	// caller's values are not modified
+	Equal(t, []string{"a", "b"}, values["tags[]"])
+	Equal(t, []string{"1"}, values["ids"])
+
+	// values already copied by rewriting dotted indexes are merged the same way
+	d.SetArraySyntax(ArrayDots)
+
+	for i := 0; i < 10; i++ {
+		v = Test{}
+		err = d.Decode(&v, url.Values{"tags": {"x"}, "tags[]": {"a"}, "ids[]": {"1", "2"}, "nested.ids.0": {"5"}}, nil)
+		NoError(t, err)
+		Equal(t, Test{Item: Item{Tags: []string{"x", "a"}, IDs: []int{1, 2}}, Nested: Item{IDs: []int{5}}}, v)
+	}
+
+	d.SetArraySyntax(ArrayBrackets)
+	d.SetBracketStyle(StyleDefault)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NotNil(t, err)
+}
+
+func TestDecoder_SetArraySyntax(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string `form:"name"`
+		Tags []string
+	}
+
+	type Test struct {
+		Items  []Item    `form:"items"`
+		Matrix [][]int   `form:"matrix"`
+		Arr    [2]string `form:"arr"`
+		Map    map[string]int
+	}
+
+	d := NewDecoder[any]()
+	d.SetArraySyntax(ArrayDots)
+
+	var v Test
+	err := d.Decode(&v, url.Values{
+		"items.0.name":   {"a"},
+		"items[1].name":  {"b"},
+		"items.1.Tags.0": {"t"},
+		"matrix.0.1":     {"1"},
+		"arr.1":          {"x"},
+		"Map[k.1]":       {"2"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, Test{
+		Items:  []Item{{Name: "a"}, {Name: "b", Tags: []string{"t"}}},
+		Matrix: [][]int{{0, 1}},
+		Arr:    [2]string{"", "x"},
+		Map:    map[string]int{"k.1": 2},
+	}, v)
+}
+
+func TestDecoder_SetNumberParsers(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Int   int
+		Int8  int8
+		Uint  uint16
+		Float float64
+		Map   map[int]float32
+	}
+
+	d := NewDecoder[any]()
+	d.SetIntParser(func(s string, bitSize int) (int64, error) {
+		return strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, bitSize)
+	})
+	d.SetUintParser(func(s string, bitSize int) (uint64, error) {
+		return strconv.ParseUint(s, 16, bitSize)
+	})
+	d.SetFloatParser(func(s string, bitSize int) (float64, error) {
+		return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), bitSize)
+	})
+
+	values := url.Values{
+		"Int":      {"1_000"},
+		"Int8":     {"1_2"},
+		"Uint":     {"ff"},
+		"Float":    {"1,5"},
+		"Map[1_0]": {"2,5"},
+	}
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, Test{Int: 1000, Int8: 12, Uint: 255, Float: 1.5, Map: map[int]float32{10: 2.5}}, v)
+
+	v = Test{}
+	err = d.Decode(&v, url.Values{"Int8": {"1_000"}}, nil)
+	NotNil(t, err)
+
+	d.SetIntParser(nil)
+
+	v = Test{}
+	err = d.Decode(&v, url.Values{"Int": {"1_000"}, "Float": {"1,5"}}, nil)
+	NotNil(t, err)
+	Contains(t, err.Error(), "invalid integer value '1_000'")
+	NotContains(t, err.Error(), "Float")
+}
+
+func TestDecoder_SetSparseMode(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string
+		Age  int
+	}
+
+	type Test struct {
+		Strings []string
+		Items   []Item
+		Dense   []int
+	}
+
+	values := url.Values{
+		"Strings[0]":    {"x"},
+		"Strings[5]":    {"y"},
+		"Items[7].Name": {"b"},
+		"Items[7].Age":  {"2"},
+		"Items[3].Name": {"a"},
+		"Dense[0]":      {"1"},
+		"Dense[1]":      {"2"},
+	}
+
+	d := NewDecoder[any]()
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+	Len(t, v.Strings, 6)
+	Len(t, v.Items, 8)
+
+	d.SetSparseMode(SparseCompact)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NoError(t, err)
+	Equal(t, Test{
+		Strings: []string{"x", "y"},
+		Items:   []Item{{Name: "a"}, {Name: "b", Age: 2}},
+		Dense:   []int{1, 2},
+	}, v)
+
+	d.SetSparseMode(SparseError)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NotNil(t, err)
+
+	errs, ok := err.(DecodeErrors)
+	True(t, ok)
+	Len(t, errs, 2)
+	Equal(t, "sparse slice indexes, 2 values for length 6", errs["Strings"].Error())
+	Equal(t, "sparse slice indexes, 2 values for length 8", errs["Items"].Error())
+	Equal(t, []int{1, 2}, v.Dense)
+}
+
+func TestDecoder_SetMaxKeys(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		A, B, C string
+		List    []int
+	}
+
+	d := NewDecoder[any]()
+	d.SetMaxKeys(3)
+	d.SetMaxValuesPerKey(2)
+
+	var v Test
+	err := d.Decode(&v, url.Values{"A": {"a"}, "B": {"b"}, "List": {"1", "2"}}, nil)
+	NoError(t, err)
+	Equal(t, Test{A: "a", B: "b", List: []int{1, 2}}, v)
+
+	v = Test{}
+	err = d.Decode(&v, url.Values{"A": {"a"}, "B": {"b"}, "C": {"c"}, "D": {"d"}}, nil)
+
+	var limitErr *LimitError
+
+	True(t, errors.As(err, &limitErr))
+	Equal(t, &LimitError{Max: 3, Count: 4}, limitErr)
+	Equal(t, "form: number of keys 4 exceeds maximum of 3", err.Error())
+	Equal(t, Test{}, v)
+
+	err = d.Decode(&v, url.Values{"A": {"a"}, "List": {"1", "2", "3"}}, nil)
+	True(t, errors.As(err, &limitErr))
+	Equal(t, &LimitError{Key: "List", Max: 2, Count: 3}, limitErr)
+	Equal(t, "form: number of values 3 of key 'List' exceeds maximum of 2", err.Error())
+	Equal(t, Test{}, v)
+}
+
+func TestDecoder_SetReaderMode(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Body    io.Reader
+		Text    *strings.Reader
+		Parts   []io.Reader
+		Missing io.Reader
+	}
+
+	values := url.Values{"Body": {"large body"}, "Text": {"text"}, "Parts": {"a", "b"}}
+
+	d := NewDecoder[any]()
+	d.SetReaderMode(ReaderLazy)
+
+	var v Test
+	err := d.Decode(&v, values, nil)
+	NoError(t, err)
+
+	b, err := io.ReadAll(v.Body)
+	NoError(t, err)
+	Equal(t, "large body", string(b))
+
+	b, err = io.ReadAll(v.Text)
+	NoError(t, err)
+	Equal(t, "text", string(b))
+
+	Len(t, v.Parts, 2)
+	b, err = io.ReadAll(v.Parts[1])
+	NoError(t, err)
+	Equal(t, "b", string(b))
+	Nil(t, v.Missing)
+
+	d.SetReaderMode(ReaderOff)
+
+	v = Test{}
+	err = d.Decode(&v, values, nil)
+	NoError(t, err)
+	Nil(t, v.Body)
+	Nil(t, v.Text)
+}
+
+func TestDecoder_SetMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name     string
+		Children []Node
+	}
+
+	d := NewDecoder[any]()
+	d.SetMaxDepth(3)
+
+	var v Node
+	err := d.Decode(&v, url.Values{"Name": {"root"}, "Children[0].Name": {"a"}, "Children[0].Children[1]": {}}, nil)
+	NoError(t, err)
+	Equal(t, "a", v.Children[0].Name)
+
+	v = Node{}
+	err = d.Decode(&v, url.Values{"Name": {"root"}, "Children[0].Children[0].Name": {"b"}}, nil)
+
+	var depthErr *DepthError
+
+	True(t, errors.As(err, &depthErr))
+	Equal(t, &DepthError{Key: "Children[0].Children[0].Name", Max: 3, Depth: 4}, depthErr)
+	Equal(t, "form: nesting depth 4 of key 'Children[0].Children[0].Name' exceeds maximum of 3", err.Error())
+	Equal(t, Node{}, v)
+}
+
+func TestDecoderFieldError(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Qty uint
+	}
+
+	type Order struct {
+		Items []Item
+		Tags  []int
+		When  time.Time
+	}
+
+	d := NewDecoder[any]()
+
+	var v Order
+	err := d.Decode(&v, url.Values{"Items[1].Qty": {"-3"}, "Tags[2]": {"x"}, "When": {"yesterday"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 3, len(errs))
+
+	var fe *FieldError
+
+	True(t, errors.As(errs["Items[1].Qty"], &fe))
+	Equal(t, "Items[1].Qty", fe.Namespace())
+	Equal(t, "Qty", fe.Field())
+	Equal(t, reflect.Uint, fe.Kind())
+	Equal(t, "-3", fe.RawValue())
+	Equal(t, "invalid unsigned integer value '-3' type 'uint' namespace 'Items[1].Qty'", fe.Error())
+	NotNil(t, fe.Unwrap())
+
+	True(t, errors.As(errs["Tags[2]"], &fe))
+	Equal(t, "Tags", fe.Field())
+	Equal(t, reflect.Int, fe.Kind())
+	Equal(t, "x", fe.RawValue())
+
+	True(t, errors.As(errs["When"], &fe))
+	Equal(t, "When", fe.Field())
+	Equal(t, reflect.Struct, fe.Kind())
+	Equal(t, "yesterday", fe.RawValue())
+
+	v = Order{}
+	err = d.Decode(&v, url.Values{"Tags[a]": {"1"}}, nil)
+	NotNil(t, err)
+	True(t, errors.As(err.(DecodeErrors)["Tags"], &fe))
+	Equal(t, "Tags", fe.Field())
+	Equal(t, reflect.Invalid, fe.Kind())
+	Equal(t, "", fe.RawValue())
+
+	d.SetUTF8Mode(UTF8Reject)
+
+	var s struct{ Name string }
+	err = d.Decode(&s, url.Values{"Name": {"a\xffb"}}, nil)
+	True(t, errors.As(err.(DecodeErrors)["Name"], &fe))
+	True(t, errors.Is(fe, ErrInvalidUTF8))
+	Equal(t, "a\xffb", fe.RawValue())
+}
+
+func TestDecoder_DecodeWithProvenance(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string
+	}
+
+	type Request struct {
+		ID    int
+		Page  int
+		Sort  string
+		Tags  []string
+		Items []Item
+		Age   uint
+	}
+
+	d := NewDecoder[any]()
+	d.SetArraySyntax(ArrayDots)
+	d.SetBracketStyle(StylePHP)
+
+	provenance := make(map[string]Provenance)
+
+	var v Request
+	err := d.DecodeWithProvenance(&v, url.Values{"ID": {"7"}, "Sort": {"name"}, "Age": {"30"}}, nil, "path", provenance)
+	NoError(t, err)
+
+	err = d.DecodeWithProvenance(&v, url.Values{
+		"Page":         {"2"},
+		"Sort":         {"date"},
+		"Tags[]":       {"a", "b"},
+		"Items.0.Name": {"first"},
+		"Age":          {"x"},
+	}, nil, "query", provenance)
+	NotNil(t, err)
+
+	Equal(t, Request{ID: 7, Page: 2, Sort: "date", Tags: []string{"a", "b"}, Items: []Item{{Name: "first"}}, Age: 30}, v)
+	Equal(t, map[string]Provenance{
+		"ID":            {Source: "path", Key: "ID"},
+		"Page":          {Source: "query", Key: "Page"},
+		"Sort":          {Source: "query", Key: "Sort"},
+		"Tags":          {Source: "query", Key: "Tags[]"},
+		"Items[0].Name": {Source: "query", Key: "Items.0.Name"},
+		"Age":           {Source: "path", Key: "Age"},
+	}, provenance)
+}
+
+func TestDecoderStream(t *testing.T) {
+	t.Parallel()
+
+	type Request struct {
+		IDs   func(id int64) bool      `form:"ids,stream"`
+		Names func(name string)        `form:"names,stream"`
+		Dates func(d *time.Time) error `form:"dates,stream"`
+		Bad   func(a, b int)           `form:"bad,stream"`
+		Unset func(id int) bool        `form:"unset,stream"`
+		Plain func(id int) bool        `form:"plain"`
+	}
+
+	var (
+		ids   []int64
+		names []string
+		dates []time.Time
+	)
+
+	req := Request{
+		IDs: func(id int64) bool {
+			ids = append(ids, id)
+
+			return id != 3
+		},
+		Names: func(name string) {
+			names = append(names, name)
+		},
+		Dates: func(d *time.Time) error {
+			if d.Year() < 2000 {
+				return errors.New("too old")
+			}
+
+			dates = append(dates, *d)
+
+			return nil
+		},
+	}
+
+	d := NewDecoder[any]()
+
+	err := d.Decode(&req, url.Values{
+		"ids":   {"1", "2", "3", "4"},
+		"names": {"a", "", "c"},
+		"dates": {"2020-01-02T00:00:00Z", "1999-01-01T00:00:00Z", "2021-01-01T00:00:00Z"},
+	}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:dates ERROR:too old", err.Error())
+	Equal(t, []int64{1, 2, 3}, ids)
+	Equal(t, []string{"a", "", "c"}, names)
+	Equal(t, []time.Time{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}, dates)
+
+	ids = nil
+
+	err = d.Decode(&req, url.Values{"ids": {"1", "x", "2"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:ids ERROR:invalid integer value 'x' type 'int64' namespace 'ids'", err.Error())
+	Equal(t, []int64{1}, ids)
+
+	req.Bad = func(a, b int) {}
+	err = d.Decode(&req, url.Values{"bad": {"1"}, "unset": {"1"}, "plain": {"1"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:bad ERROR:invalid stream function type 'func(int, int)'", err.Error())
+}
+
+func TestDecoderErrorsIs(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Int   int8
+		Uint  uint
+		Float float32
+		Bool  bool
+		Map   map[complex64]string
+		Arr   [2]string
+		Slice []string
+	}
+
+	d := NewDecoder[any]()
+	d.SetMaxArraySize(10)
+
+	var v Test
+	err := d.Decode(&v, url.Values{
+		"Int":         {"300"},
+		"Uint":        {"-1"},
+		"Float":       {"x"},
+		"Bool":        {"maybe"},
+		"Map[1]":      {"a"},
+		"Arr[x]":      {"a"},
+		"Slice[100]":  {"a"},
+		"Unrelated":   {"a"},
+		"Unrelated.X": {"a"},
+	}, nil)
+	NotNil(t, err)
+
+	for _, sentinel := range []error{
+		ErrInvalidInt, ErrInvalidUint, ErrInvalidFloat, ErrInvalidBool,
+		ErrUnsupportedType, ErrInvalidIndex, ErrArrayIndexOutOfBounds,
+	} {
+		True(t, errors.Is(err, sentinel), sentinel.Error())
+	}
+
+	False(t, errors.Is(err, ErrSparseSlice))
+
+	errs := err.(DecodeErrors)
+	Equal(t, len(errs), len(errs.Unwrap()))
+
+	var fe *FieldError
+
+	True(t, errors.As(err, &fe))
+	Equal(t, "Arr", fe.Namespace())
+
+	d.SetSparseMode(SparseError)
+
+	err = d.Decode(&v, url.Values{"Slice[0]": {"a"}, "Slice[2]": {"b"}}, nil)
+	True(t, errors.Is(err, ErrSparseSlice))
+
+	err = d.Decode(&v, url.Values{"Slice[0": {"a"}}, nil)
+	True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestDecoder_SetErrorFormatter(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Age   int
+		Admin bool
+	}
+
+	d := NewDecoder[any]()
+	d.SetErrorFormatter(func(e *FieldError) string {
+		if errors.Is(e, ErrInvalidInt) {
+			return e.Field() + " muss eine ganze Zahl sein, nicht '" + e.RawValue() + "'"
+		}
+
+		return e.Unwrap().Error()
+	})
+
+	var v Test
+	err := d.Decode(&v, url.Values{"Age": {"x"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:Age ERROR:Age muss eine ganze Zahl sein, nicht 'x'", err.Error())
+
+	err = d.Decode(&v, url.Values{"Admin": {"x"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:Admin ERROR:invalid boolean value 'x' type 'bool' namespace 'Admin'", err.Error())
+
+	d.SetErrorFormatter(nil)
+
+	err = d.Decode(&v, url.Values{"Age": {"x"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:Age ERROR:invalid integer value 'x' type 'int' namespace 'Age'", err.Error())
+}
+
+func TestDecoderBestEffort(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		A int
+		B string
+	}
+
+	type Test struct {
+		Name  string
+		Age   int
+		In    Inner
+		Ptr   *Inner
+		Ints  []int
+		Items []Inner
+		Map   map[string]int
+		Arr   [3]int
+	}
+
+	d := NewDecoder[any]()
+
+	var v Test
+	err := d.Decode(&v, url.Values{
+		"Name":       {"n"},
+		"Age":        {"x"},
+		"In.A":       {"x"},
+		"In.B":       {"b"},
+		"Ptr.A":      {"x"},
+		"Ptr.B":      {"pb"},
+		"Ints":       {"1", "x", "3"},
+		"Items[0].A": {"x"},
+		"Items[1].A": {"2"},
+		"Map[a]":     {"1"},
+		"Map[b]":     {"x"},
+		"Arr[0]":     {"1"},
+		"Arr[1]":     {"x"},
+	}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 7, len(errs))
+
+	for _, ns := range []string{"Age", "In.A", "Ptr.A", "Ints", "Items[0].A", "Map[b]", "Arr[1]"} {
+		NotNil(t, errs[ns], ns)
+	}
+
+	Equal(t, Test{
+		Name:  "n",
+		In:    Inner{B: "b"},
+		Ptr:   &Inner{B: "pb"},
+		Ints:  []int{1, 0, 3},
+		Items: []Inner{{}, {A: 2}},
+		Map:   map[string]int{"a": 1},
+		Arr:   [3]int{1},
+	}, v)
+}
+
+func TestDecoderRawField(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Payload  json.RawMessage `form:"payload,raw"`
+		Query    string          `form:"query,raw"`
+		Parts    []string        `form:"parts,raw"`
+		Optional *string         `form:"optional,raw"`
+		Number   int             `form:"number,raw"`
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFunc(func(s string, _ any) (interface{}, error) {
+		return "converted", nil
+	}, reflect.TypeOf(""))
+
+	var v Test
+	err := d.Decode(&v, url.Values{
+		"payload":  {`{"a":1}`},
+		"query":    {"a=1&b=2", "c=3"},
+		"parts":    {"x", "y"},
+		"optional": {"o"},
+		"number":   {"5"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, json.RawMessage(`{"a":1}`), v.Payload)
+	Equal(t, "a=1&b=2,c=3", v.Query)
+	Equal(t, []string{"x", "y"}, v.Parts)
+	Equal(t, "o", *v.Optional)
+	Equal(t, 5, v.Number)
+}
+
+func TestDecoderEmbeddedModes(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		ID int
+	}
+
+	type Meta struct {
+		Source string
+	}
+
+	type Address struct {
+		City string
+		Zip  string
+	}
+
+	type Shipping struct {
+		City string
+	}
+
+	type Order struct {
+		Base      `form:",promote"`
+		Meta      `form:",namespace"`
+		Address   `form:",prefix=billing_"`
+		*Shipping `form:",prefix=shipping_"`
+	}
+
+	d := NewDecoder[any]()
+
+	var o Order
+	err := d.Decode(&o, url.Values{
+		"ID":           {"1"},
+		"Base.ID":      {"2"},
+		"Source":       {"ignored"},
+		"Meta.Source":  {"web"},
+		"billing_City": {"Berlin"},
+		"City":         {"ignored"},
+		"Address.Zip":  {"ignored"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, Order{
+		Base:    Base{ID: 1},
+		Meta:    Meta{Source: "web"},
+		Address: Address{City: "Berlin"},
+	}, o)
+
+	o = Order{}
+	err = d.Decode(&o, url.Values{"shipping_City": {"Paris"}}, nil)
+	NoError(t, err)
+	NotNil(t, o.Shipping)
+	Equal(t, "Paris", o.Shipping.City)
+
+	plan := PlanFor[Order](d)
+
+	o = Order{}
+	NoError(t, plan.Set(&o, "billing_Zip", []string{"10115"}, nil))
+	NoError(t, plan.Set(&o, "Meta.Source", []string{"api"}, nil))
+	NoError(t, plan.Set(&o, "Source", []string{"ignored"}, nil))
+	Equal(t, "10115", o.Address.Zip)
+	Equal(t, "api", o.Meta.Source)
+}
+
+func TestDecoderFlatten(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		Street string `form:"street"`
+		City   string `form:"city"`
+	}
+
+	type Customer struct {
+		Name    string   `form:"name"`
+		Addr    Address  `form:"addr,flatten"`
+		Billing *Address `form:",prefix=billing_"`
+		Unset   *Address `form:",prefix=unset_"`
+		Nested  struct {
+			Addr Address `form:",prefix=n_"`
+		} `form:"nested"`
+	}
+
+	d := NewDecoder[any]()
+
+	var c Customer
+	err := d.Decode(&c, url.Values{
+		"name":             {"joe"},
+		"addr_street":      {"Main"},
+		"addr_city":        {"Berlin"},
+		"billing_city":     {"Paris"},
+		"addr.city":        {"ignored"},
+		"nested.n_city":    {"Rome"},
+		"nested.Addr.city": {"ignored"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, "joe", c.Name)
+	Equal(t, Address{Street: "Main", City: "Berlin"}, c.Addr)
+	Equal(t, &Address{City: "Paris"}, c.Billing)
+	Nil(t, c.Unset)
+	Equal(t, Address{City: "Rome"}, c.Nested.Addr)
+
+	c = Customer{}
+	NoError(t, PlanFor[Customer](d).Set(&c, "billing_street", []string{"High"}, nil))
+	Equal(t, &Address{Street: "High"}, c.Billing)
+}
+
+func TestDecoderFlattenRecursive(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name string `form:"name"`
+		Next *Node  `form:"next,flatten"`
+	}
+
+	d := NewDecoder[any]()
+
+	var n Node
+
+	NoError(t, d.Decode(&n, url.Values{"name": {"a"}, "next_name": {"b"}, "next_next_name": {"c"}}, nil))
+	Equal(t, Node{Name: "a", Next: &Node{Name: "b", Next: &Node{Name: "c"}}}, n)
+
+	n = Node{}
+	NoError(t, d.Decode(&n, url.Values{"name": {"a"}}, nil))
+	Equal(t, Node{Name: "a"}, n)
+
+	type Parent struct {
+		Node Node `form:"node"`
+	}
+
+	d.SetKeyStyle(StyleRails)
+
+	var p Parent
+
+	NoError(t, d.Decode(&p, url.Values{"node[name]": {"a"}, "node[next_name]": {"b"}}, nil))
+	Equal(t, Parent{Node: Node{Name: "a", Next: &Node{Name: "b"}}}, p)
+}
+
+func TestDecoderFieldAliases(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+	}
+
+	type Test struct {
+		UserID  int      `form:"user_id" formalias:"uid, userId"`
+		Name    string   `form:"name,alt=username,alt=login"`
+		Address *Address `form:"address,alt=addr"`
+	}
+
+	d := NewDecoder[any]()
+
+	var test Test
+	err := d.Decode(&test, url.Values{
+		"userId":    {"3"},
+		"login":     {"joe"},
+		"addr.city": {"Berlin"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, Test{UserID: 3, Name: "joe", Address: &Address{City: "Berlin"}}, test)
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"user_id": {"1"}, "uid": {"2"}, "username": {"bob"}, "login": {"joe"}}, nil)
+	NoError(t, err)
+	Equal(t, 1, test.UserID)
+	Equal(t, "bob", test.Name)
+
+	err = d.Decode(&test, url.Values{"uid": {"x"}}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:uid ERROR:invalid integer value 'x' type 'int' namespace 'uid'", err.Error())
+
+	test = Test{}
+	NoError(t, PlanFor[Test](d).Set(&test, "addr.city", []string{"Paris"}, nil))
+	Equal(t, &Address{City: "Paris"}, test.Address)
+
+	values, err := NewEncoder().Encode(Test{UserID: 1, Name: "joe"})
+	NoError(t, err)
+	Equal(t, url.Values{"user_id": {"1"}, "name": {"joe"}}, values)
+}
+
+func TestDecoderFormerlyNames(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string `form:"name,formerly=title"`
+	}
+
+	type Test struct {
+		ID    string `form:"user_id,formerly=uid"`
+		Inner Inner  `form:"inner,formerly=nested"`
+	}
+
+	d := NewDecoder[any]()
+
+	var test Test
+
+	NoError(t, d.Decode(&test, url.Values{"uid": {"1"}, "nested.title": {"n"}}, nil))
+	Equal(t, Test{ID: "1", Inner: Inner{Name: "n"}}, test)
+
+	// the current name is tried first
+	test = Test{}
+	NoError(t, d.Decode(&test, url.Values{"user_id": {"2"}, "uid": {"1"}}, nil))
+	Equal(t, "2", test.ID)
+
+	// values encoded during a migration decode with both names present
+	e := NewEncoder()
+	e.SetKeyMigrationMode(KeyMigrationDualWrite)
+
+	values, err := e.Encode(Test{ID: "3", Inner: Inner{Name: "m"}})
+	NoError(t, err)
+
+	test = Test{}
+	NoError(t, d.Decode(&test, values, nil))
+	Equal(t, Test{ID: "3", Inner: Inner{Name: "m"}}, test)
+}
+
+type testPayment interface {
+	Amount() int
+}
+
+type testCard struct {
+	Number string `form:"number"`
+	Total  int    `form:"total"`
+}
+
+func (c *testCard) Amount() int { return c.Total }
+
+type testTransfer struct {
+	IBAN  string `form:"iban"`
+	Total int    `form:"total"`
+}
+
+func (t testTransfer) Amount() int { return t.Total }
+
+func TestDecoderInterfaceFactory(t *testing.T) {
+	t.Parallel()
+
+	type Order struct {
+		Payment  testPayment   `form:"payment"`
+		Payments []testPayment `form:"payments"`
+		Other    testPayment   `form:"other"`
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterInterfaceFactory(reflect.TypeOf((*testPayment)(nil)).Elem(), func(values url.Values, namespace string) interface{} {
+		switch values.Get(namespace + ".type") {
+		case "card":
+			return &testCard{}
+		case "transfer":
+			return testTransfer{}
+		case "invalid":
+			return "x"
+		}
+
+		return nil
+	})
+
+	var o Order
+	err := d.Decode(&o, url.Values{
+		"payment.type":      {"card"},
+		"payment.number":    {"4242"},
+		"payment.total":     {"10"},
+		"payments[0].type":  {"transfer"},
+		"payments[0].iban":  {"DE89"},
+		"payments[0].total": {"20"},
+		"payments[1].type":  {"card"},
+		"payments[1].total": {"30"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, &testCard{Number: "4242", Total: 10}, o.Payment)
+	Equal(t, []testPayment{testTransfer{IBAN: "DE89", Total: 20}, &testCard{Total: 30}}, o.Payments)
+	Nil(t, o.Other)
+
+	o = Order{}
+	err = d.Decode(&o, url.Values{"other.type": {"invalid"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrUnsupportedType))
+	Nil(t, o.Other)
+}
+
+func TestDecoderVariants(t *testing.T) {
+	t.Parallel()
+
+	type Order struct {
+		Payment  testPayment   `form:"payment,discriminator=kind"`
+		Payments []testPayment `form:"payments,discriminator=kind"`
+		Plain    testPayment   `form:"plain"`
+	}
+
+	iface := reflect.TypeOf((*testPayment)(nil)).Elem()
+
+	d := NewDecoder[any]()
+	d.RegisterVariant(iface, "card", testCard{})
+	d.RegisterVariant(iface, "transfer", testTransfer{})
+
+	var o Order
+	err := d.Decode(&o, url.Values{
+		"payment.kind":      {"card"},
+		"payment.number":    {"4242"},
+		"payments[0].kind":  {"transfer"},
+		"payments[0].iban":  {"DE89"},
+		"payments[1].kind":  {"card"},
+		"payments[1].total": {"30"},
+		"plain.kind":        {"card"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, &testCard{Number: "4242"}, o.Payment)
+	Equal(t, []testPayment{testTransfer{IBAN: "DE89"}, &testCard{Total: 30}}, o.Payments)
+	Nil(t, o.Plain)
+
+	o = Order{}
+	err = d.Decode(&o, url.Values{"payment.kind": {"cash"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrUnsupportedType))
+	Equal(t, "Field Namespace:payment.kind ERROR:unknown variant 'cash' of interface 'form.testPayment' namespace 'payment.kind'", err.Error())
+	Nil(t, o.Payment)
+}
+
+type testUpper string
+
+func TestDecoderFuncScope(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Value testUpper
+	}
+
+	type Test struct {
+		Value    testUpper
+		Ptr      *testUpper
+		Slice    []testUpper
+		Ptrs     []*testUpper
+		SlicePtr *[]testUpper
+		Array    [2]testUpper
+		Map      map[testUpper]testUpper
+		CSV      []testUpper `collectionFormat:"csv"`
+		Inners   []Inner
+	}
+
+	values := url.Values{
+		"Value":           {"a"},
+		"Ptr":             {"b"},
+		"Slice":           {"c", "d"},
+		"Ptrs":            {"e"},
+		"SlicePtr":        {"f"},
+		"Array":           {"g", "h"},
+		"Map[k]":          {"v"},
+		"CSV":             {"i,j"},
+		"Inners[0].Value": {"k"},
+	}
+
+	upper := func(s string, _ any) (interface{}, error) {
+		return testUpper(strings.ToUpper(s)), nil
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFunc(upper, reflect.TypeOf(testUpper("")))
+
+	var test Test
+	NoError(t, d.Decode(&test, values, nil))
+	Equal(t, testUpper("A"), test.Value)
+	Equal(t, testUpper("B"), *test.Ptr)
+	Equal(t, []testUpper{"C", "D"}, test.Slice)
+	Equal(t, testUpper("E"), *test.Ptrs[0])
+	Equal(t, []testUpper{"F"}, *test.SlicePtr)
+	Equal(t, [2]testUpper{"G", "H"}, test.Array)
+	Equal(t, map[testUpper]testUpper{"K": "V"}, test.Map)
+	Equal(t, []testUpper{"I", "J"}, test.CSV)
+	Equal(t, []Inner{{Value: "K"}}, test.Inners)
+
+	d.SetFuncScope(FuncScopeExact)
+
+	test = Test{}
+	NoError(t, d.Decode(&test, values, nil))
+	Equal(t, testUpper("A"), test.Value)
+	Equal(t, testUpper("b"), *test.Ptr)
+	Equal(t, []testUpper{"c", "d"}, test.Slice)
+	Equal(t, testUpper("e"), *test.Ptrs[0])
+	Equal(t, []testUpper{"f"}, *test.SlicePtr)
+	Equal(t, [2]testUpper{"g", "h"}, test.Array)
+	Equal(t, map[testUpper]testUpper{"k": "v"}, test.Map)
+	Equal(t, []testUpper{"i", "j"}, test.CSV)
+	Equal(t, []Inner{{Value: "K"}}, test.Inners)
+
+	var value testUpper
+	NoError(t, d.Decode(&value, url.Values{"": {"x"}}, nil))
+	Equal(t, testUpper("X"), value)
+}
+
+func TestDecoderRemainder(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City  string     `form:"city"`
+		Extra url.Values `form:",remainder"`
+	}
+
+	type Test struct {
+		Name    string            `form:"name"`
+		Tags    []string          `form:"tags"`
+		Attrs   map[string]string `form:"attrs"`
+		Address Address           `form:"address"`
+		Extra   url.Values        `form:"extra,remainder"`
+	}
+
+	d := NewDecoder[any]()
+
+	var test Test
+	err := d.Decode(&test, url.Values{
+		"name":           {"joe"},
+		"tags[0]":        {"a"},
+		"attrs[color]":   {"red"},
+		"address.city":   {"Berlin"},
+		"address.street": {"Main"},
+		"utm_source":     {"mail", "web"},
+		"names":          {"x"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, "joe", test.Name)
+	Equal(t, []string{"a"}, test.Tags)
+	Equal(t, map[string]string{"color": "red"}, test.Attrs)
+	Equal(t, url.Values{"address.street": {"Main"}}, test.Address.Extra)
+	Equal(t, url.Values{"address.street": {"Main"}, "utm_source": {"mail", "web"}, "names": {"x"}}, test.Extra)
+
+	test = Test{}
+	NoError(t, d.Decode(&test, url.Values{"name": {"joe"}}, nil))
+	Nil(t, test.Extra)
+
+	values, err := NewEncoder().Encode(Test{
+		Name:  "joe",
+		Extra: url.Values{"utm_source": {"mail"}, "name": {"other"}},
+	})
+	NoError(t, err)
+	Equal(t, url.Values{"name": {"joe", "other"}, "utm_source": {"mail"}, "address.city": {""}}, values)
+}
+
+func TestDecoderBoolStrings(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		A bool
+		B bool
+		C *bool
+		D bool
+		M map[bool]string
+	}
+
+	d := NewDecoder[any]()
+	d.SetBoolStrings([]string{"on", "Y"}, []string{"off", "N"})
+
+	var test Test
+	err := d.Decode(&test, url.Values{"A": {"on"}, "B": {"N"}, "C": {"Y"}, "D": {""}, "M[on]": {"x"}}, nil)
+	NoError(t, err)
+	True(t, test.A)
+	False(t, test.B)
+	True(t, *test.C)
+	False(t, test.D)
+	Equal(t, map[bool]string{true: "x"}, test.M)
+
+	err = d.Decode(&test, url.Values{"A": {"true"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidBool))
+
+	d.SetBoolStrings(nil, nil)
+
+	test = Test{}
+	NoError(t, d.Decode(&test, url.Values{"A": {"true"}, "B": {"yes"}}, nil))
+	True(t, test.A)
+	True(t, test.B)
+}
+
+func TestDecoderBoolFormat(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Newsletter bool            `form:"newsletter,bool=on|off"`
+		Terms      *bool           `form:"terms,bool=yes|"`
+		Days       []bool          `form:"days,bool=Y|N"`
+		Options    map[string]bool `form:"options,bool=on|off"`
+		Plain      bool            `form:"plain"`
+	}
+
+	values := url.Values{
+		"newsletter":    {"on"},
+		"terms":         {"yes"},
+		"days":          {"Y", "N", "true"},
+		"options[dark]": {"off"},
+		"plain":         {"on"},
+	}
+
+	var test Test
+	NoError(t, NewDecoder[any]().Decode(&test, values, nil))
+	True(t, test.Newsletter)
+	True(t, *test.Terms)
+	Equal(t, []bool{true, false, true}, test.Days)
+	Equal(t, map[string]bool{"dark": false}, test.Options)
+	True(t, test.Plain)
+
+	test = Test{Newsletter: true}
+	NoError(t, NewDecoder[any]().Decode(&test, url.Values{"newsletter": {"off"}}, nil))
+	False(t, test.Newsletter)
+
+	enc := NewEncoder()
+	encoded, err := enc.Encode(Test{Newsletter: true, Days: []bool{false, true}}, nil)
+	NoError(t, err)
+
+	var decoded Test
+	NoError(t, NewDecoder[any]().Decode(&decoded, encoded, nil))
+	True(t, decoded.Newsletter)
+	Equal(t, []bool{false, true}, decoded.Days)
+
+	err = NewDecoder[any]().Decode(&test, url.Values{"newsletter": {"maybe"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidBool))
+}
+
+func TestDecoderCheckbox(t *testing.T) {
+	t.Parallel()
+
+	type Prefs struct {
+		Theme string `form:"theme"`
+		Alert bool   `form:"alert,checkbox"`
+	}
+
+	type Test struct {
+		Newsletter bool   `form:"newsletter,checkbox"`
+		Terms      *bool  `form:"terms,checkbox"`
+		Plain      bool   `form:"plain"`
+		Prefs      *Prefs `form:"prefs"`
+		Name       string
+	}
+
+	yes := true
+	test := Test{Newsletter: true, Terms: &yes, Plain: true}
+
+	d := NewDecoder[any]()
+	d.SetMode(ModeExplicit)
+
+	NoError(t, d.Decode(&test, url.Values{"Name": {"joe"}}, nil))
+	False(t, test.Newsletter)
+	False(t, *test.Terms)
+	True(t, test.Plain)
+	Equal(t, "", test.Name)
+
+	// unchecked checkboxes do not allocate parent struct pointers
+	Nil(t, test.Prefs)
+
+	NoError(t, d.Decode(&test, url.Values{"newsletter": {"on"}, "prefs.theme": {"dark"}}, nil))
+	True(t, test.Newsletter)
+	NotNil(t, test.Prefs)
+	Equal(t, Prefs{Theme: "dark"}, *test.Prefs)
+}
+
+func TestDecoderNumberSeparators(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Int   int
+		Uint  uint32
+		Float float64
+		Map   map[int]string
+	}
+
+	d := NewDecoder[any]()
+	d.SetNumberSeparators(',', '.')
+
+	var test Test
+	err := d.Decode(&test, url.Values{"Int": {"-1,234,567"}, "Uint": {"12,345"}, "Float": {"1,234.56"}, "Map[1,000]": {"k"}}, nil)
+	NoError(t, err)
+	Equal(t, Test{Int: -1234567, Uint: 12345, Float: 1234.56, Map: map[int]string{1000: "k"}}, test)
+
+	test = Test{}
+	err = d.Decode(&test, url.Values{"Int": {"12,34"}, "Uint": {",123"}, "Float": {"1234,567.5"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 3, len(errs))
+	True(t, errors.Is(errs["Int"], ErrInvalidInt))
+	True(t, errors.Is(errs["Uint"], ErrInvalidUint))
+	True(t, errors.Is(errs["Float"], ErrInvalidFloat))
+
+	d.SetNumberSeparators('.', ',')
+
+	test = Test{}
+	NoError(t, d.Decode(&test, url.Values{"Int": {"1.234"}, "Float": {"1.234,5"}}, nil))
+	Equal(t, 1234, test.Int)
+	Equal(t, 1234.5, test.Float)
+
+	// a decimal point is a thousands separator and has to group three digits
+	err = d.Decode(&test, url.Values{"Float": {"1.5"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err.(DecodeErrors)["Float"], ErrInvalidFloat))
+
+	d.SetNumberSeparators(0, 0)
+
+	err = d.Decode(&test, url.Values{"Int": {"1,234"}}, nil)
+	NotNil(t, err)
+}
+
+func TestDecoderEmptyZero(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Price    float64 `form:"price,emptyzero"`
+		Quantity *int    `form:"quantity,emptyzero"`
+		Count    uint    `form:"count,emptyzero"`
+		Plain    int     `form:"plain"`
+		Name     string  `form:"name,emptyzero"`
+	}
+
+	test := Test{Price: 9.5, Count: 3, Plain: 7, Name: "x"}
+
+	d := NewDecoder[any]()
+
+	NoError(t, d.Decode(&test, url.Values{"price": {""}, "quantity": {""}, "plain": {""}, "name": {""}}, nil))
+	Equal(t, 0.0, test.Price)
+	NotNil(t, test.Quantity)
+	Equal(t, 0, *test.Quantity)
+	Equal(t, uint(3), test.Count)
+	Equal(t, 7, test.Plain)
+	Equal(t, "", test.Name)
+
+	NoError(t, d.Decode(&test, url.Values{"price": {"2.5"}, "quantity": {"4"}}, nil))
+	Equal(t, 2.5, test.Price)
+	Equal(t, 4, *test.Quantity)
+}
+
+func TestDecoderPermissiveNumbers(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Hex    int
+		Bin    uint8
+		Oct    int16
+		Neg    int
+		Zero   int
+		Exp    int64
+		UExp   uint
+		Float  float64
+		HexMap map[uint16]string
+	}
+
+	values := url.Values{
+		"Hex":          {"0x1F"},
+		"Bin":          {"0b1010"},
+		"Oct":          {"0o17"},
+		"Neg":          {"-0X10"},
+		"Zero":         {"010"},
+		"Exp":          {"2.5e2"},
+		"UExp":         {"1E3"},
+		"Float":        {"1.5e-3"},
+		"HexMap[0xff]": {"v"},
+	}
+
+	var test Test
+
+	d := NewDecoder[any]()
+	NotNil(t, d.Decode(&test, values, nil))
+
+	d.SetPermissiveNumbers(true)
+
+	test = Test{}
+	NoError(t, d.Decode(&test, values, nil))
+	Equal(t, Test{
+		Hex:    31,
+		Bin:    10,
+		Oct:    15,
+		Neg:    -16,
+		Zero:   10,
+		Exp:    250,
+		UExp:   1000,
+		Float:  0.0015,
+		HexMap: map[uint16]string{255: "v"},
+	}, test)
+
+	err := d.Decode(&test, url.Values{"Hex": {"1.5e0"}, "Bin": {"0x100"}, "Oct": {"1e5"}, "UExp": {"-1e2"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 4, len(errs))
+	True(t, errors.Is(errs["Hex"], ErrInvalidInt))
+	True(t, errors.Is(errs["Bin"], ErrInvalidUint))
+	True(t, errors.Is(errs["Oct"], ErrInvalidInt))
+	True(t, errors.Is(errs["UExp"], ErrInvalidUint))
+
+	// huge exponents are out of range without computing the number
+	err = d.Decode(&test, url.Values{"Exp": {"1e600000000"}, "UExp": {"1e9999"}, "Neg": {"-5e0000000018"}, "Oct": {"1e-600000000"}}, nil)
+	NotNil(t, err)
+
+	errs = err.(DecodeErrors)
+	Equal(t, 3, len(errs))
+	True(t, errors.Is(errs["Exp"], ErrInvalidInt))
+	True(t, errors.Is(errs["UExp"], ErrInvalidUint))
+	True(t, errors.Is(errs["Oct"], ErrInvalidInt))
+	Equal(t, -5000000000000000000, test.Neg)
+}
+
+func TestDecodeCollect(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+	}
+
+	type User struct {
+		Name    string    `form:"name"`
+		Age     *int      `form:"age"`
+		Tags    []string  `form:"tags"`
+		Address Address   `form:"address"`
+		Others  []Address `form:"others"`
+		Note    string    `form:"note"`
+	}
+
+	values := url.Values{
+		"name":           {"joe"},
+		"age":            {"3"},
+		"tags":           {"a", "b"},
+		"address.city":   {"Berlin"},
+		"others[0].city": {"Paris"},
+	}
+
+	var user User
+
+	goValues, err := NewDecoder[any]().DecodeCollect(&user, values, nil)
+	NoError(t, err)
+
+	Equal(t, map[string]interface{}{
+		"name":           "joe",
+		"age":            3,
+		"tags":           []string{"a", "b"},
+		"address.city":   "Berlin",
+		"others[0].city": "Paris",
+	}, goValues)
+
+	encoded, encodedGoValues, err := NewEncoder().EncodeCollect(user)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"name":           {"joe"},
+		"age":            {"3"},
+		"tags":           {"a", "b"},
+		"address.city":   {"Berlin"},
+		"others[0].city": {"Paris"},
+		"note":           {""},
+	}, encoded)
+
+	for k, v := range goValues {
+		Equal(t, v, encodedGoValues[k], k)
+	}
+}
+
+func TestDecoder_SetMergeMode(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+		Zip  string `form:"zip"`
+	}
+
+	type Test struct {
+		Name    string             `form:"name"`
+		Age     int                `form:"age"`
+		Tags    []string           `form:"tags"`
+		Items   []int              `form:"items"`
+		Pair    [2]string          `form:"pair"`
+		Labels  map[string]string  `form:"labels"`
+		Address Address            `form:"address"`
+		Homes   map[string]Address `form:"homes"`
+	}
+
+	defaults := func() Test {
+		return Test{
+			Name:    "default",
+			Age:     10,
+			Tags:    []string{"a"},
+			Items:   []int{1, 2},
+			Pair:    [2]string{"x", "y"},
+			Labels:  map[string]string{"env": "dev"},
+			Address: Address{City: "Berlin", Zip: "10115"},
+			Homes:   map[string]Address{"main": {City: "Paris", Zip: "75001"}},
+		}
+	}
+
+	values := url.Values{
+		"name":            {"joe"},
+		"tags":            {"b"},
+		"items[0]":        {"3"},
+		"pair":            {"z"},
+		"labels[team]":    {"core"},
+		"address.city":    {"Rome"},
+		"homes[main].zip": {"75002"},
+	}
+
+	tests := []struct {
+		mode     MergeMode
+		expected Test
+	}{
+		{
+			mode: MergeDefault,
+			expected: Test{
+				Name:    "joe",
+				Age:     10,
+				Tags:    []string{"a", "b"},
+				Items:   []int{3, 2},
+				Pair:    [2]string{"z", "y"},
+				Labels:  map[string]string{"env": "dev", "team": "core"},
+				Address: Address{City: "Rome", Zip: "10115"},
+				Homes:   map[string]Address{"main": {City: "Paris", Zip: "75002"}},
+			},
+		},
+		{
+			mode: MergeReplace,
+			expected: Test{
+				Name:    "joe",
+				Tags:    []string{"b"},
+				Items:   []int{3},
+				Pair:    [2]string{"z", ""},
+				Labels:  map[string]string{"team": "core"},
+				Address: Address{City: "Rome"},
+				Homes:   map[string]Address{"main": {Zip: "75002"}},
+			},
+		},
+		{
+			mode: MergeOverlay,
+			expected: Test{
+				Name:    "joe",
+				Age:     10,
+				Tags:    []string{"b"},
+				Items:   []int{3},
+				Pair:    [2]string{"z", ""},
+				Labels:  map[string]string{"team": "core"},
+				Address: Address{City: "Rome", Zip: "10115"},
+				Homes:   map[string]Address{"main": {Zip: "75002"}},
+			},
+		},
+		{
+			mode: MergeAppend,
+			expected: Test{
+				Name:    "joe",
+				Age:     10,
+				Tags:    []string{"a", "b"},
+				Items:   []int{1, 2, 3},
+				Pair:    [2]string{"z", "y"},
+				Labels:  map[string]string{"env": "dev", "team": "core"},
+				Address: Address{City: "Rome", Zip: "10115"},
+				Homes:   map[string]Address{"main": {City: "Paris", Zip: "75002"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		decoder := NewDecoder[any]()
+		decoder.SetMergeMode(tt.mode)
+
+		test := defaults()
+
+		err := decoder.Decode(&test, values, nil)
+		NoError(t, err)
+		Equal(t, tt.expected, test, tt.mode)
+	}
+
+	// existing values are kept when all values of a field are invalid
+	decoder := NewDecoder[any]()
+	decoder.SetMergeMode(MergeOverlay)
+
+	test := defaults()
+
+	err := decoder.Decode(&test, url.Values{"items": {"x"}, "items[0]": {"y"}}, nil)
+	NotNil(t, err)
+	Equal(t, []int{1, 2}, test.Items)
+}
+
+func TestDecoder_SliceAppendResetOptions(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Tags    []string  `form:"tags,append"`
+		Items   []int     `form:"items,append"`
+		Filters []string  `form:"filters,reset"`
+		Ptr     *[]string `form:"ptr,reset"`
+		Name    string    `form:"name,reset"`
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.SetMergeMode(MergeOverlay)
+
+	ptr := []string{"p"}
+	test := Test{Tags: []string{"a"}, Items: []int{1}, Filters: []string{"old"}, Ptr: &ptr, Name: "joe"}
+
+	err := decoder.Decode(&test, url.Values{"tags": {"b"}, "items[0]": {"2"}}, nil)
+	NoError(t, err)
+	Equal(t, []string{"a", "b"}, test.Tags)
+	Equal(t, []int{1, 2}, test.Items)
+	Nil(t, test.Filters)
+	Nil(t, *test.Ptr)
+	Equal(t, "joe", test.Name)
+
+	err = decoder.Decode(&test, url.Values{"tags": {"c"}, "filters": {"new"}}, nil)
+	NoError(t, err)
+	Equal(t, []string{"a", "b", "c"}, test.Tags)
+	Equal(t, []string{"new"}, test.Filters)
+}
+
+func TestDecoder_FixedArrays(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		BBox  [4]float64 `form:"bbox"`
+		Color [3]uint8   `form:"color"`
+	}
+
+	test := Test{BBox: [4]float64{-1, -2, 3, 4}, Color: [3]uint8{10, 20, 30}}
+
+	encoder := NewEncoder()
+	encoder.SetArrayMode(ArrayIndexed)
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"bbox[0]":  {"-1"},
+		"bbox[1]":  {"-2"},
+		"bbox[2]":  {"3"},
+		"bbox[3]":  {"4"},
+		"color[0]": {"10"},
+		"color[1]": {"20"},
+		"color[2]": {"30"},
+	}, values)
+
+	var decoded Test
+
+	err = NewDecoder[any]().Decode(&decoded, values, nil)
+	NoError(t, err)
+	Equal(t, test, decoded)
+
+	values, err = NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, []string{"-1", "-2", "3", "4"}, values["bbox"])
+
+	values["color[3]"] = []string{"40"}
+
+	decoded = Test{}
+
+	err = NewDecoder[any]().Decode(&decoded, values, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 1, len(errs))
+	Equal(t, "array index '3' out of bounds for length 3", errs["color[3]"].Error())
+	Equal(t, test, decoded)
+}
+
+func TestDecoder_DelimitedSlices(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		IDs      []int     `form:"ids,explode=false"`
+		Names    []string  `form:"names,delim=|"`
+		Words    []string  `form:"words,delim=; "`
+		Exploded []string  `form:"exploded,explode=true" collectionFormat:"csv"`
+		Scores   []float64 `form:"scores,explode=false,omitempty"`
+	}
+
+	values := url.Values{
+		"ids":      {"1,2,3"},
+		"names":    {"a|b"},
+		"words":    {"x; y"},
+		"exploded": {"a,b", "c"},
+	}
+
+	var test Test
+
+	err := NewDecoder[any]().Decode(&test, values, nil)
+	NoError(t, err)
+	Equal(t, Test{
+		IDs:      []int{1, 2, 3},
+		Names:    []string{"a", "b"},
+		Words:    []string{"x", "y"},
+		Exploded: []string{"a,b", "c"},
+	}, test)
+
+	encoded, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, values, encoded)
+
+	err = NewDecoder[any]().Decode(&Test{}, url.Values{"ids": {"1,x"}}, nil)
+	NotNil(t, err)
+	Contains(t, err.(DecodeErrors)["ids"].Error(), "x")
+
+	// values of other fields are not truncated at the delimiter
+	type Scalar struct {
+		Name string  `form:"name,delim=|"`
+		Code *string `form:"code,delim=|"`
+	}
+
+	var scalar Scalar
+
+	err = NewDecoder[any]().Decode(&scalar, url.Values{"name": {"a|b"}, "code": {"c|d"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err.(DecodeErrors)["name"], ErrInvalidTagOption))
+	True(t, errors.Is(err.(DecodeErrors)["code"], ErrInvalidTagOption))
+	Equal(t, Scalar{}, scalar)
+}
+
+func TestDecoder_SetKeyStyleRails(t *testing.T) {
+	t.Parallel()
+
+	type Line struct {
+		Name  string   `form:"name"`
+		Qty   int      `form:"qty"`
+		Notes []string `form:"notes"`
+	}
+
+	type Order struct {
+		Customer struct {
+			City string `form:"city"`
+		} `form:"customer"`
+		Tags  []string `form:"tags"`
+		Lines []Line   `form:"lines"`
+	}
+
+	type Test struct {
+		Order Order `form:"order"`
+	}
+
+	values := url.Values{
+		"order[customer][city]":   {"Paris"},
+		"order[tags][]":           {"a", "b"},
+		"order[lines][][name]":    {"x", "y"},
+		"order[lines][][qty]":     {"1", "2"},
+		"order[lines][][notes][]": {"n"},
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.SetKeyStyle(StyleRails)
+
+	var test Test
+
+	err := decoder.Decode(&test, values, nil)
+	NoError(t, err)
+	Equal(t, "Paris", test.Order.Customer.City)
+	Equal(t, []string{"a", "b"}, test.Order.Tags)
+	Equal(t, []Line{{Name: "x", Qty: 1, Notes: []string{"n"}}, {Name: "y", Qty: 2}}, test.Order.Lines)
+
+	// the caller's values are not modified
+	Equal(t, 5, len(values))
+	Equal(t, []string{"x", "y"}, values["order[lines][][name]"])
+}
+
+func TestDecoder_SetLooseInterfaceDecoding(t *testing.T) {
+	t.Parallel()
+
+	type Filter struct {
+		Status interface{}   `form:"status"`
+		Tag    interface{}   `form:"tag"`
+		IDs    []interface{} `form:"id"`
+		Count  interface{}   `form:"count"`
+	}
+
+	values := url.Values{
+		"status": {"open", "closed"},
+		"tag":    {"go"},
+		"id":     {"1", "2"},
+		"count":  {"1", "2"},
+	}
+
+	d := NewDecoder[any]()
+
+	var filter Filter
+
+	NoError(t, d.Decode(&filter, values, nil))
+	Equal(t, "open", filter.Status)
+
+	d.SetLooseInterfaceDecoding(true)
+
+	count := 0
+	filter = Filter{Count: &count}
+
+	NoError(t, d.Decode(&filter, values, nil))
+	Equal(t, []string{"open", "closed"}, filter.Status)
+	Equal(t, "go", filter.Tag)
+	Equal(t, []interface{}{"1", "2"}, filter.IDs)
+	Equal(t, 1, count)
+
+	// registered factories take precedence
+	d.RegisterInterfaceFactory(reflect.TypeOf((*interface{})(nil)).Elem(), func(values url.Values, namespace string) interface{} {
+		return new(int)
+	})
+
+	filter = Filter{}
+
+	NoError(t, d.Decode(&filter, url.Values{"status": {"3", "4"}}, nil))
+	Equal(t, 3, *filter.Status.(*int))
+}
@@ -0,0 +1,218 @@
+package form
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+const defaultTag = "default"
+
+var defaultTagTypes sync.Map // map[reflect.Type]bool, whether the struct type or its nested structs have `default` tags
+
+// ApplyDefaults sets zero fields of a struct to their defaults with a default Decoder, see Decoder.ApplyDefaults.
+func ApplyDefaults(v interface{}) error {
+	defaultDecoderOnce.Do(func() {
+		defaultDecoder = NewDecoder[any]()
+	})
+
+	return defaultDecoder.ApplyDefaults(v)
+}
+
+// RegisterDefault registers a default value for fields of its type without `default` tag,
+// eg. RegisterDefault(time.UTC) or RegisterDefault(Currency("EUR")). It is applied to zero fields
+// without values when decoding and by ApplyDefaults.
+// It is safe to call concurrently with decoding, calls in progress keep using previously registered defaults.
+func (d *Decoder[DecodeFuncArgument]) RegisterDefault(value interface{}) {
+	d.checkFrozen()
+
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return
+	}
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.typeDefaults == nil {
+			f.typeDefaults = map[reflect.Type]reflect.Value{}
+		}
+
+		f.typeDefaults[v.Type()] = v
+	})
+}
+
+// ApplyDefaults sets zero fields of a struct, including nested structs, non-nil pointers to structs
+// and elements of slices and arrays, to their defaults.
+//
+// A default is taken from `default` tag, eg. `default:"10"`, and decoded as a value of the field
+// with the registered functions and tag options of the decoder, or from a value registered for the field type
+// with RegisterDefault. Decode applies the same defaults to fields without values, ApplyDefaults does not
+// depend on decoding, so they can be applied to values constructed programmatically.
+//
+// ApplyDefaults returns an InvalidDecoderError if v is not a non-nil pointer.
+func (d *Decoder[DecodeFuncArgument]) ApplyDefaults(v interface{}) error {
+	val := reflect.ValueOf(v)
+
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return &InvalidDecoderError{Type: reflect.TypeOf(v)}
+	}
+
+	dec := d.dataPool.Get().(*decoder[DecodeFuncArgument]) //nolint:errcheck
+	dec.opts = d.opts
+	dec.funcs = d.funcs.Load()
+	dec.structCache = d.structCache.Load()
+	dec.dm = dec.dm[0:0]
+
+	val = val.Elem()
+	namespace := dec.namespace[0:0]
+
+	if val.Kind() == reflect.Struct {
+		namespace = dec.rootNamespace(val.Type())
+	}
+
+	dec.setDefaults(val, namespace, true)
+
+	var err error
+
+	if len(dec.errs) > 0 {
+		err = dec.errs
+	}
+
+	dec.reset()
+
+	d.dataPool.Put(dec)
+
+	return err
+}
+
+// setDefaults sets zero fields of a struct, including nested structs and non-nil pointers to structs,
+// to their defaults, elements of slices and arrays are set only with elems.
+func (d *decoder[DecodeFuncArgument]) setDefaults(v reflect.Value, namespace []byte, elems bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			d.setDefaults(v.Elem(), namespace, elems)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if !elems {
+			return
+		}
+
+		l := len(namespace)
+
+		for i := 0; i < v.Len(); i++ {
+			namespace = append(namespace[:l], '[')
+			namespace = strconv.AppendInt(namespace, int64(i), 10)
+			namespace = append(namespace, ']')
+
+			d.setDefaults(v.Index(i), namespace, elems)
+		}
+
+	case reflect.Struct:
+		typ := v.Type()
+
+		if isValueStruct(typ) {
+			return
+		}
+
+		s, ok := d.structCache.Get(d.opts.Mode, typ, d.opts.TagName)
+		if !ok {
+			s = d.structCache.parseStruct(d.opts.Mode, typ, d.opts.TagName)
+		}
+
+		// pointers of recursive types may form cycles
+		if !d.enterStruct(typ, namespace) {
+			return
+		}
+
+		defer func() {
+			d.path = d.path[:len(d.path)-1]
+		}()
+
+		l := len(namespace)
+
+		for _, f := range s.fields {
+			if !f.canSet {
+				continue
+			}
+
+			fv := v.Field(f.idx)
+			namespace = d.appendName(namespace[:l], l == 0, f.name)
+
+			if !d.setDefault(fv, f, namespace) {
+				d.setDefaults(fv, namespace, elems)
+			}
+		}
+	}
+}
+
+// setDefault sets a zero field to the default of its `default` tag, decoded like a value of the field,
+// or to the default registered for its type and reports whether it did. Defaults are not recorded
+// as decoded values, eg. by DecodeWithProvenance.
+func (d *decoder[DecodeFuncArgument]) setDefault(fv reflect.Value, f cachedField, namespace []byte) bool {
+	if !fv.CanSet() || !fv.IsZero() {
+		return false
+	}
+
+	if f.hasDefault {
+		values, owned, dm, dmDone, maxKeyLen, isHeader := d.values, d.valuesOwned, d.dm, d.dmDone, d.maxKeyLen, d.isHeader
+		sources, provenance, consumed := d.sources, d.provenance, d.consumed
+
+		d.values, d.valuesOwned, d.dm, d.dmDone, d.isHeader = url.Values{string(namespace): {f.defaultValue}}, false, nil, false, false
+		d.sources, d.provenance, d.consumed = nil, nil, nil
+
+		d.setField(fv, f, namespace)
+
+		d.values, d.valuesOwned, d.dm, d.dmDone, d.maxKeyLen, d.isHeader = values, owned, dm, dmDone, maxKeyLen, isHeader
+		d.sources, d.provenance, d.consumed = sources, provenance, consumed
+
+		return true
+	}
+
+	if dv, ok := d.funcs.typeDefaults[fv.Type()]; ok {
+		fv.Set(dv)
+
+		return true
+	}
+
+	return false
+}
+
+// hasDefaultTags reports whether fields of the struct type, or of structs nested in it, have `default` tags.
+func hasDefaultTags(typ reflect.Type) bool {
+	if has, ok := defaultTagTypes.Load(typ); ok {
+		return has.(bool) //nolint:forcetypeassert
+	}
+
+	has := reachesDefaultTag(typ, map[reflect.Type]struct{}{})
+	defaultTagTypes.Store(typ, has)
+
+	return has
+}
+
+func reachesDefaultTag(typ reflect.Type, seen map[reflect.Type]struct{}) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct || isValueStruct(typ) {
+		return false
+	}
+
+	if _, ok := seen[typ]; ok {
+		return false
+	}
+
+	seen[typ] = struct{}{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+
+		if _, ok := fld.Tag.Lookup(defaultTag); ok || reachesDefaultTag(fld.Type, seen) {
+			return true
+		}
+	}
+
+	return false
+}
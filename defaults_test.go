@@ -0,0 +1,152 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type defaultsCurrency string
+
+func TestApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	d := NewDecoder[any]()
+	d.RegisterDefault(defaultsCurrency("EUR"))
+
+	type Price struct {
+		Amount   float64 `default:"9.99"`
+		Currency defaultsCurrency
+	}
+
+	type Item struct {
+		Price
+		Name     string        `default:"unnamed"`
+		Count    int           `default:"1"`
+		Enabled  *bool         `default:"true"`
+		Timeout  time.Duration `default:"5"`
+		Created  time.Time     `default:"2006-01-02T15:04:05Z"`
+		Variants []Price
+		Parent   *Price
+		Missing  *Price
+		Explicit defaultsCurrency `default:"USD"`
+		Set      int              `default:"3"`
+		internal string           `default:"x"`
+	}
+
+	v := Item{
+		Set:      7,
+		Variants: []Price{{Amount: 1}, {}},
+		Parent:   &Price{Currency: "GBP"},
+	}
+
+	err := d.ApplyDefaults(&v)
+	NoError(t, err)
+
+	enabled := true
+
+	Equal(t, Item{
+		Price:    Price{Amount: 9.99, Currency: "EUR"},
+		Name:     "unnamed",
+		Count:    1,
+		Enabled:  &enabled,
+		Timeout:  5,
+		Created:  time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		Variants: []Price{{Amount: 1, Currency: "EUR"}, {Amount: 9.99, Currency: "EUR"}},
+		Parent:   &Price{Amount: 9.99, Currency: "GBP"},
+		Explicit: "USD",
+		Set:      7,
+	}, v)
+
+	var bad struct {
+		Count int `default:"abc"`
+	}
+
+	err = ApplyDefaults(&bad)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:Count ERROR:invalid integer value 'abc' type 'int' namespace 'Count'", err.Error())
+
+	var fe *FieldError
+
+	True(t, errors.As(err.(DecodeErrors)["Count"], &fe))
+	Equal(t, "Count", fe.Namespace())
+	Equal(t, "abc", fe.RawValue())
+
+	err = d.ApplyDefaults(v)
+	NotNil(t, err)
+}
+
+func TestDecoderDefaults(t *testing.T) {
+	t.Parallel()
+
+	type Page struct {
+		Size  int    `query:"size" default:"20"`
+		Order string `query:"order" default:"asc"`
+	}
+
+	type Filter struct {
+		Page     Page             `query:"page"`
+		Next     *Page            `query:"next"`
+		Count    int              `query:"count" default:"10"`
+		Since    time.Time        `query:"since" default:"yesterday"`
+		Currency defaultsCurrency `query:"currency"`
+		Name     string           `query:"name"`
+	}
+
+	yesterday := time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	d := NewDecoder[any]()
+	d.SetTagName("query")
+	d.RegisterDefault(defaultsCurrency("EUR"))
+	d.RegisterFunc(func(s string, _ any) (interface{}, error) {
+		if s == "yesterday" {
+			return yesterday, nil
+		}
+
+		return time.Parse(time.RFC3339, s)
+	}, timeType)
+
+	var f Filter
+
+	provenance := make(map[string]Provenance)
+
+	err := d.DecodeWithProvenance(&f, url.Values{"count": {"0"}, "name": {"x"}}, nil, "query", provenance)
+	NoError(t, err)
+	Equal(t, Filter{
+		Page:     Page{Size: 20, Order: "asc"},
+		Count:    0,
+		Since:    yesterday,
+		Currency: "EUR",
+		Name:     "x",
+	}, f)
+	Equal(t, map[string]Provenance{
+		"count": {Source: "query", Key: "count"},
+		"name":  {Source: "query", Key: "name"},
+	}, provenance)
+
+	f = Filter{}
+
+	err = d.Decode(&f, url.Values{"page.size": {"5"}, "currency": {"USD"}}, nil)
+	NoError(t, err)
+	Equal(t, Page{Size: 5, Order: "asc"}, f.Page)
+	Equal(t, 10, f.Count)
+	Equal(t, defaultsCurrency("USD"), f.Currency)
+	Nil(t, f.Next)
+
+	f = Filter{}
+
+	err = d.Decode(&f, url.Values{"count": {"abc"}}, nil)
+	NotNil(t, err)
+	Equal(t, 0, f.Count)
+
+	// defaults and functions converting them are registered per decoder
+	var other Filter
+
+	err = NewDecoder[any]().Decode(&other, url.Values{"Name": {"x"}}, nil)
+	NotNil(t, err)
+	Contains(t, err.(DecodeErrors), "Since")
+	Empty(t, other.Currency)
+}
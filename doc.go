@@ -27,8 +27,9 @@ It has the following features:
 Questions
 
 	    Does it support encoding.TextUnmarshaler?
-	    No because TextUnmarshaler only accepts []byte but posted values can have
-	    multiple values, so is not suitable.
+	    Yes, types implementing encoding.TextUnmarshaler (or pointers to them) are decoded
+	    from a single value, encoding.TextMarshaler is used symmetrically by the encoder.
+	    Multiple values are decoded into slices of such types.
 
 		Mixing array/slice with array[idx]/slice[idx], in which order are they parsed?
 		array/slice then array[idx]/slice[idx]
@@ -0,0 +1,223 @@
+package form
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// keySegment is a path element of a key, only bracketed segments can be indexes.
+type keySegment struct {
+	name      string
+	bracketed bool
+}
+
+// splitKey splits a key into path segments, eg. "a[b][0].c" into "a", "[b]", "[0]" and "c".
+func splitKey(k string) ([]keySegment, error) {
+	segs := make([]keySegment, 0, 4)
+	start := 0
+
+	for i := 0; i < len(k); i++ {
+		switch k[i] {
+		case '.':
+			if i > start {
+				segs = append(segs, keySegment{name: k[start:i]})
+			}
+
+			start = i + 1
+
+		case '[':
+			if i > start {
+				segs = append(segs, keySegment{name: k[start:i]})
+			}
+
+			end := closingBracket(k[i:])
+			if end == -1 {
+				return nil, newError(ErrInvalidKey, errMissingEndBracket, k)
+			}
+
+			segs = append(segs, keySegment{name: k[i+1 : i+end], bracketed: true})
+			i += end
+			start = i + 1
+
+		case ']':
+			return nil, newError(ErrInvalidKey, errMissingStartBracket, k)
+		}
+	}
+
+	if start < len(k) {
+		segs = append(segs, keySegment{name: k[start:]})
+	}
+
+	return segs, nil
+}
+
+// closingBracket returns the index of the bracket closing the one k starts with, brackets nested in a key
+// are part of it, eg. "[b]" of "[[b]]". It returns -1 when the bracket is not closed.
+func closingBracket(k string) int {
+	nested := 0
+
+	for i := 1; i < len(k); i++ {
+		switch k[i] {
+		case '[':
+			nested++
+		case ']':
+			if nested == 0 {
+				return i
+			}
+
+			nested--
+		}
+	}
+
+	return -1
+}
+
+// decodeDynamic decodes values into map[string]interface{} building nested maps and slices from keys,
+// eg. "a[b][0]=x" is decoded as {"a": {"b": ["x"]}}. A key with several values is decoded as []interface{}.
+func (d *decoder[DecodeFuncArgument]) decodeDynamic(v reflect.Value) {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	m := v.Interface().(map[string]interface{}) //nolint:forcetypeassert
+
+	keys := make([]string, 0, len(d.values))
+
+	for k := range d.values {
+		keys = append(keys, k)
+	}
+
+	// sorted keys keep order of appended elements and resolution of conflicting keys stable
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if d.timedOut() {
+			return
+		}
+
+		vals := d.values[k]
+		if len(vals) == 0 {
+			continue
+		}
+
+		segs, err := splitKey(k)
+		if err != nil {
+			d.setError([]byte(k), err)
+
+			continue
+		}
+
+		if len(segs) == 0 {
+			continue
+		}
+
+		leaf, ok := d.dynamicLeaf(k, vals)
+		if !ok {
+			continue
+		}
+
+		child, err := d.insertDynamic(m[segs[0].name], segs[1:], leaf)
+		if err != nil {
+			d.setValueError([]byte(k), reflect.String, vals[0], err)
+
+			continue
+		}
+
+		m[segs[0].name] = child
+	}
+}
+
+// dynamicLeaf returns a string for a single value and []interface{} for several values.
+func (d *decoder[DecodeFuncArgument]) dynamicLeaf(k string, vals []string) (interface{}, bool) {
+	leaves := make([]interface{}, len(vals))
+
+	for i, val := range vals {
+		s, err := d.checkUTF8(val)
+		if err != nil {
+			d.setValueError([]byte(k), reflect.String, val, err)
+
+			return nil, false
+		}
+
+		leaves[i] = s
+	}
+
+	if len(leaves) == 1 {
+		return leaves[0], true
+	}
+
+	return leaves, true
+}
+
+// insertDynamic sets leaf at path segs within container and returns the updated container,
+// numeric and empty bracketed segments create slices, other segments create maps.
+func (d *decoder[DecodeFuncArgument]) insertDynamic(container interface{}, segs []keySegment, leaf interface{}) (interface{}, error) {
+	if len(segs) == 0 {
+		if _, nested := container.(map[string]interface{}); nested {
+			return container, newError(ErrConflictingKeys, errConflictingKeys)
+		}
+
+		return leaf, nil
+	}
+
+	seg := segs[0]
+	arr, isArr := container.([]interface{})
+
+	if seg.bracketed && (container == nil || isArr) {
+		switch {
+		case seg.name == blank:
+			// empty brackets append, values of the last segment are appended one by one
+			if leaves, ok := leaf.([]interface{}); ok && len(segs) == 1 {
+				return append(arr, leaves...), nil
+			}
+
+			child, err := d.insertDynamic(nil, segs[1:], leaf)
+
+			return append(arr, child), err
+
+		case isDigits(seg.name):
+			idx, err := strconv.Atoi(seg.name)
+			if err == nil {
+				idx -= d.opts.IndexBase
+			}
+
+			if err != nil || idx < 0 {
+				return container, newError(ErrInvalidIndex, "invalid slice index '%s'", seg.name)
+			}
+
+			if idx >= d.opts.MaxArraySize {
+				return container, newError(ErrArrayIndexOutOfBounds, errArraySize, idx+1, d.opts.MaxArraySize)
+			}
+
+			for len(arr) <= idx {
+				arr = append(arr, nil)
+			}
+
+			arr[idx], err = d.insertDynamic(arr[idx], segs[1:], leaf)
+
+			return arr, err
+		}
+	}
+
+	m, ok := container.(map[string]interface{})
+	if !ok {
+		if container != nil && !isArr {
+			return container, newError(ErrConflictingKeys, errConflictingKeys)
+		}
+
+		m = make(map[string]interface{})
+
+		// elements of a slice keep their indexes as keys when a non-index key is mixed in
+		for i, el := range arr {
+			if el != nil {
+				m[strconv.Itoa(i)] = el
+			}
+		}
+	}
+
+	child, err := d.insertDynamic(m[seg.name], segs[1:], leaf)
+	m[seg.name] = child
+
+	return m, err
+}
@@ -0,0 +1,139 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDecodeDynamic(t *testing.T) {
+	t.Parallel()
+
+	d := NewDecoder[any]()
+
+	var m map[string]interface{}
+
+	err := d.Decode(&m, url.Values{
+		"name":                {"joe"},
+		"tags":                {"a", "b"},
+		"ids[]":               {"1", "2"},
+		"user[address][city]": {"Berlin"},
+		"user.age":            {"30"},
+		"items[0][name]":      {"first"},
+		"items[1][name]":      {"second"},
+		"items[1][tags][]":    {"x"},
+		"sparse[2]":           {"c"},
+		"mixed[0]":            {"zero"},
+		"mixed[key]":          {"value"},
+		"[top]":               {"t"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, map[string]interface{}{
+		"name": "joe",
+		"tags": []interface{}{"a", "b"},
+		"ids":  []interface{}{"1", "2"},
+		"user": map[string]interface{}{
+			"address": map[string]interface{}{"city": "Berlin"},
+			"age":     "30",
+		},
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second", "tags": []interface{}{"x"}},
+		},
+		"sparse": []interface{}{nil, nil, "c"},
+		"mixed":  map[string]interface{}{"0": "zero", "key": "value"},
+		"top":    "t",
+	}, m)
+
+	m = map[string]interface{}{"existing": "kept"}
+
+	err = d.Decode(&m, url.Values{"a[b": {"1"}, "c[99999]": {"2"}, "d": {"3"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidKey))
+	True(t, errors.Is(err, ErrArrayIndexOutOfBounds))
+	Equal(t, map[string]interface{}{"existing": "kept", "d": "3"}, m)
+
+	// the value of the key decoded first is kept, keys are decoded in sorted order
+	m = nil
+	err = d.Decode(&m, url.Values{"a": {"1"}, "a[b]": {"2"}, "c[d]": {"3"}, "c.d.e": {"4"}}, nil)
+	NotNil(t, err)
+	Equal(t, map[string]interface{}{"a": "1", "c": map[string]interface{}{"d": map[string]interface{}{"e": "4"}}}, m)
+
+	errs := err.(DecodeErrors)
+	Len(t, errs, 2)
+	True(t, errors.Is(errs["a[b]"], ErrConflictingKeys))
+	True(t, errors.Is(errs["c[d]"], ErrConflictingKeys))
+
+	var fe *FieldError
+	True(t, errors.As(errs["a[b]"], &fe))
+	Equal(t, "2", fe.RawValue())
+
+	d.SetIndexBase(1)
+
+	m = nil
+	err = d.Decode(&m, url.Values{"a[1]": {"x"}, "a[2]": {"y"}, "b[0]": {"z"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidIndex))
+	Equal(t, map[string]interface{}{"a": []interface{}{"x", "y"}}, m)
+}
+
+func TestEncodeDynamic(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]interface{}{
+		"name": "joe",
+		"tags": []interface{}{"a", "b"},
+		"user": map[string]interface{}{
+			"age":     30,
+			"address": map[string]interface{}{"city": "Berlin"},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second", "flags": []interface{}{true}},
+		},
+	}
+
+	encoder := NewEncoder()
+
+	values, err := encoder.Encode(map[string]interface{}{"user": map[string]interface{}{"age": 30}})
+	NoError(t, err)
+	Equal(t, url.Values{"[user][age]": {"30"}}, values)
+
+	encoder.SetBareMapKeys(true)
+
+	values, err = encoder.Clone().Encode(payload)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"name":                {"joe"},
+		"tags[0]":             {"a"},
+		"tags[1]":             {"b"},
+		"user[age]":           {"30"},
+		"user[address][city]": {"Berlin"},
+		"items[0][name]":      {"first"},
+		"items[1][name]":      {"second"},
+		"items[1][flags][0]":  {"true"},
+	}, values)
+
+	pairs, err := encoder.EncodePairs(payload)
+	NoError(t, err)
+	Equal(t, Pair{Key: "items[0][name]", Value: "first"}, pairs[0])
+
+	var decoded map[string]interface{}
+
+	err = NewDecoder[any]().Decode(&decoded, values, nil)
+	NoError(t, err)
+	Equal(t, map[string]interface{}{
+		"name": "joe",
+		"tags": []interface{}{"a", "b"},
+		"user": map[string]interface{}{
+			"age":     "30",
+			"address": map[string]interface{}{"city": "Berlin"},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second", "flags": []interface{}{"true"}},
+		},
+	}, decoded)
+}
@@ -5,18 +5,47 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 type encoder struct {
-	e         *Encoder
-	errs      EncodeErrors
-	columns   []string
-	values    url.Values
-	goValues  map[string]interface{}
-	namespace []byte
+	e           *Encoder
+	funcs       *encodeFuncs
+	structCache *structCacheMap
+	errs        EncodeErrors
+	columns     []string
+	values      url.Values
+	goValues    map[string]interface{}
+	namespace   []byte
+
+	// keys are settings of keys of the call, see Encoder.EncodeWithKeyStyle
+	keys keyFormat
+
+	// emit receives values in encoding order instead of values, map entries are sorted by key
+	emit func(key, value string)
+
+	// stopped is set when emit receiver failed, encoding of remaining fields is skipped
+	stopped bool
+
+	// inFormerly is set while a field is emitted under its former name, nested fields are not dual-written
+	inFormerly bool
+
+	// deepObject is set while a field of deepObject style is encoded, nested names are bracketed
+	deepObject bool
+
+	// shadows are promoted embedded structs being traversed with fields shadowed by the embedding struct
+	shadows []shadow
+
+	// path are structs being encoded to detect cycles of pointers
+	path []visit
+
+	// argument is passed to registered functions, see Encoder.EncodeWithArgument
+	argument         interface{}
+	argumentProvider ArgumentProvider[interface{}]
 }
 
 func (e *encoder) setError(namespace []byte, err error) {
@@ -32,6 +61,14 @@ func (e *encoder) setVal(namespace []byte, v reflect.Value, vals ...string) {
 		e.goValues[string(namespace)] = v.Interface()
 	}
 
+	if e.emit != nil {
+		for _, val := range vals {
+			e.emit(string(namespace), val)
+		}
+
+		return
+	}
+
 	arr, ok := e.values[string(namespace)]
 	if ok {
 		arr = append(arr, vals...)
@@ -45,65 +82,285 @@ func (e *encoder) setVal(namespace []byte, v reflect.Value, vals ...string) {
 	e.values[string(namespace)] = arr
 }
 
-func (e *encoder) traverseStruct(v reflect.Value, namespace []byte, idx int) {
+// traverseStruct encodes fields of a struct, namePrefix is prepended to field names of flattened structs.
+func (e *encoder) traverseStruct(v reflect.Value, namespace []byte, idx int, namePrefix string) {
 	typ := v.Type()
 	l := len(namespace)
 	first := l == 0
 
 	// anonymous structs will still work for caching as the whole definition is stored
 	// including tags
-	s, ok := e.e.structCache.Get(typ)
+	s, ok := e.structCache.Get(e.e.mode, typ, e.e.tagName)
 	if !ok {
-		s = e.e.structCache.parseStruct(e.e.mode, typ, e.e.tagName)
+		s = e.structCache.parseStruct(e.e.mode, typ, e.e.tagName)
+	}
+
+	if !e.enterStruct(v, namespace) {
+		return
+	}
+
+	defer func() {
+		e.path = e.path[:len(e.path)-1]
+	}()
+
+	if s.hasBeforeEncode {
+		if v, ok = e.beforeEncode(v, namespace); !ok {
+			return
+		}
 	}
 
 	for _, f := range s.fields {
+		if e.stopped {
+			return
+		}
+
 		namespace = namespace[:l]
+		fv := v.Field(f.idx)
+
+		if e.e.paramStyle != ParamNative && !f.hasStyle && !f.isAnonymous {
+			f = styleField(f, fv.Type(), e.e.paramStyle, e.e.paramExplode)
+		}
+
+		if e.keys.commaSlices && !f.hasStyle {
+			f = qsCommaField(f, fv.Type())
+		}
+
+		if f.isAnonymous && e.e.embeddedNilZero && fv.Kind() == reflect.Ptr && fv.IsNil() &&
+			fv.Type().Elem().Kind() == reflect.Struct {
+			fv = reflect.New(fv.Type().Elem()).Elem()
+		}
+
+		if f.isFlatten {
+			if ev, kind := ExtractType(fv); kind == reflect.Struct {
+				e.traverseStruct(ev, namespace, idx, namePrefix+f.flattenPrefix)
+			}
 
-		if f.isAnonymous && e.e.embedAnonymous {
+			continue
+		}
+
+		if f.isAnonymous && (f.embed == embedPromote || f.embed == embedDefault && e.e.embedAnonymous) {
 			if f.hasExportedScalar {
-				e.setFieldByType(v.Field(f.idx), namespace, idx, f)
+				pushed := e.pushShadow(f, l, namePrefix)
+
+				e.setFieldByType(fv, namespace, idx, f)
+
+				if pushed {
+					e.shadows = e.shadows[:len(e.shadows)-1]
+				}
 			}
 
 			continue
 		}
 
-		if first {
-			namespace = append(namespace, f.name...)
-		} else {
-			namespace = append(namespace, e.e.namespacePrefix...)
-			namespace = append(namespace, f.name...)
-			namespace = append(namespace, e.e.namespaceSuffix...)
+		skip, embedded := e.conflict(s, f, namespace, namePrefix)
+
+		switch {
+		case skip:
+			continue
+		case embedded != blank:
+			// a shadowed field of a promoted struct is encoded under the name of the embedded field
+			e.setNamedField(fv, e.appendName(namespace, first, embedded), false, f.name, idx, f)
+
+			continue
 		}
 
-		e.setFieldByType(v.Field(f.idx), namespace, idx, f)
+		e.setNamedField(fv, namespace, first, namePrefix+f.name, idx, f)
 
-		if f.sliceSeparator != 0 {
-			ns := string(namespace)
-			if len(e.values[ns]) > 0 {
-				e.values[ns] = []string{strings.Join(e.values[ns], string(f.sliceSeparator))}
-			}
+		// former names are written at the level of the field only, fields nested under a former name
+		// keep their current names instead of emitting every combination of old and new names
+		if e.e.dualWriteFormerly && f.formerly != blank && !e.inFormerly {
+			e.inFormerly = true
+			e.setNamedField(fv, namespace, first, namePrefix+f.formerly, idx, f)
+			e.inFormerly = false
+		}
+	}
+
+	if s.hasRemainder && !e.stopped {
+		e.setRemainder(v.Field(s.remainderIdx))
+	}
+}
+
+// setRemainder merges values of a `remainder` field into the output, keys are emitted as they are
+// in sorted order.
+func (e *encoder) setRemainder(fv reflect.Value) {
+	rest, _ := fv.Interface().(url.Values)
+
+	keys := make([]string, 0, len(rest))
+
+	for k := range rest {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if e.stopped {
+			return
+		}
+
+		if len(rest[k]) > 0 {
+			e.setVal([]byte(k), reflect.ValueOf(rest[k]), rest[k]...)
+		}
+	}
+}
+
+// appendName appends a field name to namespace.
+func (e *encoder) appendName(namespace []byte, first bool, name string) []byte {
+	switch {
+	case first:
+		return append(namespace, name...)
+	case e.deepObject:
+		namespace = append(namespace, '[')
+		namespace = append(namespace, name...)
+
+		return append(namespace, ']')
+	default:
+		namespace = append(namespace, e.keys.namespacePrefix...)
+		namespace = append(namespace, name...)
+
+		return append(namespace, e.keys.namespaceSuffix...)
+	}
+}
+
+// setNamedField encodes struct field under the given name appended to namespace.
+func (e *encoder) setNamedField(fv reflect.Value, namespace []byte, first bool, name string, idx int, f cachedField) {
+	namespace = e.appendName(namespace, first, name)
+
+	if f.isDeepObject && !e.deepObject {
+		e.deepObject = true
+
+		defer func() {
+			e.deepObject = false
+		}()
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		if e.e.nilPointerEmpty && fv.IsNil() && !f.isOmitEmpty && !f.isOmitNil &&
+			(fv.Type().Elem().Kind() != reflect.Struct || isValueStruct(fv.Type().Elem())) {
+			e.setVal(namespace, fv, blank)
+
+			return
+		}
+	} else if e.e.omitZero && !e.hasValue(fv) {
+		return
+	}
+
+	if f.isCheckbox && e.e.omitUnchecked && !isChecked(fv) {
+		return
+	}
+
+	if f.isJoinedObject {
+		e.setJoinedObject(fv, namespace, idx, f)
+
+		return
+	}
+
+	if f.sliceSeparator != blank && e.emit != nil {
+		e.setJoinedField(fv, namespace, idx, f)
+
+		return
+	}
+
+	e.setFieldByType(fv, namespace, idx, f)
+
+	if f.sliceSeparator != blank {
+		ns := string(namespace)
+		if len(e.values[ns]) > 0 {
+			e.values[ns] = []string{strings.Join(e.values[ns], f.sliceSeparator)}
 		}
 	}
 }
 
+// isChecked reports whether a field with `checkbox` tag option is true, a nil pointer is unchecked.
+func isChecked(fv reflect.Value) bool {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return false
+		}
+
+		fv = fv.Elem()
+	}
+
+	return fv.Bool()
+}
+
+// setJoinedField emits values of a field with slice separator joined into one value.
+func (e *encoder) setJoinedField(fv reflect.Value, namespace []byte, idx int, f cachedField) {
+	emit := e.emit
+	ns := string(namespace)
+
+	var vals []string
+
+	e.emit = func(key, value string) {
+		if key == ns {
+			vals = append(vals, value)
+		} else {
+			emit(key, value)
+		}
+	}
+
+	e.setFieldByType(fv, namespace, idx, f)
+
+	e.emit = emit
+
+	if len(vals) > 0 {
+		emit(ns, strings.Join(vals, f.sliceSeparator))
+	}
+}
+
 func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx int, f cachedField) {
 	if idx > -1 && current.Kind() == reflect.Ptr {
-		namespace = append(namespace, '[')
-		namespace = strconv.AppendInt(namespace, int64(idx), 10)
-		namespace = append(namespace, ']')
+		namespace = e.appendIndex(namespace, idx)
 		idx = -2
 	}
 
-	if f.isOmitEmpty && !hasValue(current) {
+	v, kind := ExtractType(current)
+
+	if e.e.emptyCleared && kind != reflect.Invalid && !(kind == reflect.Ptr && v.IsNil()) {
+		if c, ok := asClearable(v); ok {
+			if c.IsCleared() {
+				e.setVal(namespace, v, blank)
+
+				return
+			}
+
+			if !c.IsSet() {
+				return
+			}
+		}
+	}
+
+	if f.isOmitEmpty && !e.hasValue(current) {
 		return
 	}
 
-	v, kind := ExtractType(current)
+	if f.isRaw {
+		e.setRawVal(namespace, v, kind)
+
+		return
+	}
 
-	if e.e.customTypeFuncs != nil {
-		if cf, ok := e.e.customTypeFuncs[v.Type()]; ok {
-			val, err := cf(v.Interface())
+	// omitnil distinguishes a nil pointer, which is omitted, from a pointer to empty value,
+	// which is emitted as empty string
+	if f.isOmitNil && current.Kind() == reflect.Ptr {
+		if current.IsNil() {
+			return
+		}
+
+		if !e.hasValue(v) {
+			e.setVal(namespace, v, blank)
+
+			return
+		}
+	}
+
+	if e.funcs.customTagFuncs != nil && len(f.options) > 0 {
+		if cf, option := e.funcs.tagFunc(f.options); cf != nil {
+			if kind == reflect.Ptr && v.IsNil() {
+				return
+			}
+
+			val, err := cf(v.Interface(), e.funcArgument(v.Type(), option))
 			if err != nil {
 				e.setError(namespace, err)
 
@@ -111,9 +368,7 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 			}
 
 			if idx > -1 {
-				namespace = append(namespace, '[')
-				namespace = strconv.AppendInt(namespace, int64(idx), 10)
-				namespace = append(namespace, ']')
+				namespace = e.appendIndex(namespace, idx)
 			}
 
 			e.setVal(namespace, v, val)
@@ -122,9 +377,9 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		}
 	}
 
-	if f.isExported && len(namespace) > 0 && !(kind == reflect.Ptr && v.IsNil()) {
-		if tu, ok := v.Interface().(encoding.TextMarshaler); ok {
-			val, err := tu.MarshalText()
+	if e.funcs.customTypeFuncs != nil {
+		if cf, ok := e.funcs.customTypeFuncs[v.Type()]; ok {
+			val, err := cf(v.Interface(), e.funcArgument(v.Type(), blank))
 			if err != nil {
 				e.setError(namespace, err)
 
@@ -132,12 +387,66 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 			}
 
 			if idx > -1 {
-				namespace = append(namespace, '[')
-				namespace = strconv.AppendInt(namespace, int64(idx), 10)
-				namespace = append(namespace, ']')
+				namespace = e.appendIndex(namespace, idx)
+			}
+
+			e.setVal(namespace, v, val)
+
+			return
+		}
+	}
+
+	// anonymous fields are skipped to avoid marshaling promoted methods of embedded values,
+	// time.Time has its own default format
+	if kind != reflect.Invalid && !f.isAnonymous && !(kind == reflect.Ptr && v.IsNil()) && v.Type() != timeType {
+		if seq, ok := formValues(v); ok {
+			if idx > -1 {
+				namespace = e.appendIndex(namespace, idx)
+			}
+
+			e.setProduced(namespace, v, seq)
+
+			return
+		}
+
+		if o, ok := asOptional(v); ok {
+			if o.IsCleared() {
+				if idx > -1 {
+					namespace = e.appendIndex(namespace, idx)
+				}
+
+				e.setVal(namespace, v, blank)
+
+				return
+			}
+
+			e.setFieldByType(o.optionalValue(), namespace, idx, f)
+
+			return
+		}
+
+		if tm, ok := asTextMarshaler(v); ok {
+			val, err := tm.MarshalText()
+			if err != nil {
+				e.setError(namespace, err)
+
+				return
+			}
+
+			// elements are indexed as time.Time elements are
+			if idx > -1 {
+				namespace = e.appendIndex(namespace, idx)
 			}
 
 			e.setVal(namespace, v, string(val))
+
+			return
+		}
+
+		if isUUIDType(v.Type()) {
+			e.setVal(namespace, v, v.Interface().(fmt.Stringer).String())
+
+			return
 		}
 	}
 
@@ -149,50 +458,87 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		e.setVal(namespace, v, v.String())
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		e.setVal(namespace, v, strconv.FormatUint(v.Uint(), 10))
+		e.setVal(namespace, v, e.formatUint(v.Uint()))
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		e.setVal(namespace, v, strconv.FormatInt(v.Int(), 10))
+		e.setVal(namespace, v, e.formatInt(v))
 
 	case reflect.Float32:
-		e.setVal(namespace, v, strconv.FormatFloat(v.Float(), 'f', -1, 32))
+		e.setVal(namespace, v, e.formatFloat(v.Float(), 32))
 
 	case reflect.Float64:
-		e.setVal(namespace, v, strconv.FormatFloat(v.Float(), 'f', -1, 64))
+		e.setVal(namespace, v, e.formatFloat(v.Float(), 64))
 
 	case reflect.Bool:
-		e.setVal(namespace, v, strconv.FormatBool(v.Bool()))
+		switch {
+		case !f.hasBoolFormat:
+			e.setVal(namespace, v, strconv.FormatBool(v.Bool()))
+		case v.Bool():
+			e.setVal(namespace, v, f.boolTrue)
+		default:
+			e.setVal(namespace, v, f.boolFalse)
+		}
 
 	case reflect.Slice, reflect.Array:
-		if idx == -1 {
+		// values of scalar elements are written under the key with empty brackets, eg. "tags[]=a&tags[]=b"
+		if e.keys.emptyBrackets && f.sliceSeparator == blank && isScalarType(v.Type().Elem()) {
+			if idx > -1 {
+				namespace = e.appendIndex(namespace, idx)
+			}
+
+			namespace = append(namespace, "[]"...)
+
 			for i := 0; i < v.Len(); i++ {
-				e.setFieldByType(v.Index(i), namespace, i, cachedField{})
+				e.setFieldByType(v.Index(i), namespace, -2, f.elemField())
+			}
+
+			return
+		}
+
+		indexed := kind == reflect.Array && e.e.indexArrays ||
+			f.sliceSeparator == blank && (e.keys.indexSlices || e.keys.objectBrackets)
+
+		if idx == -1 && !indexed {
+			for i := 0; i < v.Len(); i++ {
+				e.setFieldByType(v.Index(i), namespace, i, f.elemField())
+			}
+
+			// elements encoded under the same key are collected together
+			if e.goValues != nil && v.Len() > 0 {
+				if _, ok := e.goValues[string(namespace)]; ok {
+					e.goValues[string(namespace)] = v.Interface()
+				}
 			}
 
 			return
 		}
 
 		if idx > -1 {
-			namespace = append(namespace, '[')
-			namespace = strconv.AppendInt(namespace, int64(idx), 10)
-			namespace = append(namespace, ']')
+			namespace = e.appendIndex(namespace, idx)
 		}
 
-		namespace = append(namespace, '[')
 		l := len(namespace)
 
 		for i := 0; i < v.Len(); i++ {
-			namespace = namespace[:l]
-			namespace = strconv.AppendInt(namespace, int64(i), 10)
-			namespace = append(namespace, ']')
-			e.setFieldByType(v.Index(i), namespace, -2, cachedField{})
+			// elements of objects are written with empty brackets in order, eg. "items[][name]=a"
+			if e.keys.objectBrackets {
+				namespace = append(namespace[:l], "[]"...)
+			} else {
+				namespace = e.appendIndex(namespace[:l], i)
+			}
+
+			e.setFieldByType(v.Index(i), namespace, -2, f.elemField())
 		}
 
 	case reflect.Map:
 		if idx > -1 {
-			namespace = append(namespace, '[')
-			namespace = strconv.AppendInt(namespace, int64(idx), 10)
-			namespace = append(namespace, ']')
+			namespace = e.appendIndex(namespace, idx)
+		}
+
+		if e.emit != nil {
+			e.setSortedMap(v, namespace, f)
+
+			return
 		}
 
 		var (
@@ -201,6 +547,7 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 		)
 
 		l := len(namespace)
+		bare := e.e.bareMapKeys && l == 0 && v.Type() == dynamicMapType
 
 		for _, key := range v.MapKeys() {
 			namespace = namespace[:l]
@@ -209,49 +556,95 @@ func (e *encoder) setFieldByType(current reflect.Value, namespace []byte, idx in
 				continue
 			}
 
-			namespace = append(namespace, '[')
-			namespace = append(namespace, s...)
-			namespace = append(namespace, ']')
+			namespace = appendMapKey(namespace, s, bare)
 
-			e.setFieldByType(v.MapIndex(key), namespace, -2, cachedField{})
+			e.setFieldByType(v.MapIndex(key), namespace, -2, f.elemField())
 		}
 
 	case reflect.Struct:
-		// if we get here then no custom time function declared so use RFC3339 by default
+		// if we get here then no custom time function declared so use RFC3339 or the locale layout
 		if v.Type() == timeType {
 			if idx > -1 {
-				namespace = append(namespace, '[')
-				namespace = strconv.AppendInt(namespace, int64(idx), 10)
-				namespace = append(namespace, ']')
+				namespace = e.appendIndex(namespace, idx)
+			}
+
+			e.setVal(namespace, v, e.formatTime(v.Interface().(time.Time), f))
+
+			return
+		}
+
+		if vt, ok := valueTypes[v.Type()]; ok {
+			if idx > -1 {
+				namespace = e.appendIndex(namespace, idx)
 			}
 
-			e.setVal(namespace, v, v.Interface().(time.Time).Format(time.RFC3339))
+			e.setVal(namespace, v, vt.encode(v.Interface()))
 
 			return
 		}
 
 		if idx == -1 {
-			e.traverseStruct(v, namespace, idx)
+			e.traverseStruct(v, namespace, idx, blank)
 
 			return
 		}
 
 		if idx > -1 {
-			namespace = append(namespace, '[')
-			namespace = strconv.AppendInt(namespace, int64(idx), 10)
-			namespace = append(namespace, ']')
+			namespace = e.appendIndex(namespace, idx)
 		}
 
-		e.traverseStruct(v, namespace, -2)
+		e.traverseStruct(v, namespace, -2, blank)
 	}
 }
 
+// setSortedMap encodes map entries in order of their encoded keys.
+func (e *encoder) setSortedMap(v reflect.Value, namespace []byte, f cachedField) {
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+
+	for _, key := range v.MapKeys() {
+		if s, valid := e.getMapKey(key, namespace); valid {
+			entries = append(entries, entry{key: s, val: v.MapIndex(key)})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	l := len(namespace)
+	bare := e.e.bareMapKeys && l == 0 && v.Type() == dynamicMapType
+
+	for _, en := range entries {
+		namespace = appendMapKey(namespace[:l], en.key, bare)
+
+		e.setFieldByType(en.val, namespace, -2, f.elemField())
+	}
+}
+
+// appendMapKey appends bracketed map key to namespace, keys of top-level map[string]interface{}
+// are not bracketed with bare map keys, eg. "user[name]", see Encoder.SetBareMapKeys.
+func appendMapKey(namespace []byte, key string, bare bool) []byte {
+	if bare {
+		return append(namespace, key...)
+	}
+
+	namespace = append(namespace, '[')
+	namespace = append(namespace, key...)
+
+	return append(namespace, ']')
+}
+
 func (e *encoder) getMapKey(key reflect.Value, namespace []byte) (string, bool) {
 	v, kind := ExtractType(key)
 
-	if e.e.customTypeFuncs != nil {
-		if cf, ok := e.e.customTypeFuncs[v.Type()]; ok {
-			val, err := cf(v.Interface())
+	if e.funcs.customTypeFuncs != nil {
+		if cf, ok := e.funcs.customTypeFuncs[v.Type()]; ok {
+			val, err := cf(v.Interface(), e.funcArgument(v.Type(), blank))
 			if err != nil {
 				e.setError(namespace, err)
 
@@ -262,6 +655,23 @@ func (e *encoder) getMapKey(key reflect.Value, namespace []byte) (string, bool)
 		}
 	}
 
+	if kind == reflect.Struct && v.Type() == timeType {
+		return e.formatTime(v.Interface().(time.Time), cachedField{}), true
+	}
+
+	if kind != reflect.Interface && kind != reflect.Ptr {
+		if tm, ok := asTextMarshaler(v); ok {
+			val, err := tm.MarshalText()
+			if err != nil {
+				e.setError(namespace, err)
+
+				return "", false
+			}
+
+			return string(val), true
+		}
+	}
+
 	switch kind {
 	case reflect.Interface, reflect.Ptr:
 		return "", false
@@ -270,23 +680,221 @@ func (e *encoder) getMapKey(key reflect.Value, namespace []byte) (string, bool)
 		return v.String(), true
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return strconv.FormatUint(v.Uint(), 10), true
+		return e.formatUint(v.Uint()), true
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return strconv.FormatInt(v.Int(), 10), true
+		return e.formatInt(v), true
 
 	case reflect.Float32:
-		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true
+		return e.formatFloat(v.Float(), 32), true
 
 	case reflect.Float64:
-		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+		return e.formatFloat(v.Float(), 64), true
 
 	case reflect.Bool:
 		return strconv.FormatBool(v.Bool()), true
 
 	default:
-		e.setError(namespace, fmt.Errorf("unsupported map key '%v' namespace '%s'", v.String(), namespace))
+		e.setError(namespace, newError(ErrUnsupportedType, "unsupported map key '%v' namespace '%s'", v.String(), namespace))
 
 		return "", false
 	}
 }
+
+// setRawVal emits value of a `raw` field as is, bypassing custom functions and marshalers.
+func (e *encoder) setRawVal(namespace []byte, v reflect.Value, kind reflect.Kind) {
+	switch {
+	case kind == reflect.String:
+		e.setVal(namespace, v, v.String())
+	case kind != reflect.Slice || v.IsNil():
+		return
+	case v.Type().Elem().Kind() == reflect.Uint8:
+		e.setVal(namespace, v, string(v.Bytes()))
+	default:
+		vals := make([]string, v.Len())
+
+		for i := range vals {
+			vals[i] = v.Index(i).String()
+		}
+
+		e.setVal(namespace, v, vals...)
+	}
+}
+
+// hasValue reports whether value is not empty for `omitempty` tag option,
+// using registered IsZeroFunc of its type if any.
+func (e *encoder) hasValue(v reflect.Value) bool {
+	if e.funcs.isZeroFuncs != nil && v.IsValid() && v.CanInterface() {
+		if fn, ok := e.funcs.isZeroFuncs[v.Type()]; ok {
+			return !fn(v.Interface())
+		}
+	}
+
+	return hasValue(v)
+}
+
+// setProduced adds key/value pairs of a producer as they are produced,
+// keys are bracketed after namespace like map keys.
+func (e *encoder) setProduced(namespace []byte, v reflect.Value, seq valuesSeq) {
+	l := len(namespace)
+
+	seq(func(key, value string) bool {
+		namespace = namespace[:l]
+
+		if l == 0 {
+			namespace = append(namespace, key...)
+		} else {
+			namespace = append(namespace, '[')
+			namespace = append(namespace, key...)
+			namespace = append(namespace, ']')
+		}
+
+		e.setVal(namespace, v, value)
+
+		return !e.stopped
+	})
+}
+
+// appendIndex appends element index to namespace in configured syntax.
+func (e *encoder) appendIndex(namespace []byte, idx int) []byte {
+	if e.keys.arrayDots {
+		namespace = append(namespace, '.')
+
+		return strconv.AppendInt(namespace, int64(idx+e.e.indexBase), 10)
+	}
+
+	namespace = append(namespace, '[')
+	namespace = strconv.AppendInt(namespace, int64(idx+e.e.indexBase), 10)
+
+	return append(namespace, ']')
+}
+
+// isScalarType reports whether values of type are encoded as single values, not as structs, maps or slices.
+func isScalarType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return isValueStruct(t) || reflect.PointerTo(t).Implements(textMarshalerType)
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		return false
+	default:
+		return true
+	}
+}
+
+// asTextMarshaler returns encoding.TextMarshaler implemented by value or by pointer to value,
+// values which are not addressable, eg. fields of structs passed by value, are copied, eg. big.Int.
+func asTextMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return tm, true
+	}
+
+	if v.Kind() == reflect.Ptr || !reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		return nil, false
+	}
+
+	if v.CanAddr() {
+		return v.Addr().Interface().(encoding.TextMarshaler), true
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+
+	return ptr.Interface().(encoding.TextMarshaler), true
+}
+
+// asOptional returns the Optional of value, values which are not addressable are copied.
+func asOptional(v reflect.Value) (optional, bool) {
+	if !v.CanInterface() || !reflect.PointerTo(v.Type()).Implements(optionalType) {
+		return nil, false
+	}
+
+	if v.CanAddr() {
+		return v.Addr().Interface().(optional), true
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+
+	return ptr.Interface().(optional), true
+}
+
+// asClearable returns Clearable implemented by value or by pointer to addressable value.
+func asClearable(v reflect.Value) (Clearable, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+
+	if c, ok := v.Interface().(Clearable); ok {
+		return c, true
+	}
+
+	if v.CanAddr() {
+		c, ok := v.Addr().Interface().(Clearable)
+
+		return c, ok
+	}
+
+	return nil, false
+}
+
+// valuesSeq has the shape of iter.Seq2[string, string], iter package is not used
+// to keep support of older Go versions.
+type valuesSeq = func(yield func(key, value string) bool)
+
+var valuesSeqType = reflect.TypeOf(valuesSeq(nil))
+
+// producerTypes caches whether types have a FormValues method.
+var producerTypes sync.Map // map[reflect.Type]bool
+
+// formValues returns key/value pairs producer of a value implementing
+//
+//	FormValues() iter.Seq2[string, string]
+//
+// by value or by pointer to addressable value, any result type of the same underlying type is accepted.
+func formValues(v reflect.Value) (valuesSeq, bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+
+	if seq, ok := callFormValues(v); ok {
+		return seq, true
+	}
+
+	if v.CanAddr() {
+		return callFormValues(v.Addr())
+	}
+
+	return nil, false
+}
+
+func callFormValues(v reflect.Value) (valuesSeq, bool) {
+	typ := v.Type()
+
+	ok, found := producerTypes.Load(typ)
+	if !found {
+		m, has := typ.MethodByName("FormValues")
+		ok = has && typ.Kind() != reflect.Interface && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 &&
+			m.Type.Out(0).ConvertibleTo(valuesSeqType)
+
+		producerTypes.Store(typ, ok)
+	}
+
+	if !ok.(bool) { //nolint:forcetypeassert
+		return nil, false
+	}
+
+	seq := v.MethodByName("FormValues").Call(nil)[0]
+	if seq.IsNil() {
+		return nil, false
+	}
+
+	return seq.Convert(valuesSeqType).Interface().(valuesSeq), true //nolint:forcetypeassert
+}
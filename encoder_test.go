@@ -1,9 +1,13 @@
 package form
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -1410,6 +1414,9 @@ func TestEncoderErrors(t *testing.T) {
 
 	k = ee["Struct"]
 	Equal(t, k.Error(), "unsupported map key '<struct {} Value>' namespace 'Struct'")
+
+	True(t, errors.Is(errs, ErrUnsupportedType))
+	Equal(t, []error{ee["BadMapKey"], ee["Struct"], ee["Time"]}, ee.Unwrap())
 }
 
 func TestEncoderPanicsAndBadValues(t *testing.T) {
@@ -1726,3 +1733,1114 @@ func (e embeddedUnexported) MarshalText() (text []byte, err error) {
 func MakeEmbeddedUnexported() io.Writer {
 	return deeperEmbedded{}
 }
+
+func TestEncoder_RegisterFuncByTag(t *testing.T) {
+	t.Parallel()
+
+	type Request struct {
+		IDs    []int  `form:"ids,csv"`
+		Ptr    *[]int `form:"ptr,csv"`
+		Others []int  `form:"others"`
+	}
+
+	e := NewEncoder()
+	e.RegisterFuncByTag("csv", func(x interface{}) (string, error) {
+		var parts []string
+
+		for _, i := range x.([]int) {
+			parts = append(parts, strconv.Itoa(i))
+		}
+
+		return strings.Join(parts, ","), nil
+	})
+
+	values, err := e.Encode(Request{IDs: []int{1, 2, 3}, Others: []int{6, 7}})
+	NoError(t, err)
+	Equal(t, url.Values{"ids": {"1,2,3"}, "others": {"6", "7"}}, values)
+
+	values, err = e.Encode(Request{Ptr: &[]int{4, 5}})
+	NoError(t, err)
+	Equal(t, url.Values{"ids": {""}, "ptr": {"4,5"}}, values)
+}
+
+func TestEncoder_SetEmbeddedNilMode(t *testing.T) {
+	t.Parallel()
+
+	type A struct {
+		Field string
+		Num   int
+	}
+
+	type B struct {
+		*A
+		Name string
+	}
+
+	encoder := NewEncoder()
+
+	values, err := encoder.Encode(B{Name: "b"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"b"}}, values)
+
+	encoder.SetEmbeddedNilMode(EmbeddedNilZero)
+
+	values, err = encoder.Encode(B{Name: "b"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"b"}, "Field": {""}, "Num": {"0"}}, values)
+
+	values, err = encoder.Encode(B{A: &A{Field: "a", Num: 1}, Name: "b"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"b"}, "Field": {"a"}, "Num": {"1"}}, values)
+
+	encoder.SetAnonymousMode(AnonymousSeparate)
+
+	values, err = encoder.Encode(B{Name: "b"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"b"}, "A.Field": {""}, "A.Num": {"0"}}, values)
+}
+
+type ptrTextMarshaler struct {
+	v string
+}
+
+func (p *ptrTextMarshaler) MarshalText() ([]byte, error) {
+	return []byte("ptr:" + p.v), nil
+}
+
+func TestEncoder_Encode_textMarshalElements(t *testing.T) {
+	t.Parallel()
+
+	var data struct {
+		Value textMarshaler               `form:"value"`
+		List  []textMarshaler             `form:"list"`
+		Addrs []netip.Addr                `form:"addrs"`
+		Map   map[string]textMarshaler    `form:"map"`
+		Ptr   *ptrTextMarshaler           `form:"ptr"`
+		Nil   *textMarshaler              `form:"nil"`
+		Nums  map[string]ptrTextMarshaler `form:"-"`
+	}
+
+	data.Value = "abc"
+	data.List = []textMarshaler{"a", "b"}
+	data.Addrs = []netip.Addr{netip.MustParseAddr("1.2.3.4"), netip.MustParseAddr("::1")}
+	data.Map = map[string]textMarshaler{"k": "v"}
+	data.Ptr = &ptrTextMarshaler{v: "p"}
+
+	u, err := NewEncoder().Encode(data)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"value":    {"marshaled:abc"},
+		"list[0]":  {"marshaled:a"},
+		"list[1]":  {"marshaled:b"},
+		"addrs[0]": {"1.2.3.4"},
+		"addrs[1]": {"::1"},
+		"map[k]":   {"marshaled:v"},
+		"ptr":      {"ptr:p"},
+	}, u)
+}
+
+func TestEncoder_SetKeyMigrationMode(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string `form:"name,formerly=title"`
+	}
+
+	type Test struct {
+		ID    string   `form:"user_id,formerly=uid"`
+		Tags  []string `form:"tags,formerly=labels" collectionFormat:"csv"`
+		Inner Inner    `form:"inner,formerly=nested"`
+		Plain string   `form:"plain"`
+	}
+
+	v := Test{ID: "1", Tags: []string{"a", "b"}, Inner: Inner{Name: "n"}, Plain: "p"}
+
+	e := NewEncoder()
+
+	u, err := e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"user_id":    {"1"},
+		"tags":       {"a,b"},
+		"inner.name": {"n"},
+		"plain":      {"p"},
+	}, u)
+
+	e.SetKeyMigrationMode(KeyMigrationDualWrite)
+
+	u, err = e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"user_id":     {"1"},
+		"uid":         {"1"},
+		"tags":        {"a,b"},
+		"labels":      {"a,b"},
+		"inner.name":  {"n"},
+		"inner.title": {"n"},
+		"nested.name": {"n"},
+		"plain":       {"p"},
+	}, u)
+
+	type Account struct {
+		Owner Test `form:"owner,formerly=user"`
+	}
+
+	// each field is emitted under its current name and once under its former name,
+	// fields nested under a former name keep their current names
+	u, err = e.Encode(Account{Owner: Test{ID: "1", Inner: Inner{Name: "n"}}})
+	NoError(t, err)
+	Equal(t, url.Values{
+		"owner.user_id":     {"1"},
+		"owner.uid":         {"1"},
+		"owner.inner.name":  {"n"},
+		"owner.inner.title": {"n"},
+		"owner.nested.name": {"n"},
+		"owner.plain":       {""},
+		"user.user_id":      {"1"},
+		"user.inner.name":   {"n"},
+		"user.plain":        {""},
+	}, u)
+}
+
+func TestEncoder_RegisterFunc_concurrent(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Value int
+		Time  time.Time
+	}
+
+	e := NewEncoder()
+	v := Test{Value: 1, Time: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			e.RegisterFunc(func(x interface{}) (string, error) {
+				return x.(time.Time).Format("2006-01-02"), nil
+			}, time.Time{})
+			e.RegisterFuncByTag("opt"+strconv.Itoa(i), func(x interface{}) (string, error) {
+				return "", nil
+			})
+			e.RegisterTagNameFunc(func(field reflect.StructField) string {
+				return field.Name
+			})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		u, err := e.Encode(v)
+		NoError(t, err)
+		Equal(t, []string{"1"}, u["Value"])
+	}
+
+	<-done
+
+	u, err := e.Encode(v)
+	NoError(t, err)
+	Equal(t, []string{"2006-01-02"}, u["Time"])
+}
+
+func TestEncoder_Clone(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name  string `json:"name"`
+		Value int
+	}
+
+	e := NewEncoder()
+	e.SetNamespacePrefix("[")
+	e.RegisterFunc(func(x interface{}) (string, error) {
+		return "int:" + strconv.Itoa(x.(int)), nil
+	}, 0)
+
+	c := e.Clone()
+	c.SetTagName("json")
+	c.SetMode(ModeExplicit)
+
+	Equal(t, e.structCache.Load(), c.structCache.Load())
+	Equal(t, "[", c.keys.namespacePrefix)
+
+	v := Test{Name: "n", Value: 1}
+
+	u, err := e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"n"}, "Value": {"int:1"}}, u)
+
+	u, err = c.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{"name": {"n"}}, u)
+}
+
+func TestEncoder_Precompute(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string `json:"name"`
+	}
+
+	type Test struct {
+		Inner *Inner `json:"inner"`
+	}
+
+	e := NewEncoder()
+	e.SetTagName("json")
+	e.Precompute(reflect.TypeOf(Test{}))
+
+	_, ok := e.structCache.Load().Get(ModeImplicit, reflect.TypeOf(Inner{}), "json")
+	True(t, ok)
+
+	u, err := e.Encode(Test{Inner: &Inner{Name: "n"}})
+	NoError(t, err)
+	Equal(t, url.Values{"inner.name": {"n"}}, u)
+}
+
+type optionalString struct {
+	value   string
+	set     bool
+	cleared bool
+}
+
+func (o optionalString) IsSet() bool     { return o.set }
+func (o optionalString) IsCleared() bool { return o.cleared }
+
+func (o optionalString) MarshalText() ([]byte, error) {
+	return []byte(o.value), nil
+}
+
+func TestEncoder_SetClearedMode(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name    optionalString  `form:"name"`
+		Bio     optionalString  `form:"bio"`
+		Nick    optionalString  `form:"nick"`
+		Ptr     *optionalString `form:"ptr"`
+		NilPtr  *optionalString `form:"nil_ptr"`
+		Regular string          `form:"regular"`
+	}
+
+	v := Test{
+		Name:    optionalString{value: "n", set: true},
+		Bio:     optionalString{set: true, cleared: true},
+		Ptr:     &optionalString{set: true, cleared: true},
+		Regular: "r",
+	}
+
+	e := NewEncoder()
+
+	u, err := e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{"name": {"n"}, "bio": {""}, "nick": {""}, "ptr": {""}, "regular": {"r"}}, u)
+
+	e.SetClearedMode(ClearedEmpty)
+
+	u, err = e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{"name": {"n"}, "bio": {""}, "ptr": {""}, "regular": {"r"}}, u)
+}
+
+func TestEncoder_Encode_mapKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Enums map[textMarshaler]int `form:"enums"`
+		Addrs map[netip.Addr]string `form:"addrs"`
+		Times map[time.Time]string  `form:"times"`
+	}
+
+	u, err := NewEncoder().Encode(Test{
+		Enums: map[textMarshaler]int{"x": 2},
+		Addrs: map[netip.Addr]string{netip.MustParseAddr("10.0.0.1"): "b"},
+		Times: map[time.Time]string{time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC): "c"},
+	})
+	NoError(t, err)
+	Equal(t, url.Values{
+		"enums[marshaled:x]":          {"2"},
+		"addrs[10.0.0.1]":             {"b"},
+		"times[2006-01-02T15:04:05Z]": {"c"},
+	}, u)
+}
+
+func TestEncoder_SetIndexBase(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string
+	}
+
+	type Test struct {
+		Items []Item
+		Ptrs  []*int
+		Plain []int
+	}
+
+	i := 1
+	v := Test{Items: []Item{{Name: "a"}, {Name: "b"}}, Ptrs: []*int{nil, &i}, Plain: []int{1, 2}}
+
+	e := NewEncoder()
+	e.SetIndexBase(1)
+
+	u, err := e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"Items[1].Name": {"a"},
+		"Items[2].Name": {"b"},
+		"Ptrs[2]":       {"1"},
+		"Plain":         {"1", "2"},
+	}, u)
+
+	d := NewDecoder[any]()
+	d.SetIndexBase(1)
+
+	var decoded Test
+	err = d.Decode(&decoded, u, nil)
+	NoError(t, err)
+	Equal(t, v, decoded)
+}
+
+func TestEncoder_SetArraySyntax(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string `form:"name"`
+	}
+
+	type Test struct {
+		Items  []Item         `form:"items"`
+		Matrix [][]int        `form:"matrix"`
+		Map    map[string]int `form:"map"`
+		Plain  []int          `form:"plain"`
+	}
+
+	v := Test{
+		Items:  []Item{{Name: "a"}, {Name: "b"}},
+		Matrix: [][]int{{1, 2}},
+		Map:    map[string]int{"k": 1},
+		Plain:  []int{1, 2},
+	}
+
+	e := NewEncoder()
+	e.SetArraySyntax(ArrayDots)
+
+	u, err := e.Encode(v)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"items.0.name": {"a"},
+		"items.1.name": {"b"},
+		"matrix.0.0":   {"1"},
+		"matrix.0.1":   {"2"},
+		"map[k]":       {"1"},
+		"plain":        {"1", "2"},
+	}, u)
+
+	d := NewDecoder[any]()
+	d.SetArraySyntax(ArrayDots)
+
+	var decoded Test
+	err = d.Decode(&decoded, u, nil)
+	NoError(t, err)
+	Equal(t, v, decoded)
+}
+
+// testProducerSeq has the shape of iter.Seq2[string, string].
+type testProducerSeq func(yield func(string, string) bool)
+
+type testProducer struct {
+	n int
+}
+
+func (p testProducer) FormValues() testProducerSeq {
+	return func(yield func(string, string) bool) {
+		for i := 0; i < p.n; i++ {
+			if !yield("id"+strconv.Itoa(i), strconv.Itoa(i*10)) {
+				return
+			}
+		}
+	}
+}
+
+type testPtrProducer struct{}
+
+func (p *testPtrProducer) FormValues() func(yield func(string, string) bool) {
+	return func(yield func(string, string) bool) {
+		yield("a", "1")
+	}
+}
+
+func TestEncoderProducer(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name  string
+		Rows  testProducer
+		Ptr   testPtrProducer
+		Items []testProducer
+	}
+
+	encoder := NewEncoder()
+
+	values, err := encoder.Encode(Test{Name: "n", Rows: testProducer{n: 2}, Items: []testProducer{{n: 1}}})
+	NoError(t, err)
+	Equal(t, url.Values{
+		"Name":          {"n"},
+		"Rows[id0]":     {"0"},
+		"Rows[id1]":     {"10"},
+		"Items[0][id0]": {"0"},
+	}, values)
+
+	values, err = encoder.Encode(&Test{Name: "n"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"n"}, "Ptr[a]": {"1"}}, values)
+
+	values, err = encoder.Encode(testProducer{n: 3})
+	NoError(t, err)
+	Equal(t, url.Values{"id0": {"0"}, "id1": {"10"}, "id2": {"20"}}, values)
+
+	var buf strings.Builder
+
+	err = encoder.EncodeTo(&buf, testProducer{n: 3})
+	NoError(t, err)
+	Equal(t, "id0=0&id1=10&id2=20", buf.String())
+
+	buf.Reset()
+
+	err = encoder.EncodeTo(&buf, struct{ B, A string }{B: "b c", A: "a"})
+	NoError(t, err)
+	Equal(t, "B=b+c&A=a", buf.String())
+
+	err = encoder.EncodeTo(&buf, nil)
+	NotNil(t, err)
+}
+
+func TestEncoderRegisterIsZeroFunc(t *testing.T) {
+	t.Parallel()
+
+	type Money struct {
+		Amount   int
+		Currency string
+		Tags     []string
+	}
+
+	type Test struct {
+		Price    Money `form:"price,omitempty"`
+		Discount Money `form:"discount,omitempty"`
+		Name     string
+	}
+
+	encoder := NewEncoder()
+
+	test := Test{Price: Money{Amount: 5}, Name: "n"}
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"price.Amount":      {"5"},
+		"price.Currency":    {""},
+		"discount.Amount":   {"0"},
+		"discount.Currency": {""},
+		"Name":              {"n"},
+	}, values)
+
+	encoder.RegisterIsZeroFunc(func(x interface{}) bool {
+		m := x.(Money)
+
+		return m.Amount == 0 && m.Currency == "" && len(m.Tags) == 0
+	}, Money{})
+
+	values, err = encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"price.Amount": {"5"}, "price.Currency": {""}, "Name": {"n"}}, values)
+}
+
+func TestEncoderOmitNil(t *testing.T) {
+	t.Parallel()
+
+	type Patch struct {
+		Name  *string `form:"name,omitnil"`
+		Age   *int    `form:"age,omitnil"`
+		Email *string `form:"email,omitnil"`
+		Plain *int    `form:"plain"`
+	}
+
+	name := "joe"
+	age := 0
+	empty := ""
+
+	encoder := NewEncoder()
+
+	values, err := encoder.Encode(Patch{Name: &name, Age: &age, Plain: &age})
+	NoError(t, err)
+	Equal(t, url.Values{"name": {"joe"}, "age": {""}, "plain": {"0"}}, values)
+
+	values, err = encoder.Encode(Patch{Email: &empty})
+	NoError(t, err)
+	Equal(t, url.Values{"email": {""}}, values)
+}
+
+func TestEncoderEncodePairs(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Z string
+		A string
+	}
+
+	type Test struct {
+		Zeta   string
+		Alpha  int
+		Tags   []string
+		IDs    []int `form:"ids" collectionFormat:"csv"`
+		Attrs  map[string]string
+		Inner  Inner
+		Nested []Inner
+	}
+
+	test := Test{
+		Zeta:   "z",
+		Alpha:  1,
+		Tags:   []string{"b", "a"},
+		IDs:    []int{3, 1},
+		Attrs:  map[string]string{"y": "2", "x": "1", "w": "0"},
+		Inner:  Inner{Z: "iz", A: "ia"},
+		Nested: []Inner{{Z: "n0"}},
+	}
+
+	encoder := NewEncoder()
+
+	for i := 0; i < 5; i++ {
+		pairs, err := encoder.EncodePairs(test)
+		NoError(t, err)
+		Equal(t, []Pair{
+			{Key: "Zeta", Value: "z"},
+			{Key: "Alpha", Value: "1"},
+			{Key: "Tags", Value: "b"},
+			{Key: "Tags", Value: "a"},
+			{Key: "ids", Value: "3,1"},
+			{Key: "Attrs[w]", Value: "0"},
+			{Key: "Attrs[x]", Value: "1"},
+			{Key: "Attrs[y]", Value: "2"},
+			{Key: "Inner.Z", Value: "iz"},
+			{Key: "Inner.A", Value: "ia"},
+			{Key: "Nested[0].Z", Value: "n0"},
+			{Key: "Nested[0].A", Value: ""},
+		}, pairs)
+	}
+
+	pairs, err := encoder.EncodePairs(map[int]string{2: "b", 1: "a"})
+	NoError(t, err)
+	Equal(t, []Pair{{Key: "[1]", Value: "a"}, {Key: "[2]", Value: "b"}}, pairs)
+
+	_, err = encoder.EncodePairs(nil)
+	NotNil(t, err)
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, []string{"3,1"}, values["ids"])
+}
+
+func TestEncoderEncodeFunc(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		A string
+		B []int
+		C map[string]int
+	}
+
+	test := Test{A: "a b", B: []int{1, 2}, C: map[string]int{"y": 2, "x": 1}}
+
+	encoder := NewEncoder()
+
+	var keys []string
+
+	err := encoder.EncodeFunc(test, func(key, value string) error {
+		keys = append(keys, key+"="+value)
+
+		return nil
+	})
+	NoError(t, err)
+	Equal(t, []string{"A=a b", "B=1", "B=2", "C[x]=1", "C[y]=2"}, keys)
+
+	errStop := errors.New("stop")
+	keys = nil
+
+	err = encoder.EncodeFunc(test, func(key, value string) error {
+		keys = append(keys, key)
+
+		if len(keys) == 2 {
+			return errStop
+		}
+
+		return nil
+	})
+	Equal(t, errStop, err)
+	Equal(t, []string{"A", "B"}, keys)
+
+	err = encoder.EncodeFunc(nil, func(key, value string) error { return nil })
+	NotNil(t, err)
+}
+
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, io.ErrShortWrite
+	}
+
+	w.n--
+
+	return len(p), nil
+}
+
+func TestEncoderEncodeTo(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Name string
+	}
+
+	type Test struct {
+		ID    int
+		Items []Inner
+		Tags  map[string]bool
+	}
+
+	test := Test{ID: 1, Items: []Inner{{Name: "a&b"}, {Name: "c"}}, Tags: map[string]bool{"z": true, "a": false}}
+
+	encoder := NewEncoder()
+
+	var buf strings.Builder
+
+	err := encoder.EncodeTo(&buf, test)
+	NoError(t, err)
+	Equal(t, "ID=1&Items%5B0%5D.Name=a%26b&Items%5B1%5D.Name=c&Tags%5Ba%5D=false&Tags%5Bz%5D=true", buf.String())
+
+	w := &failingWriter{n: 2}
+
+	err = encoder.EncodeTo(w, test)
+	Equal(t, io.ErrShortWrite, err)
+
+	err = encoder.EncodeTo(&failingWriter{n: 1}, testProducer{n: 100})
+	Equal(t, io.ErrShortWrite, err)
+}
+
+func TestEncoderRawField(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Payload json.RawMessage `form:"payload,raw"`
+		Bytes   []byte          `form:"bytes"`
+		Query   string          `form:"query,raw"`
+		Parts   []string        `form:"parts,raw"`
+		Missing *string         `form:"missing,raw"`
+	}
+
+	encoder := NewEncoder()
+	encoder.RegisterFunc(func(x interface{}) (string, error) {
+		return "converted", nil
+	}, "")
+
+	values, err := encoder.Encode(Test{
+		Payload: json.RawMessage(`{"a":1}`),
+		Bytes:   []byte("ab"),
+		Query:   "a=1&b=2",
+		Parts:   []string{"x", "y"},
+	})
+	NoError(t, err)
+	Equal(t, url.Values{
+		"payload": {`{"a":1}`},
+		"bytes":   {"97", "98"},
+		"query":   {"a=1&b=2"},
+		"parts":   {"x", "y"},
+	}, values)
+}
+
+func TestEncoderEmbeddedModes(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		ID int
+	}
+
+	type Meta struct {
+		Source string
+	}
+
+	type Address struct {
+		City string
+	}
+
+	type Shipping struct {
+		City string
+	}
+
+	type Order struct {
+		Base      `form:",promote"`
+		Meta      `form:",namespace"`
+		Address   `form:",prefix=billing_"`
+		*Shipping `form:",prefix=shipping_"`
+	}
+
+	o := Order{
+		Base:    Base{ID: 1},
+		Meta:    Meta{Source: "web"},
+		Address: Address{City: "Berlin"},
+	}
+
+	encoder := NewEncoder()
+
+	values, err := encoder.Encode(o)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"ID":           {"1"},
+		"Meta.Source":  {"web"},
+		"billing_City": {"Berlin"},
+	}, values)
+
+	encoder.SetAnonymousMode(AnonymousSeparate)
+	o.Shipping = &Shipping{City: "Paris"}
+
+	values, err = encoder.Encode(o)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"ID":            {"1"},
+		"Meta.Source":   {"web"},
+		"billing_City":  {"Berlin"},
+		"shipping_City": {"Paris"},
+	}, values)
+}
+
+func TestEncoderFlatten(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		Street string `form:"street"`
+		City   string `form:"city,omitempty"`
+	}
+
+	type Customer struct {
+		Name    string   `form:"name"`
+		Addr    Address  `form:"addr,flatten"`
+		Billing *Address `form:",prefix=billing_"`
+		Unset   *Address `form:",prefix=unset_"`
+	}
+
+	c := Customer{
+		Name:    "joe",
+		Addr:    Address{Street: "Main", City: "Berlin"},
+		Billing: &Address{Street: "High"},
+	}
+
+	values, err := NewEncoder().Encode(c)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"name":           {"joe"},
+		"addr_street":    {"Main"},
+		"addr_city":      {"Berlin"},
+		"billing_street": {"High"},
+	}, values)
+}
+
+func TestEncoderNilPointerAndZeroValueModes(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		Value string
+	}
+
+	type Test struct {
+		Name    *string
+		Age     *int    `form:",omitempty"`
+		Nick    *string `form:",omitnil"`
+		When    *time.Time
+		Inner   *Inner
+		Count   int
+		Label   string
+		Tags    []string
+		Present string
+	}
+
+	test := Test{Present: "yes"}
+
+	encoder := NewEncoder()
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"Count": {"0"}, "Label": {""}, "Present": {"yes"}}, values)
+
+	encoder.SetNilPointerMode(NilPointerEmpty)
+
+	values, err = encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {""}, "When": {""}, "Count": {"0"}, "Label": {""}, "Present": {"yes"}}, values)
+
+	encoder.SetNilPointerMode(NilPointerSkip)
+	encoder.SetZeroValueMode(ZeroValueOmit)
+
+	zero := 0
+	test.Age = &zero
+
+	// pointers are not affected, a pointer to zero value is emitted
+	values, err = encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"Age": {"0"}, "Present": {"yes"}}, values)
+
+	values, err = encoder.Encode([]int{0, 1})
+	NoError(t, err)
+	Equal(t, url.Values{"": {"0", "1"}}, values)
+
+	encoder.SetNilPointerMode(NilPointerEmpty)
+
+	// modes are kept by clones
+	values, err = encoder.Clone().Encode(Test{Label: "x"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {""}, "When": {""}, "Label": {"x"}}, values)
+}
+
+func TestEncoderZeroValueOmitUnexportedEmbedded(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string `form:"city"`
+		Zip  int    `form:"zip"`
+	}
+
+	type Test struct {
+		address `form:"addr,namespace"`
+		Name    string
+	}
+
+	encoder := NewEncoder()
+	encoder.SetZeroValueMode(ZeroValueOmit)
+
+	values, err := encoder.Encode(Test{address: address{City: "Berlin"}, Name: "joe"})
+	NoError(t, err)
+	Equal(t, url.Values{"addr.city": {"Berlin"}, "Name": {"joe"}}, values)
+
+	values, err = encoder.Encode(Test{Name: "joe"})
+	NoError(t, err)
+	Equal(t, url.Values{"Name": {"joe"}}, values)
+}
+
+func TestEncoderBoolFormat(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Newsletter bool  `form:"newsletter,bool=on|off"`
+		Terms      *bool `form:"terms,bool=yes|"`
+		Plain      bool  `form:"plain"`
+		Flags      []bool
+	}
+
+	no := false
+
+	values, err := NewEncoder().Encode(Test{Newsletter: true, Terms: &no, Flags: []bool{true}})
+	NoError(t, err)
+	Equal(t, url.Values{"newsletter": {"on"}, "terms": {""}, "plain": {"false"}, "Flags": {"true"}}, values)
+
+	type Elements struct {
+		Days    []bool          `form:"days,bool=Y|N"`
+		Week    [2]bool         `form:"week,bool=Y|N"`
+		Matrix  [][]bool        `form:"matrix,bool=1|0"`
+		Options map[string]bool `form:"options,bool=on|off"`
+	}
+
+	test := Elements{
+		Days:    []bool{true, false},
+		Week:    [2]bool{false, true},
+		Matrix:  [][]bool{{true, false}},
+		Options: map[string]bool{"a": true, "b": false},
+	}
+
+	expected := url.Values{
+		"days":         {"Y", "N"},
+		"week":         {"N", "Y"},
+		"matrix[0][0]": {"1"},
+		"matrix[0][1]": {"0"},
+		"options[a]":   {"on"},
+		"options[b]":   {"off"},
+	}
+
+	values, err = NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, expected, values)
+
+	pairs, err := NewEncoder().EncodePairs(test)
+	NoError(t, err)
+	Equal(t, 8, len(pairs))
+	Equal(t, Pair{Key: "options[b]", Value: "off"}, pairs[7])
+}
+
+func TestEncoderCheckboxMode(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Newsletter bool  `form:"newsletter,checkbox"`
+		Terms      *bool `form:"terms,checkbox"`
+		Alerts     bool  `form:"alerts,checkbox"`
+		Plain      bool  `form:"plain"`
+	}
+
+	no := false
+	test := Test{Terms: &no, Alerts: true}
+
+	e := NewEncoder()
+
+	values, err := e.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"newsletter": {"false"}, "terms": {"false"}, "alerts": {"true"}, "plain": {"false"}}, values)
+
+	e.SetCheckboxMode(CheckboxOmitUnchecked)
+
+	values, err = e.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"alerts": {"true"}, "plain": {"false"}}, values)
+
+	values, err = e.Clone().Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"alerts": {"true"}, "plain": {"false"}}, values)
+}
+
+func TestEncodeCollectPooled(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name string `form:"name"`
+	}
+
+	encoder := NewEncoder()
+	goValues := make(map[string]interface{})
+
+	_, err := encoder.Encode(Test{Name: "a"}, goValues)
+	NoError(t, err)
+	Equal(t, map[string]interface{}{"name": "a"}, goValues)
+
+	// later encodings without a map do not collect into the map of an earlier one
+	_, err = encoder.Encode(Test{Name: "b"})
+	NoError(t, err)
+	Equal(t, map[string]interface{}{"name": "a"}, goValues)
+}
+
+func TestEncoder_SetKeyStyleRails(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+	}
+
+	type User struct {
+		Name      string    `form:"name"`
+		Address   Address   `form:"address"`
+		Tags      []string  `form:"tags"`
+		Addresses []Address `form:"addresses"`
+		Scores    []*int    `form:"scores"`
+	}
+
+	type Test struct {
+		User User `form:"user"`
+	}
+
+	score := 3
+	test := Test{User: User{
+		Name:      "joe",
+		Address:   Address{City: "Paris"},
+		Tags:      []string{"a", "b"},
+		Addresses: []Address{{City: "Rome"}, {City: "Oslo"}},
+		Scores:    []*int{&score},
+	}}
+
+	encoder := NewEncoder()
+	encoder.SetKeyStyle(StyleRails)
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"user[name]":               {"joe"},
+		"user[address][city]":      {"Paris"},
+		"user[tags][]":             {"a", "b"},
+		"user[addresses][0][city]": {"Rome"},
+		"user[addresses][1][city]": {"Oslo"},
+		"user[scores][]":           {"3"},
+	}, values)
+
+	decoder := NewDecoder[any]()
+	decoder.SetKeyStyle(StyleRails)
+
+	var decoded Test
+
+	err = decoder.Decode(&decoded, values, nil)
+	NoError(t, err)
+	Equal(t, test, decoded)
+
+	encoder.SetKeyStyle(StyleDots)
+
+	values, err = encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, []string{"a", "b"}, values["user.tags"])
+}
+
+func TestEncoder_EncodeWithKeyStyle(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string `form:"name"`
+	}
+
+	type Test struct {
+		Tags  []string `form:"tags"`
+		Items []Item   `form:"items"`
+	}
+
+	values := url.Values{
+		"order[tags][]":         {"a", "b"},
+		"order[items][][name]":  {"x"},
+		"order[items][1][name]": {"y"},
+	}
+
+	// decoded from one dialect and encoded in another when proxying
+	decoder := NewDecoder[any]()
+	decoder.SetKeyStyle(StyleRails)
+
+	var test struct {
+		Order Test `form:"order"`
+	}
+
+	err := decoder.Decode(&test, values, nil)
+	NoError(t, err)
+
+	encoder := NewEncoder()
+
+	encoded, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"order.tags":          {"a", "b"},
+		"order.items[0].name": {"x"},
+		"order.items[1].name": {"y"},
+	}, encoded)
+
+	encoded, err = encoder.EncodeWithKeyStyle(test, StyleQS)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"order[tags][0]":        {"a"},
+		"order[tags][1]":        {"b"},
+		"order[items][0][name]": {"x"},
+		"order[items][1][name]": {"y"},
+	}, encoded)
+
+	// the encoder key style is not affected by the call
+	encoded, err = encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, []string{"a", "b"}, encoded["order.tags"])
+
+	encoder.SetKeyStyle(StyleRails)
+
+	encoded, err = encoder.EncodeWithKeyStyle(test, StyleDots)
+	NoError(t, err)
+	Equal(t, []string{"x"}, encoded["order.items[0].name"])
+}
@@ -0,0 +1,56 @@
+package form
+
+import (
+	"sort"
+	"strings"
+)
+
+// NewEnumFunc returns functions converting enum values by their names, values outside of the set
+// are rejected with ErrInvalidEnum listing allowed names. Values with several names are encoded
+// with the first name in sorted order.
+//
+// eg.
+//
+//	decodeFn, encodeFn := form.NewEnumFunc[any](map[string]Color{"red": Red, "green": Green})
+//	decoder.RegisterFunc(decodeFn, reflect.TypeOf(Color(0)))
+//	encoder.RegisterFunc(encodeFn, Color(0))
+func NewEnumFunc[A any, T comparable](values map[string]T) (DecodeFunc[A], EncodeFunc) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	byValue := make(map[T]string, len(values))
+
+	for _, name := range names {
+		if _, ok := byValue[values[name]]; !ok {
+			byValue[values[name]] = name
+		}
+	}
+
+	allowed := "'" + strings.Join(names, "', '") + "'"
+
+	decodeFn := func(s string, _ A) (interface{}, error) {
+		v, ok := values[s]
+		if !ok {
+			return nil, newError(ErrInvalidEnum, "invalid value '%s', allowed values are %s", s, allowed)
+		}
+
+		return v, nil
+	}
+
+	encodeFn := func(x interface{}) (string, error) {
+		v, _ := x.(T)
+
+		name, ok := byValue[v]
+		if !ok {
+			return "", newError(ErrInvalidEnum, "invalid value '%v' of type '%T', allowed values are %s", x, x, allowed)
+		}
+
+		return name, nil
+	}
+
+	return decodeFn, encodeFn
+}
@@ -0,0 +1,71 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type testColor int
+
+const (
+	testRed testColor = iota + 1
+	testGreen
+	testBlue
+)
+
+func TestNewEnumFunc(t *testing.T) {
+	t.Parallel()
+
+	decodeFn, encodeFn := NewEnumFunc[any](map[string]testColor{
+		"red":   testRed,
+		"green": testGreen,
+		"verde": testGreen,
+	})
+
+	type Test struct {
+		Color   testColor            `form:"color"`
+		Palette []testColor          `form:"palette"`
+		Ptr     *testColor           `form:"ptr"`
+		ByName  map[string]testColor `form:"by_name"`
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFunc(decodeFn, reflect.TypeOf(testColor(0)))
+
+	e := NewEncoder()
+	e.RegisterFunc(encodeFn, testColor(0))
+
+	var test Test
+
+	err := d.Decode(&test, url.Values{"color": {"red"}, "palette": {"green", "verde"}, "ptr": {"red"}, "by_name[a]": {"green"}}, nil)
+	NoError(t, err)
+	Equal(t, testRed, test.Color)
+	Equal(t, []testColor{testGreen, testGreen}, test.Palette)
+	Equal(t, testRed, *test.Ptr)
+	Equal(t, map[string]testColor{"a": testGreen}, test.ByName)
+
+	values, err := e.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"color":      {"red"},
+		"palette[0]": {"green"},
+		"palette[1]": {"green"},
+		"ptr":        {"red"},
+		"by_name[a]": {"green"},
+	}, values)
+
+	err = d.Decode(&test, url.Values{"color": {"purple"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	True(t, errors.Is(errs["color"], ErrInvalidEnum))
+	Contains(t, errs["color"].Error(), "invalid value 'purple', allowed values are 'green', 'red', 'verde'")
+
+	_, err = e.Encode(Test{Color: testBlue})
+	NotNil(t, err)
+	True(t, errors.Is(err.(EncodeErrors)["color"], ErrInvalidEnum))
+}
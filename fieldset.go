@@ -0,0 +1,29 @@
+package form
+
+import "sort"
+
+// FieldSet is the set of namespaces of fields that received at least one value, see Decoder.DecodeFieldSet.
+//
+// Parents of set fields are included, eg. "Address" along with "Address.City", as are indexed elements,
+// eg. "Users[0].Name" and "Users".
+type FieldSet map[string]struct{}
+
+// Has reports whether the field of a namespace received a value.
+func (s FieldSet) Has(namespace string) bool {
+	_, ok := s[namespace]
+
+	return ok
+}
+
+// Namespaces returns the namespaces in the set in sorted order.
+func (s FieldSet) Namespaces() []string {
+	namespaces := make([]string, 0, len(s))
+
+	for ns := range s {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces
+}
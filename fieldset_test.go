@@ -0,0 +1,49 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDecoder_DecodeFieldSet(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+		Zip  string `form:"zip"`
+	}
+
+	type Patch struct {
+		Name    string    `form:"name"`
+		Age     int       `form:"age"`
+		Admin   bool      `form:"admin"`
+		Address *Address  `form:"address"`
+		Others  []Address `form:"others"`
+	}
+
+	var patch Patch
+
+	fields, err := NewDecoder[any]().DecodeFieldSet(&patch, url.Values{
+		"age":            {"0"},
+		"name":           {""},
+		"address.city":   {"Berlin"},
+		"others[1].zip":  {"10115"},
+		"unknown":        {"x"},
+		"others[x].city": {"Paris"},
+	}, nil)
+	NotNil(t, err)
+
+	True(t, fields.Has("age"))
+	True(t, fields.Has("name"))
+	False(t, fields.Has("admin"))
+	False(t, fields.Has("unknown"))
+	False(t, fields.Has("address.zip"))
+	Equal(t, []string{"address", "address.city", "age", "name", "others", "others[1].zip"}, fields.Namespaces())
+	Equal(t, "Berlin", patch.Address.City)
+
+	fields, err = NewDecoder[any]().DecodeFieldSet(&patch, url.Values{}, nil)
+	NoError(t, err)
+	Equal(t, 0, len(fields))
+}
@@ -1,7 +1,11 @@
 package form
 
 import (
+	"encoding"
+	"io"
+	"net/url"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -12,7 +16,18 @@ const (
 	errorText = " ERROR:"
 )
 
-var timeType = reflect.TypeOf(time.Time{})
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	readerType          = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	stringsReaderType   = reflect.TypeOf((*strings.Reader)(nil))
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	optionalType        = reflect.TypeOf((*optional)(nil)).Elem()
+	dynamicMapType      = reflect.TypeOf(map[string]interface{}(nil))
+	urlValuesType       = reflect.TypeOf(url.Values(nil))
+)
 
 // Mode specifies which mode the form decoder is to run.
 type Mode uint8
@@ -45,3 +60,298 @@ const (
 	//     encode results: url.Values{"Field":[]string{"B FieldVal"}, "A.Field":[]string{"A FieldVal"}}
 	AnonymousSeparate
 )
+
+// ConflictMode specifies how a field of an embedded struct is treated when a field of the embedding struct
+// has the same name.
+type ConflictMode uint8
+
+const (
+	// ConflictShared decodes both fields from the name and encodes both under it
+	// eg. type A struct { Field string }
+	//     type B struct { A, Field string }
+	//     encode results: url.Values{"Field":[]string{"A FieldVal", "B FieldVal"}}
+	ConflictShared ConflictMode = iota
+
+	// ConflictOuter uses the name for the field of the embedding struct only, like Go field promotion,
+	// the embedded field is decoded from its namespaced name, eg. "A.Field", and not encoded.
+	ConflictOuter
+
+	// ConflictInner uses the name for the field of the embedded struct only, the field of the embedding
+	// struct is neither decoded nor encoded.
+	ConflictInner
+
+	// ConflictError reports ErrAmbiguousField for decoded values of the name and for encoded structs
+	// with conflicting fields.
+	ConflictError
+
+	// ConflictNamespace uses the name for the field of the embedding struct and the namespaced name for
+	// the embedded field, eg. "A.Field", in both the decoder and encoder.
+	ConflictNamespace
+)
+
+// EmbeddedNilMode specifies how nil pointers to embedded structs are encoded.
+type EmbeddedNilMode uint8
+
+const (
+	// EmbeddedNilSkip skips the subtree of a nil embedded struct pointer.
+	EmbeddedNilSkip EmbeddedNilMode = iota
+
+	// EmbeddedNilZero encodes the subtree of a nil embedded struct pointer with zero values
+	// eg. type A struct { Field string }
+	//     type B struct { *A }
+	//     encode results: url.Values{"Field":[]string{""}}
+	EmbeddedNilZero
+)
+
+// NilPointerMode specifies how the encoder handles nil pointer fields.
+type NilPointerMode uint8
+
+const (
+	// NilPointerSkip omits nil pointer fields.
+	NilPointerSkip NilPointerMode = iota
+
+	// NilPointerEmpty emits nil pointers to values other than structs as empty strings,
+	// fields with `omitempty` or `omitnil` are still omitted
+	// eg. type A struct { Name *string }
+	//     encode results: url.Values{"Name":[]string{""}}
+	NilPointerEmpty
+)
+
+// ZeroValueMode specifies how the encoder handles non-pointer fields with zero values.
+type ZeroValueMode uint8
+
+const (
+	// ZeroValueKeep emits zero values unless fields have `omitempty` tag option.
+	ZeroValueKeep ZeroValueMode = iota
+
+	// ZeroValueOmit omits zero values of all non-pointer fields as if they had `omitempty` tag option.
+	ZeroValueOmit
+)
+
+// CheckboxMode specifies how the encoder handles false values of fields with `checkbox` tag option.
+type CheckboxMode uint8
+
+const (
+	// CheckboxKeep emits unchecked checkboxes as "false".
+	CheckboxKeep CheckboxMode = iota
+
+	// CheckboxOmitUnchecked omits unchecked checkboxes as browsers do when submitting a form.
+	CheckboxOmitUnchecked
+)
+
+// KeyMigrationMode specifies how fields renamed with `formerly` tag option are encoded.
+type KeyMigrationMode uint8
+
+const (
+	// KeyMigrationOff emits fields under their current name only.
+	KeyMigrationOff KeyMigrationMode = iota
+
+	// KeyMigrationDualWrite emits fields under both current and former names
+	// eg. type A struct { ID string `form:"user_id,formerly=uid"` }
+	//     encode results: url.Values{"user_id":[]string{"1"}, "uid":[]string{"1"}}
+	KeyMigrationDualWrite
+)
+
+// UTF8Mode specifies how the decoder handles string values with invalid UTF-8 sequences.
+type UTF8Mode uint8
+
+const (
+	// UTF8Accept assigns values as is.
+	UTF8Accept UTF8Mode = iota
+
+	// UTF8Reject fails decoding of values with invalid UTF-8 sequences.
+	UTF8Reject
+
+	// UTF8Replace replaces invalid UTF-8 sequences with U+FFFD.
+	UTF8Replace
+)
+
+// ClearedMode specifies how the encoder handles Clearable values.
+type ClearedMode uint8
+
+const (
+	// ClearedDefault encodes Clearable values as any other value.
+	ClearedDefault ClearedMode = iota
+
+	// ClearedEmpty encodes explicitly cleared values as empty and omits unset values,
+	// so PATCH-style submissions can express deletion
+	// eg. type A struct { Name form.Optional[string]; Age form.Optional[int] } with Name cleared and Age unset
+	//     encode results: url.Values{"Name":[]string{""}}
+	ClearedEmpty
+)
+
+// Clearable is implemented by optional value types that track whether a value was set
+// and whether it was explicitly cleared, see Encoder.SetClearedMode.
+type Clearable interface {
+	// IsSet reports whether a value, possibly empty, was provided.
+	IsSet() bool
+
+	// IsCleared reports whether the value was explicitly cleared.
+	IsCleared() bool
+}
+
+// BracketStyle specifies conventions of bracketed keys the decoder accepts.
+type BracketStyle uint8
+
+const (
+	// StyleDefault accepts indexed keys, eg. "tags[0]", and repeated plain keys, eg. "tags".
+	StyleDefault BracketStyle = iota
+
+	// StylePHP additionally accepts PHP/Rack style empty brackets for repeated values,
+	// eg. "tags[]=a&tags[]=b" is decoded as "tags=a&tags=b".
+	StylePHP
+)
+
+// KeyStyle is a preset of key conventions of another framework, it sets namespace prefix and suffix,
+// bracket style and array syntax at once, see Decoder.SetKeyStyle and Encoder.SetKeyStyle.
+type KeyStyle uint8
+
+const (
+	// StyleDots uses conventions of this package, eg. "user.address.city", "user.tags=a" and "user.items[0].name".
+	StyleDots KeyStyle = iota
+
+	// StyleRails uses conventions of Rails and Rack, eg. "user[address][city]", "user[tags][]=a" and
+	// "user[items][0][name]", the decoder also accepts arrays of hashes, eg. "user[items][][name]".
+	StyleRails
+
+	// StyleQS uses conventions of the qs JavaScript library with its default options, eg. "user[address][city]"
+	// and "user[tags][0]=a", see QSOptions.
+	StyleQS
+)
+
+// QSArrayFormat specifies how arrays are written in StyleQS like `arrayFormat` option of qs.
+type QSArrayFormat uint8
+
+const (
+	// QSIndices writes arrays with indexes, eg. "a[0]=b&a[1]=c".
+	QSIndices QSArrayFormat = iota
+
+	// QSBrackets writes arrays with empty brackets, eg. "a[]=b&a[]=c".
+	QSBrackets
+
+	// QSRepeat writes arrays as repeated keys, eg. "a=b&a=c".
+	QSRepeat
+
+	// QSComma writes arrays as comma-separated values, eg. "a=b,c", the decoder splits them like `comma` option of qs.
+	QSComma
+)
+
+// QSOptions are options of StyleQS matching options of the qs library, see Decoder.SetQSOptions
+// and Encoder.SetQSOptions.
+type QSOptions struct {
+	// AllowDots accepts and writes dotted keys of objects, eg. "a.b.c", like `allowDots` option.
+	AllowDots bool
+
+	// ArrayFormat is the format of arrays like `arrayFormat` option, the decoder accepts all formats
+	// except QSComma, which has to be set to split values.
+	ArrayFormat QSArrayFormat
+
+	// Depth is the maximum number of nested brackets of decoded keys like `depth` option, 0 is 5 as in qs.
+	// Keys nested deeper are ignored by the decoder.
+	Depth int
+}
+
+// ArraySyntax specifies how array and slice indexes are written in keys.
+type ArraySyntax uint8
+
+const (
+	// ArrayBrackets writes indexes in brackets, eg. "items[0].name".
+	ArrayBrackets ArraySyntax = iota
+
+	// ArrayDots writes indexes as dotted path segments, eg. "items.0.name",
+	// the decoder accepts both forms in this mode.
+	ArrayDots
+)
+
+// ArrayMode specifies how the encoder writes elements of fixed-size arrays.
+type ArrayMode uint8
+
+const (
+	// ArrayRepeat writes elements as repeated values of the field key like slices, eg. "bbox=1&bbox=2".
+	ArrayRepeat ArrayMode = iota
+
+	// ArrayIndexed writes elements with their indexes, eg. "bbox[0]=1&bbox[1]=2".
+	ArrayIndexed
+)
+
+// ParamStyle specifies the OpenAPI 3 serialization style of parameters, set for all fields with
+// Decoder.SetParamStyle and Encoder.SetParamStyle or per field with `style` and `explode` tag options,
+// eg. `form:"ids,style=pipeDelimited"` or `form:"filter,style=deepObject"`.
+type ParamStyle uint8
+
+const (
+	// ParamNative uses conventions of this package, eg. "ids=1&ids=2" and "filter.role=admin".
+	ParamNative ParamStyle = iota
+
+	// ParamForm writes arrays as repeated keys, eg. "ids=1&ids=2", and object properties as keys, eg. "role=admin",
+	// not exploded arrays are comma-separated, eg. "ids=1,2", and objects are key-value lists, eg. "filter=role,admin".
+	ParamForm
+
+	// ParamSpaceDelimited writes not exploded arrays separated by spaces, eg. "ids=1%202".
+	ParamSpaceDelimited
+
+	// ParamPipeDelimited writes not exploded arrays separated by pipes, eg. "ids=1|2".
+	ParamPipeDelimited
+
+	// ParamDeepObject writes object properties in brackets, eg. "filter[role]=admin".
+	ParamDeepObject
+)
+
+// SparseMode specifies how the decoder handles gaps in slice indexes, eg. "a[0]=x&a[5]=y".
+type SparseMode uint8
+
+const (
+	// SparseKeep keeps elements at their indexes, gaps are filled with zero values.
+	SparseKeep SparseMode = iota
+
+	// SparseCompact drops gaps keeping order of elements, eg. "a[0]=x&a[5]=y" is decoded as []string{"x", "y"}.
+	SparseCompact
+
+	// SparseError reports an error for slices with gaps.
+	SparseError
+)
+
+// MergeMode specifies how the decoder treats values already present in the decoded value,
+// eg. defaults decoded before user input.
+type MergeMode uint8
+
+const (
+	// MergeDefault keeps fields without values, values are appended to slices, indexed values replace
+	// elements at their indexes and entries are added to maps.
+	MergeDefault MergeMode = iota
+
+	// MergeReplace resets the decoded value before decoding, fields without values are zero.
+	MergeReplace
+
+	// MergeOverlay replaces fields with values including whole slices, arrays and maps, fields without values are kept.
+	MergeOverlay
+
+	// MergeAppend appends values to slices, indexed values are appended after existing elements in index order,
+	// and entries are added to maps, fields without values are kept.
+	MergeAppend
+)
+
+// FuncScope specifies which values the decoder converts with a function registered for their type.
+type FuncScope uint8
+
+const (
+	// FuncScopeDerived applies a function registered for T also to *T values and elements of []T, []*T,
+	// arrays and maps, including map keys.
+	FuncScopeDerived FuncScope = iota
+
+	// FuncScopeExact applies a function registered for T only to fields declared as T, and to the decoded value
+	// itself, derived types are decoded by default parsing unless functions are registered for them.
+	FuncScopeExact
+)
+
+// ReaderMode specifies whether the decoder populates reader fields.
+type ReaderMode uint8
+
+const (
+	// ReaderOff leaves io.Reader and *strings.Reader fields untouched.
+	ReaderOff ReaderMode = iota
+
+	// ReaderLazy sets io.Reader and *strings.Reader fields to readers over the received value,
+	// so large values can be consumed as a stream without copying them into another string.
+	ReaderLazy
+)
@@ -2,15 +2,38 @@ package form
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // DecodeFunc allows for registering/overriding types to be parsed.
 type DecodeFunc[Argument any] func(string, Argument) (interface{}, error)
 
+// InterfaceFactory returns a new value to decode an interface field into, usually a pointer to
+// a concrete type selected by a discriminator key, eg. values.Get(namespace+".type").
+// Returning nil leaves the field unset. values must not be modified.
+type InterfaceFactory func(values url.Values, namespace string) interface{}
+
+// ParseIntFunc parses a signed integer of the given bit size, see Decoder.SetIntParser.
+type ParseIntFunc func(s string, bitSize int) (int64, error)
+
+// ParseUintFunc parses an unsigned integer of the given bit size, see Decoder.SetUintParser.
+type ParseUintFunc func(s string, bitSize int) (uint64, error)
+
+// ParseFloatFunc parses a floating-point number of the given bit size, see Decoder.SetFloatParser.
+type ParseFloatFunc func(s string, bitSize int) (float64, error)
+
+// ErrorFormatter renders the message of a field error, see Decoder.SetErrorFormatter.
+type ErrorFormatter func(e *FieldError) string
+
 // DecodeErrors is a map of errors encountered during form decoding.
 type DecodeErrors map[string]error
 
@@ -28,6 +51,268 @@ func (d DecodeErrors) Error() string {
 	return strings.TrimSpace(buff.String())
 }
 
+// Unwrap returns errors of all fields ordered by namespace, so errors.Is and errors.As
+// match errors of any field.
+func (d DecodeErrors) Unwrap() []error {
+	keys := make([]string, 0, len(d))
+
+	for k := range d {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	errs := make([]error, len(keys))
+
+	for i, k := range keys {
+		errs[i] = d[k]
+	}
+
+	return errs
+}
+
+// ErrDecodeTimeout is matched by TimeoutError returned when decoding takes longer than the timeout,
+// see Decoder.SetTimeout.
+var ErrDecodeTimeout = errors.New("form: decode timeout exceeded")
+
+// TimeoutError is returned when decoding takes longer than the timeout, see Decoder.SetTimeout.
+// It matches ErrDecodeTimeout and errors of fields decoded before the deadline with errors.Is and errors.As.
+type TimeoutError struct {
+	// Errs are errors of fields decoded before the deadline, nil without errors.
+	Errs DecodeErrors
+}
+
+func (e *TimeoutError) Error() string {
+	if len(e.Errs) == 0 {
+		return ErrDecodeTimeout.Error()
+	}
+
+	return ErrDecodeTimeout.Error() + "\n" + e.Errs.Error()
+}
+
+// Unwrap returns ErrDecodeTimeout and the errors of fields.
+func (e *TimeoutError) Unwrap() []error {
+	if len(e.Errs) == 0 {
+		return []error{ErrDecodeTimeout}
+	}
+
+	return []error{ErrDecodeTimeout, e.Errs}
+}
+
+// ErrInvalidUTF8 is reported for string values with invalid UTF-8 sequences, see Decoder.SetUTF8Mode.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8 sequence")
+
+// Errors reported for fields in DecodeErrors, they can be matched with errors.Is,
+// eg. errors.Is(err, form.ErrInvalidInt).
+var (
+	// ErrInvalidInt is reported for values that are not valid signed integers of the field size.
+	ErrInvalidInt = errors.New("invalid integer value")
+
+	// ErrInvalidUint is reported for values that are not valid unsigned integers of the field size.
+	ErrInvalidUint = errors.New("invalid unsigned integer value")
+
+	// ErrInvalidFloat is reported for values that are not valid floating-point numbers.
+	ErrInvalidFloat = errors.New("invalid float value")
+
+	// ErrInvalidBool is reported for values that are not valid booleans.
+	ErrInvalidBool = errors.New("invalid boolean value")
+
+	// ErrInvalidIndex is reported for keys with non-numeric slice or array indexes.
+	ErrInvalidIndex = errors.New("invalid index")
+
+	// ErrInvalidKey is reported for keys with unbalanced brackets.
+	ErrInvalidKey = errors.New("invalid key formatting")
+
+	// ErrArrayIndexOutOfBounds is reported for indexes exceeding the maximum array size, see Decoder.SetMaxArraySize,
+	// or the length of fixed-size arrays.
+	ErrArrayIndexOutOfBounds = errors.New("array index out of bounds")
+
+	// ErrSparseSlice is reported for slices with gaps in indexes, see SparseError.
+	ErrSparseSlice = errors.New("sparse slice indexes")
+
+	// ErrUnsupportedType is reported for map keys and stream functions of unsupported types
+	// and for non-struct types of Decoder.StructSpec.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrInvalidTagOption is reported for values of fields with invalid tag options,
+	// eg. `maxlen` which is not a positive integer or `min` of a bool field.
+	ErrInvalidTagOption = errors.New("invalid tag option")
+
+	// ErrInvalidDuration is reported for values that are neither durations, eg. "1h30m", nor integer nanoseconds.
+	ErrInvalidDuration = errors.New("invalid duration value")
+
+	// ErrInvalidEnum is reported for values outside of the set of NewEnumFunc.
+	ErrInvalidEnum = errors.New("invalid enum value")
+
+	// ErrInvalidObject is reported for objects of `style=form` and `explode=false` fields that are not key-value lists,
+	// eg. "filter=role,admin".
+	ErrInvalidObject = errors.New("invalid object value")
+
+	// ErrAmbiguousField is reported for names of both a field and a field of an embedded struct with ConflictError.
+	ErrAmbiguousField = errors.New("ambiguous field")
+
+	// ErrInvalidByteSize is reported for values that are not valid sizes of ByteSize.
+	ErrInvalidByteSize = errors.New("invalid byte size")
+
+	// ErrConflictingKeys is reported for keys of map[string]interface{} values setting both a value and nested values
+	// of the same key, eg. "a=1&a[b]=2", the value of the key decoded first is kept.
+	ErrConflictingKeys = errors.New("conflicting keys")
+
+	// ErrInvalidValue is reported for values of standard library types decoded as single values,
+	// eg. url.URL and mail.Address, and of UUIDs, that can not be parsed.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// sentinelError is an error with a detailed message matching a sentinel error with errors.Is.
+type sentinelError struct {
+	msg      string
+	sentinel error
+}
+
+func newError(sentinel error, format string, args ...interface{}) error {
+	return &sentinelError{msg: fmt.Sprintf(format, args...), sentinel: sentinel}
+}
+
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.sentinel
+}
+
+// DecodeEvent describes a completed decoding, see Decoder.SetMetricsRecorder.
+type DecodeEvent struct {
+	// Type is the name of decoded type, eg. "api.CreateUserRequest".
+	Type string
+
+	// Duration is the time spent decoding.
+	Duration time.Duration
+
+	// Err is the error returned from decoding, if any.
+	Err error
+}
+
+// MetricsRecorder receives events of completed decodings.
+type MetricsRecorder func(e DecodeEvent)
+
+// Provenance describes where a decoded value came from, see Decoder.DecodeWithProvenance.
+type Provenance struct {
+	// Source is the name given to the decoded values, eg. "query" or "body".
+	Source string
+
+	// Key is the key the value was received under, before rewriting of dotted indexes or PHP-style brackets.
+	Key string
+}
+
+// LimitError is returned when values exceed limits set with Decoder.SetMaxKeys or Decoder.SetMaxValuesPerKey,
+// nothing is decoded in this case.
+type LimitError struct {
+	// Key is the key with too many values, it is empty if there are too many keys.
+	Key string
+
+	// Max is the exceeded limit.
+	Max int
+
+	// Count is the actual number of keys or values.
+	Count int
+}
+
+func (e *LimitError) Error() string {
+	if e.Key == blank {
+		return "form: number of keys " + strconv.Itoa(e.Count) + " exceeds maximum of " + strconv.Itoa(e.Max)
+	}
+
+	return "form: number of values " + strconv.Itoa(e.Count) + " of key '" + e.Key +
+		"' exceeds maximum of " + strconv.Itoa(e.Max)
+}
+
+// DepthError is returned when a key is nested deeper than the limit set with Decoder.SetMaxDepth,
+// nothing is decoded in this case.
+type DepthError struct {
+	Key   string
+	Max   int
+	Depth int
+}
+
+func (e *DepthError) Error() string {
+	return "form: nesting depth " + strconv.Itoa(e.Depth) + " of key '" + e.Key +
+		"' exceeds maximum of " + strconv.Itoa(e.Max)
+}
+
+// TooLongError is reported for values longer than the limit set with `maxlen` tag option,
+// eg. `form:"bio,maxlen=4096"`, length is measured in bytes. Fields with a limit which is not
+// a positive integer fail with ErrInvalidTagOption.
+type TooLongError struct {
+	MaxLen int
+	Len    int
+}
+
+func (e *TooLongError) Error() string {
+	return "value length " + strconv.Itoa(e.Len) + " exceeds maximum of " + strconv.Itoa(e.MaxLen)
+}
+
+// FieldError is the error stored in DecodeErrors for each failed field,
+// the underlying error is available with Unwrap or errors.Is/errors.As.
+type FieldError struct {
+	namespace string
+	kind      reflect.Kind
+	raw       string
+	err       error
+	format    ErrorFormatter
+}
+
+func (e *FieldError) Error() string {
+	if e.format != nil {
+		return e.format(e)
+	}
+
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.err
+}
+
+// Namespace returns the full path of the field, eg. "Users[0].Name".
+func (e *FieldError) Namespace() string {
+	return e.namespace
+}
+
+// Field returns the last element of the namespace without indexes, eg. "Name" for "Users[0].Name"
+// and "Tags" for "Tags[2]".
+func (e *FieldError) Field() string {
+	ns := e.namespace
+
+	for len(ns) > 0 && ns[len(ns)-1] == ']' {
+		idx := strings.LastIndexByte(ns, '[')
+		if idx == -1 {
+			break
+		}
+
+		ns = ns[:idx]
+	}
+
+	if idx := strings.LastIndexByte(ns, '.'); idx != -1 {
+		ns = ns[idx+1:]
+	}
+
+	return ns
+}
+
+// Kind returns the kind of the value being decoded, it is reflect.Invalid for errors
+// not related to a single value, eg. invalid array index.
+func (e *FieldError) Kind() reflect.Kind {
+	return e.kind
+}
+
+// RawValue returns the input value that failed to decode, it is empty for errors
+// not related to a single value.
+func (e *FieldError) RawValue() string {
+	return e.raw
+}
+
 // An InvalidDecoderError describes an invalid argument passed to Decode.
 // (The argument passed to Decode must be a non-nil pointer.)
 type InvalidDecoderError struct {
@@ -60,62 +345,322 @@ type recursiveData struct {
 
 type dataMap []*recursiveData
 
+// DecodeOptions holds decoder settings that can be changed for a single Decode call.
+//
+// Options should be obtained with Decoder.Options and then adjusted to keep other settings of the decoder.
+// Empty TagName, zero MaxArraySize and empty NamespacePrefix and NamespaceSuffix of a struct literal
+// are taken from the decoder, other fields are used as is.
+type DecodeOptions struct {
+	// TagName is the name of field tag, see Decoder.SetTagName.
+	TagName string
+
+	// Mode is the decoding mode, see Decoder.SetMode.
+	Mode Mode
+
+	// MaxArraySize is the maximum array size that can be created, see Decoder.SetMaxArraySize.
+	MaxArraySize int
+
+	// NamespacePrefix is prepended to names of nested struct fields, see Decoder.SetNamespacePrefix.
+	NamespacePrefix string
+
+	// NamespaceSuffix is appended to names of nested struct fields, see Decoder.SetNamespaceSuffix.
+	NamespaceSuffix string
+
+	// Timeout limits duration of decoding, see Decoder.SetTimeout.
+	Timeout time.Duration
+
+	// UTF8 specifies handling of invalid UTF-8 in string values, see Decoder.SetUTF8Mode.
+	UTF8 UTF8Mode
+
+	// IndexBase is the number of the first element in indexes, see Decoder.SetIndexBase.
+	IndexBase int
+
+	// BracketStyle specifies accepted conventions of bracketed keys, see Decoder.SetBracketStyle.
+	BracketStyle BracketStyle
+
+	// ArraySyntax specifies accepted syntax of indexes, see Decoder.SetArraySyntax.
+	ArraySyntax ArraySyntax
+
+	// SparseMode specifies handling of gaps in slice indexes, see Decoder.SetSparseMode.
+	SparseMode SparseMode
+
+	// KeyStyle is the preset of key conventions, see Decoder.SetKeyStyle.
+	KeyStyle KeyStyle
+
+	// QS are options of StyleQS, see Decoder.SetQSOptions.
+	QS QSOptions
+
+	// ParamStyle is the OpenAPI style of fields without style tag options, see Decoder.SetParamStyle.
+	ParamStyle ParamStyle
+
+	// ParamExplode specifies whether arrays and objects of ParamStyle are exploded, see Decoder.SetParamStyle.
+	ParamExplode bool
+
+	// ConflictMode specifies handling of embedded fields with names of fields of the embedding struct,
+	// see Decoder.SetConflictMode.
+	ConflictMode ConflictMode
+
+	// MergeMode specifies handling of values already present in the decoded value, see Decoder.SetMergeMode.
+	MergeMode MergeMode
+
+	// FuncScope specifies values converted by registered type functions, see Decoder.SetFuncScope.
+	FuncScope FuncScope
+
+	// MaxKeys is the maximum number of distinct keys, see Decoder.SetMaxKeys.
+	MaxKeys int
+
+	// IgnoredKeys are patterns of keys removed before decoding, see Decoder.SetIgnoredKeys.
+	IgnoredKeys []string
+
+	// MaxValuesPerKey is the maximum number of values of a key, see Decoder.SetMaxValuesPerKey.
+	MaxValuesPerKey int
+
+	// ReaderMode specifies whether reader fields are populated, see Decoder.SetReaderMode.
+	ReaderMode ReaderMode
+
+	// MaxDepth is the maximum nesting depth of keys, see Decoder.SetMaxDepth.
+	MaxDepth int
+
+	// MaxRecursion is the maximum number of times a struct type is nested in itself, 0 is 64,
+	// see Decoder.SetMaxRecursion.
+	MaxRecursion int
+
+	// MaxMemory is the memory limit of multipart request bodies, 0 is 32 MB, see Decoder.SetMaxMemory.
+	MaxMemory int64
+
+	// MethodOverride is the key of a value overriding the method of POST requests, see Decoder.SetMethodOverride.
+	MethodOverride string
+
+	// ThousandsSeparator is removed from numbers, 0 disables it, see Decoder.SetNumberSeparators.
+	ThousandsSeparator rune
+
+	// DecimalSeparator separates fractions of floats, 0 is '.', see Decoder.SetNumberSeparators.
+	DecimalSeparator rune
+
+	// TimeLayout is the layout of time.Time values, empty is time.RFC3339, see Decoder.SetLocale.
+	TimeLayout string
+
+	// TimeLayouts are layouts of time.Time values tried in order after TimeLayout, see Decoder.SetTimeLayouts.
+	TimeLayouts []string
+
+	// Location is the zone of time.Time values without zone, nil is UTC, see Decoder.SetLocation.
+	Location *time.Location
+
+	// PermissiveNumbers accepts prefixed and scientific integer literals, see Decoder.SetPermissiveNumbers.
+	PermissiveNumbers bool
+
+	// EnforceEnumRequired reports values violating `enum` and fields with `required` tag option
+	// without values, see Decoder.SetEnforceEnumRequired.
+	EnforceEnumRequired bool
+
+	// LooseInterfaces decodes repeated values of empty interface fields as []string, see Decoder.SetLooseInterfaceDecoding.
+	LooseInterfaces bool
+}
+
 // Decoder is the main decode instance.
 type Decoder[DecodeFuncArgument any] struct {
-	tagName         string
-	mode            Mode
-	structCache     *structCacheMap
+	opts        DecodeOptions
+	structCache atomic.Pointer[structCacheMap]
+	funcs       atomic.Pointer[decodeFuncs[DecodeFuncArgument]]
+	funcsLock   sync.Mutex
+	recorder    MetricsRecorder
+	validator   ValidateFunc
+	dataPool    *sync.Pool
+	frozen      bool // settings of decoders created by NewDecoderWith can not be changed
+}
+
+// decodeFuncs holds registered custom functions, registration publishes an updated copy
+// so that decoding in progress is not affected.
+type decodeFuncs[DecodeFuncArgument any] struct {
 	customTypeFuncs map[reflect.Type]DecodeFunc[DecodeFuncArgument]
-	maxArraySize    int
-	dataPool        *sync.Pool
-	namespacePrefix string
-	namespaceSuffix string
+	customTagFuncs  map[string]DecodeFunc[DecodeFuncArgument]
+	parseInt        ParseIntFunc
+	parseUint       ParseUintFunc
+	parseFloat      ParseFloatFunc
+	formatError     ErrorFormatter
+	ifaceFactories  map[reflect.Type]InterfaceFactory
+	variants        map[reflect.Type]map[string]reflect.Type
+	bodyDecoders    map[string]BodyDecodeFunc
+	typeDefaults    map[reflect.Type]reflect.Value
+
+	// preprocessors are replaced on registration, they are shared by copies
+	preprocessors    []Preprocessor
+	tagPreprocessors map[string]Preprocessor
+
+	// boolStrings replaced by SetBoolStrings are never modified, they are shared by copies
+	boolStrings map[string]bool
 }
 
-const defaultMaxArraySize = 10000
+func (f *decodeFuncs[DecodeFuncArgument]) clone() *decodeFuncs[DecodeFuncArgument] {
+	c := &decodeFuncs[DecodeFuncArgument]{
+		parseInt:      f.parseInt,
+		parseUint:     f.parseUint,
+		parseFloat:    f.parseFloat,
+		formatError:   f.formatError,
+		boolStrings:   f.boolStrings,
+		preprocessors: f.preprocessors,
+	}
+
+	if f.tagPreprocessors != nil {
+		c.tagPreprocessors = make(map[string]Preprocessor, len(f.tagPreprocessors)+1)
+
+		for k, v := range f.tagPreprocessors {
+			c.tagPreprocessors[k] = v
+		}
+	}
+
+	if f.customTypeFuncs != nil {
+		c.customTypeFuncs = make(map[reflect.Type]DecodeFunc[DecodeFuncArgument], len(f.customTypeFuncs)+1)
+
+		for k, v := range f.customTypeFuncs {
+			c.customTypeFuncs[k] = v
+		}
+	}
+
+	if f.customTagFuncs != nil {
+		c.customTagFuncs = make(map[string]DecodeFunc[DecodeFuncArgument], len(f.customTagFuncs)+1)
+
+		for k, v := range f.customTagFuncs {
+			c.customTagFuncs[k] = v
+		}
+	}
+
+	if f.ifaceFactories != nil {
+		c.ifaceFactories = make(map[reflect.Type]InterfaceFactory, len(f.ifaceFactories)+1)
+
+		for k, v := range f.ifaceFactories {
+			c.ifaceFactories[k] = v
+		}
+	}
+
+	if f.variants != nil {
+		c.variants = make(map[reflect.Type]map[string]reflect.Type, len(f.variants)+1)
+
+		for k, v := range f.variants {
+			c.variants[k] = v
+		}
+	}
+
+	if f.bodyDecoders != nil {
+		c.bodyDecoders = make(map[string]BodyDecodeFunc, len(f.bodyDecoders)+1)
+
+		for k, v := range f.bodyDecoders {
+			c.bodyDecoders[k] = v
+		}
+	}
+
+	if f.typeDefaults != nil {
+		c.typeDefaults = make(map[reflect.Type]reflect.Value, len(f.typeDefaults)+1)
+
+		for k, v := range f.typeDefaults {
+			c.typeDefaults[k] = v
+		}
+	}
+
+	return c
+}
+
+func (f *decodeFuncs[DecodeFuncArgument]) tagFunc(options tagOptions) (DecodeFunc[DecodeFuncArgument], string) {
+	for _, o := range options {
+		if fn, ok := f.customTagFuncs[o.name]; ok {
+			return fn, o.name
+		}
+	}
+
+	return nil, blank
+}
+
+const (
+	defaultMaxArraySize = 10000
+
+	// defaultMaxMemory matches the limit used by http.Request.FormValue.
+	defaultMaxMemory = 32 << 20
+)
 
 // NewDecoder creates a new decoder instance with sane defaults.
 func NewDecoder[DecodeFuncArgument any]() *Decoder[DecodeFuncArgument] {
 	d := &Decoder[DecodeFuncArgument]{
-		tagName:         "form",
-		mode:            ModeImplicit,
-		structCache:     newStructCacheMap(),
-		maxArraySize:    defaultMaxArraySize,
-		namespacePrefix: ".",
+		opts: DecodeOptions{
+			TagName:         "form",
+			Mode:            ModeImplicit,
+			MaxArraySize:    defaultMaxArraySize,
+			NamespacePrefix: ".",
+		},
 	}
 
+	d.structCache.Store(newStructCacheMap())
+	d.funcs.Store(&decodeFuncs[DecodeFuncArgument]{})
+	d.initPool()
+
+	return d
+}
+
+func (d *Decoder[DecodeFuncArgument]) initPool() {
 	d.dataPool = &sync.Pool{New: func() interface{} {
 		return &decoder[DecodeFuncArgument]{
 			d:         d,
 			namespace: make([]byte, 0, 64),
 		}
 	}}
+}
 
-	return d
+// Clone returns a copy of the decoder with the same settings and registered functions.
+//
+// The copy shares the struct cache with the original, so it is cheap to create,
+// eg. for per-route configuration, and its settings can be changed independently.
+// Functions registered on the copy do not affect the original and vice versa.
+func (d *Decoder[DecodeFuncArgument]) Clone() *Decoder[DecodeFuncArgument] {
+	c := &Decoder[DecodeFuncArgument]{
+		opts:      d.opts,
+		recorder:  d.recorder,
+		validator: d.validator,
+	}
+
+	c.structCache.Store(d.structCache.Load())
+	c.funcs.Store(d.funcs.Load())
+	c.initPool()
+
+	return c
+}
+
+// checkFrozen panics when settings of a decoder created by NewDecoderWith are changed,
+// it may be shared by goroutines decoding with it.
+func (d *Decoder[DecodeFuncArgument]) checkFrozen() {
+	if d.frozen {
+		panic("form: settings of a decoder created by NewDecoderWith can not be changed, change a Clone instead")
+	}
 }
 
 // SetTagName sets the given tag name to be used by the decoder.
 //
 // Default is "form".
 func (d *Decoder[DecodeFuncArgument]) SetTagName(tagName string) {
-	d.tagName = tagName
+	d.checkFrozen()
+
+	d.opts.TagName = tagName
 }
 
 // SetMode sets the mode the decoder should run.
 //
 // Default is ModeImplicit.
 func (d *Decoder[DecodeFuncArgument]) SetMode(mode Mode) {
-	d.mode = mode
+	d.checkFrozen()
+
+	d.opts.Mode = mode
 }
 
 // SetNamespacePrefix sets a struct namespace prefix.
 func (d *Decoder[DecodeFuncArgument]) SetNamespacePrefix(namespacePrefix string) {
-	d.namespacePrefix = namespacePrefix
+	d.checkFrozen()
+
+	d.opts.NamespacePrefix = namespacePrefix
 }
 
 // SetNamespaceSuffix sets a struct namespace suffix.
 func (d *Decoder[DecodeFuncArgument]) SetNamespaceSuffix(namespaceSuffix string) {
-	d.namespaceSuffix = namespaceSuffix
+	d.checkFrozen()
+
+	d.opts.NamespaceSuffix = namespaceSuffix
 }
 
 // SetMaxArraySize sets maximum array size that can be created.
@@ -125,72 +670,776 @@ func (d *Decoder[DecodeFuncArgument]) SetNamespaceSuffix(namespaceSuffix string)
 //
 // Default is 10000.
 func (d *Decoder[DecodeFuncArgument]) SetMaxArraySize(size uint) {
-	d.maxArraySize = int(size)
+	d.checkFrozen()
+
+	d.opts.MaxArraySize = int(size)
+}
+
+// SetTimeout sets maximum duration of a single Decode call to bound worst-case latency
+// of pathological inputs, decoding stops with TimeoutError once it is exceeded.
+// Values decoded before the deadline and their errors are kept.
+//
+// Default is 0, no timeout.
+func (d *Decoder[DecodeFuncArgument]) SetTimeout(timeout time.Duration) {
+	d.checkFrozen()
+
+	d.opts.Timeout = timeout
+}
+
+// SetUTF8Mode sets how string values with invalid UTF-8 sequences are handled,
+// they can be rejected with ErrInvalidUTF8 or repaired before assignment.
+//
+// Default is UTF8Accept.
+func (d *Decoder[DecodeFuncArgument]) SetUTF8Mode(mode UTF8Mode) {
+	d.checkFrozen()
+
+	d.opts.UTF8 = mode
+}
+
+// SetIndexBase sets the number of the first element in indexes,
+// eg. 1 for clients sending `items[1]` as the first element,
+// indexes below the base are reported as invalid.
+//
+// Default is 0.
+func (d *Decoder[DecodeFuncArgument]) SetIndexBase(base uint) {
+	d.checkFrozen()
+
+	d.opts.IndexBase = int(base)
+}
+
+// SetBracketStyle sets conventions of bracketed keys the decoder accepts,
+// eg. StylePHP for `tags[]=a&tags[]=b` emitted by many JS form serialization libraries.
+//
+// Default is StyleDefault.
+func (d *Decoder[DecodeFuncArgument]) SetBracketStyle(style BracketStyle) {
+	d.checkFrozen()
+
+	d.opts.BracketStyle = style
+}
+
+// SetArraySyntax sets accepted syntax of array and slice indexes,
+// ArrayDots additionally accepts `items.0.name` as `items[0].name`.
+//
+// Default is ArrayBrackets.
+func (d *Decoder[DecodeFuncArgument]) SetArraySyntax(syntax ArraySyntax) {
+	d.checkFrozen()
+
+	d.opts.ArraySyntax = syntax
+}
+
+// SetSparseMode sets how gaps in slice indexes are handled, eg. "a[0]=x&a[5]=y".
+//
+// Default is SparseKeep.
+func (d *Decoder[DecodeFuncArgument]) SetSparseMode(mode SparseMode) {
+	d.checkFrozen()
+
+	d.opts.SparseMode = mode
+}
+
+// SetKeyStyle sets key conventions of another framework at once, eg. StyleRails decodes
+// "user[address][city]=Paris&user[tags][]=a". It sets namespace prefix and suffix, bracket style
+// and array syntax, which can be adjusted by their setters afterwards.
+//
+// Default is StyleDots.
+func (d *Decoder[DecodeFuncArgument]) SetKeyStyle(style KeyStyle) {
+	d.checkFrozen()
+
+	d.opts.KeyStyle = style
+	d.opts.ArraySyntax = ArrayBrackets
+
+	switch style {
+	case StyleRails, StyleQS:
+		d.opts.NamespacePrefix, d.opts.NamespaceSuffix = "[", "]"
+		d.opts.BracketStyle = StylePHP
+	default:
+		d.opts.NamespacePrefix, d.opts.NamespaceSuffix = ".", blank
+		d.opts.BracketStyle = StyleDefault
+	}
 }
 
-// RegisterTagNameFunc registers a custom tag name parser function
-// NOTE: This method is not thread-safe it is intended that these all be registered prior to any parsing
+// SetQSOptions sets StyleQS with options of the qs library, eg. AllowDots decodes "user.tags[0]=a"
+// and ArrayFormat QSComma decodes "user[tags]=a,b".
+//
+// Default is QSOptions{}, StyleQS is not set.
+func (d *Decoder[DecodeFuncArgument]) SetQSOptions(opts QSOptions) {
+	d.checkFrozen()
+
+	d.SetKeyStyle(StyleQS)
+	d.opts.QS = opts
+}
+
+// SetParamStyle sets the OpenAPI serialization style of fields without `style`, `explode`, `delim` tag options
+// or collectionFormat tag, eg. ParamForm with explode false decodes "ids=1,2" into a slice.
+//
+// Default is ParamNative.
+func (d *Decoder[DecodeFuncArgument]) SetParamStyle(style ParamStyle, explode bool) {
+	d.checkFrozen()
+
+	d.opts.ParamStyle = style
+	d.opts.ParamExplode = explode
+}
+
+// SetConflictMode sets how a field of an embedded struct is decoded when a field of the embedding struct
+// has the same name, eg. ConflictOuter decodes "Field" into the outer field only.
+//
+// Default is ConflictShared.
+func (d *Decoder[DecodeFuncArgument]) SetConflictMode(mode ConflictMode) {
+	d.checkFrozen()
+
+	d.opts.ConflictMode = mode
+}
+
+// SetMergeMode sets how values already present in the decoded value are treated, eg. when user input
+// is decoded over defaults, MergeOverlay replaces slices and maps with values instead of adding to them.
+//
+// Default is MergeDefault.
+func (d *Decoder[DecodeFuncArgument]) SetMergeMode(mode MergeMode) {
+	d.checkFrozen()
+
+	d.opts.MergeMode = mode
+}
+
+// SetFuncScope sets which values are converted by a function registered with RegisterFunc,
+// eg. FuncScopeExact to use a function registered for T for T fields only and not for []T or *T fields.
+//
+// Default is FuncScopeDerived.
+func (d *Decoder[DecodeFuncArgument]) SetFuncScope(scope FuncScope) {
+	d.checkFrozen()
+
+	d.opts.FuncScope = scope
+}
+
+// SetMaxMemory sets the number of bytes of a multipart request body kept in memory by DecodeRequest,
+// remaining file parts are stored in temporary files.
+//
+// Default is 0, 32 MB as used by http.Request.FormValue.
+func (d *Decoder[DecodeFuncArgument]) SetMaxMemory(n int64) {
+	d.checkFrozen()
+
+	d.opts.MaxMemory = n
+}
+
+// SetMethodOverride sets the key of a body or query value overriding the method of POST requests
+// in DecodeRequest and BindRequest, eg. "_method" for HTML forms which can only be posted.
+// Only PUT, PATCH and DELETE are accepted, values are then selected by the effective method,
+// so "_method=DELETE" decodes the URL query. The key is decoded as any other value,
+// see SetIgnoredKeys to leave it out of `remainder` fields.
+//
+// Default is empty, methods are not overridden.
+func (d *Decoder[DecodeFuncArgument]) SetMethodOverride(key string) {
+	d.checkFrozen()
+
+	d.opts.MethodOverride = key
+}
+
+// SetNumberSeparators sets separators of formatted numbers accepted by default parsers,
+// eg. ',' and '.' for "1,234.56" or '.' and ',' for "1.234,56". Digits between thousands separators
+// must be grouped by three, 0 thousands separator accepts only plain numbers.
+//
+// Default is 0 and '.'.
+func (d *Decoder[DecodeFuncArgument]) SetNumberSeparators(thousands, decimal rune) {
+	d.checkFrozen()
+
+	d.opts.ThousandsSeparator = thousands
+	d.opts.DecimalSeparator = decimal
+}
+
+// SetLocale sets separators of numbers, see SetNumberSeparators, and the layout of time.Time values
+// accepted by default parsers, eg. Locale{DecimalSeparator: ',', TimeLayout: "02.01.2006"} for "3,14"
+// and "24.12.2024". Encoder.SetLocale formats values the same way.
+//
+// Default is Locale{}, plain numbers and RFC3339 times.
+func (d *Decoder[DecodeFuncArgument]) SetLocale(locale Locale) {
+	d.checkFrozen()
+
+	d.opts.ThousandsSeparator = locale.ThousandsSeparator
+	d.opts.DecimalSeparator = locale.DecimalSeparator
+	d.opts.TimeLayout = locale.TimeLayout
+}
+
+// SetTimeLayouts sets layouts of time.Time values tried in order when the layout set by SetLocale fails,
+// eg. time.RFC3339, "2006-01-02T15:04" and time.DateOnly for clients sending full times, times without
+// seconds and dates. Values failing all layouts report the error of the first. Fields with `layout` tag
+// option accept only that layout.
+//
+// Default is no fallback layouts.
+func (d *Decoder[DecodeFuncArgument]) SetTimeLayouts(layouts ...string) {
+	d.checkFrozen()
+
+	d.opts.TimeLayouts = append([]string(nil), layouts...)
+}
+
+// SetLocation sets the zone of time.Time values parsed without zone, eg. of HTML datetime-local inputs
+// with a layout set by SetLocale or `layout` tag option, values with zone keep it. `tz` tag option sets
+// the zone of a field, eg. `form:"at,layout=2006-01-02T15:04,tz=Europe/Paris"`, invalid zones fail
+// with ErrInvalidTagOption.
+//
+// Default is nil, UTC.
+func (d *Decoder[DecodeFuncArgument]) SetLocation(loc *time.Location) {
+	d.checkFrozen()
+
+	d.opts.Location = loc
+}
+
+// SetPermissiveNumbers sets whether default parsers accept integers with 0x, 0o or 0b prefix, eg. "0x1F",
+// and whole numbers in scientific notation, eg. "1e3". Leading zeros are not read as octal.
+// Floats accept scientific notation regardless.
+//
+// Default is false.
+func (d *Decoder[DecodeFuncArgument]) SetPermissiveNumbers(permissive bool) {
+	d.checkFrozen()
+
+	d.opts.PermissiveNumbers = permissive
+}
+
+// SetEnforceEnumRequired sets whether `enum` and `required` tag options, which describe fields in StructSpec
+// and OpenAPIParameters, are enforced when decoding. Values other than those of `enum` and fields with
+// `required` option without values are then reported as ConstraintError, required fields of nested structs
+// are reported only when the struct has values.
+//
+// Default is false, the options only describe fields.
+func (d *Decoder[DecodeFuncArgument]) SetEnforceEnumRequired(enforce bool) {
+	d.checkFrozen()
+
+	d.opts.EnforceEnumRequired = enforce
+}
+
+// SetLooseInterfaceDecoding sets whether nil fields of empty interface type without a registered factory
+// are decoded as string of a single value and []string of repeated values, eg. of generic filter structs
+// `form:"filter"` with "filter=a&filter=b". Otherwise the first value is decoded as string.
+// Elements of slices and maps of empty interfaces are always decoded as strings.
+//
+// Default is false.
+func (d *Decoder[DecodeFuncArgument]) SetLooseInterfaceDecoding(loose bool) {
+	d.checkFrozen()
+
+	d.opts.LooseInterfaces = loose
+}
+
+// SetIgnoredKeys sets patterns of keys removed before decoding, eg. hidden inputs injected by frameworks
+// like "csrf_token", "_method" or "utf8", so they are neither decoded nor collected by `remainder` fields.
+// A '*' in a pattern matches any sequence of characters, eg. "csrf_*" or "_*". Ignored keys still count
+// for SetMaxKeys and SetMaxValuesPerKey limits.
+//
+// Default is no ignored keys.
+func (d *Decoder[DecodeFuncArgument]) SetIgnoredKeys(patterns ...string) {
+	d.checkFrozen()
+
+	d.opts.IgnoredKeys = append([]string(nil), patterns...)
+}
+
+// SetMaxKeys sets maximum number of distinct keys, decoding of values with more keys
+// fails with LimitError before any processing.
+//
+// Default is 0, no limit.
+func (d *Decoder[DecodeFuncArgument]) SetMaxKeys(n uint) {
+	d.checkFrozen()
+
+	d.opts.MaxKeys = int(n)
+}
+
+// SetMaxValuesPerKey sets maximum number of values of a single key, decoding of values
+// with a key having more values fails with LimitError before any processing.
+//
+// Default is 0, no limit.
+func (d *Decoder[DecodeFuncArgument]) SetMaxValuesPerKey(n uint) {
+	d.checkFrozen()
+
+	d.opts.MaxValuesPerKey = int(n)
+}
+
+// SetMaxDepth sets maximum nesting depth of keys, eg. "a.b[0].c" has depth 3,
+// decoding of values with deeper keys fails with DepthError before any processing.
+//
+// Default is 0, no limit.
+func (d *Decoder[DecodeFuncArgument]) SetMaxDepth(n uint) {
+	d.checkFrozen()
+
+	d.opts.MaxDepth = int(n)
+}
+
+// SetMaxRecursion sets the maximum number of times a struct type is nested in itself, eg. "Next.Next.Next"
+// of `type Node struct { Next *Node }` nests Node in itself 3 times, deeper values fail with CycleError.
+//
+// Default is 64.
+func (d *Decoder[DecodeFuncArgument]) SetMaxRecursion(n uint) {
+	d.checkFrozen()
+
+	d.opts.MaxRecursion = int(n)
+}
+
+// SetReaderMode sets whether fields of io.Reader and *strings.Reader types are populated
+// with readers over the received values, it is opt-in since semantics differ from plain strings.
+//
+// Default is ReaderOff.
+func (d *Decoder[DecodeFuncArgument]) SetReaderMode(mode ReaderMode) {
+	d.checkFrozen()
+
+	d.opts.ReaderMode = mode
+}
+
+// SetMetricsRecorder sets a function to receive events of completed decodings with the decoded type name,
+// eg. to track decoding latency and error rates per request type.
+//
+// Default is nil, no events.
+func (d *Decoder[DecodeFuncArgument]) SetMetricsRecorder(recorder MetricsRecorder) {
+	d.checkFrozen()
+
+	d.recorder = recorder
+}
+
+// SetValidator sets a function called with the decoded value after every successful decode,
+// eg. validate.Struct of go-playground/validator. Its error is returned as ValidationError,
+// so it can be told apart from DecodeErrors of decoding failures.
+//
+// Default is nil, no validation.
+func (d *Decoder[DecodeFuncArgument]) SetValidator(fn ValidateFunc) {
+	d.checkFrozen()
+
+	d.validator = fn
+}
+
+// Options returns current settings of the decoder, they can be adjusted
+// and used with DecodeWithOptions.
+func (d *Decoder[DecodeFuncArgument]) Options() DecodeOptions {
+	return d.opts
+}
+
+// Precompute populates the struct cache with the given types and struct types reachable from them,
+// so that the first Decode call does not pay the reflection parsing cost.
+//
+// Structs are parsed for current tag name and mode, registering a tag name function resets the cache.
+func (d *Decoder[DecodeFuncArgument]) Precompute(types ...reflect.Type) {
+	sc := d.structCache.Load()
+	seen := make(map[reflect.Type]bool)
+
+	for _, t := range types {
+		sc.precompute(d.opts.Mode, t, d.opts.TagName, seen)
+	}
+}
+
+// RegisterTagNameFunc registers a custom tag name parser function.
+// It is safe to call concurrently with decoding, the decoder starts with a new struct cache,
+// that is no longer shared with clones.
 //
 // ADDITIONAL: once a custom function has been registered the default, or custom set, tag name is ignored
 // and relies 100% on the function for the name data. The return value WILL BE CACHED and so return value
 // must be consistent.
 func (d *Decoder[DecodeFuncArgument]) RegisterTagNameFunc(fn TagNameFunc) {
-	d.structCache.tagFn = fn
+	d.checkFrozen()
+
+	sc := newStructCacheMap()
+	sc.tagFn = fn
+	sc.nameFn = d.structCache.Load().nameFn
+
+	d.structCache.Store(sc)
+}
+
+// SetNameTransform sets a function transforming names of fields without an explicit name in the tag,
+// eg. SnakeCase to decode "user_id" into field UserID. nil restores the field names.
+// It is safe to call concurrently with decoding, the decoder starts with a new struct cache,
+// that is no longer shared with clones.
+func (d *Decoder[DecodeFuncArgument]) SetNameTransform(fn NameTransform) {
+	d.checkFrozen()
+
+	sc := newStructCacheMap()
+	sc.tagFn = d.structCache.Load().tagFn
+	sc.nameFn = fn
+
+	d.structCache.Store(sc)
 }
 
 // RegisterFunc registers a DecodeFunc against a number of types.
-// NOTE: This method is not thread-safe it is intended that these all be registered prior to any parsing
+// It is safe to call concurrently with decoding, calls in progress keep using previously registered functions.
 //
 // ADDITIONAL: if a struct type is registered, the function will only be called if a url.Value exists for
 // the struct and not just the struct fields eg. url.Values{"User":"Name%3Djoeybloggs"} will call the
 // custom type function with `User` as the type, however url.Values{"User.Name":"joeybloggs"} will not.
 func (d *Decoder[DecodeFuncArgument]) RegisterFunc(fn DecodeFunc[DecodeFuncArgument], types ...reflect.Type) {
-	if d.customTypeFuncs == nil {
-		d.customTypeFuncs = map[reflect.Type]DecodeFunc[DecodeFuncArgument]{}
-	}
+	d.checkFrozen()
 
-	for _, t := range types {
-		d.customTypeFuncs[t] = fn
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.customTypeFuncs == nil {
+			f.customTypeFuncs = map[reflect.Type]DecodeFunc[DecodeFuncArgument]{}
+		}
+
+		for _, t := range types {
+			f.customTypeFuncs[t] = fn
+		}
+	})
+}
+
+// RegisterFuncByTag registers a DecodeFunc against a field tag option,
+// eg. `form:"ids,csv"` would use function registered for "csv" option.
+// It is safe to call concurrently with decoding, calls in progress keep using previously registered functions.
+//
+// ADDITIONAL: tag option functions receive the first value of the field and the returned value
+// is assigned to the whole field, they take precedence over type functions.
+func (d *Decoder[DecodeFuncArgument]) RegisterFuncByTag(option string, fn DecodeFunc[DecodeFuncArgument]) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.customTagFuncs == nil {
+			f.customTagFuncs = map[string]DecodeFunc[DecodeFuncArgument]{}
+		}
+
+		f.customTagFuncs[option] = fn
+	})
+}
+
+// RegisterInterfaceFactory registers a factory of values for fields of the interface type,
+// the returned value is decoded with the namespace of the field and assigned to it.
+// It is safe to call concurrently with decoding, calls in progress keep using previously registered functions.
+//
+// NOTE: the returned value must implement the interface, otherwise an ErrUnsupportedType error is recorded.
+func (d *Decoder[DecodeFuncArgument]) RegisterInterfaceFactory(ifaceType reflect.Type, fn InterfaceFactory) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.ifaceFactories == nil {
+			f.ifaceFactories = map[reflect.Type]InterfaceFactory{}
+		}
+
+		f.ifaceFactories[ifaceType] = fn
+	})
+}
+
+// RegisterVariant registers a variant of the interface type for fields with `discriminator` tag option,
+// eg. a field `form:"payment,discriminator=type"` with "payment.type=card" is decoded into
+// a new value of the type registered for "card".
+// It is safe to call concurrently with decoding, calls in progress keep using previously registered functions.
+//
+// NOTE: a registered pointer is decoded as a new pointer, a value is decoded as a new value
+// when it implements the interface and as a pointer otherwise. Registered factories take precedence.
+func (d *Decoder[DecodeFuncArgument]) RegisterVariant(ifaceType reflect.Type, name string, variant interface{}) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.variants == nil {
+			f.variants = map[reflect.Type]map[string]reflect.Type{}
+		}
+
+		// variants of an interface are copied as the map is shared with previous funcs
+		variants := make(map[string]reflect.Type, len(f.variants[ifaceType])+1)
+
+		for k, v := range f.variants[ifaceType] {
+			variants[k] = v
+		}
+
+		variants[name] = reflect.TypeOf(variant)
+		f.variants[ifaceType] = variants
+	})
+}
+
+// RegisterPreprocessor registers a Preprocessor applied to values of every key before conversion,
+// in order of registration and before preprocessors of field tag options.
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) RegisterPreprocessor(fn Preprocessor) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		f.preprocessors = append(f.preprocessors[:len(f.preprocessors):len(f.preprocessors)], fn)
+	})
+}
+
+// RegisterPreprocessorByTag registers a Preprocessor applied to values of fields with the tag option,
+// including their elements and nested fields, eg. `form:"name,squash"` would use function registered
+// for "squash" option. Options "trim", "lower", "upper" and "nozw", removing zero-width characters,
+// are available without registration.
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) RegisterPreprocessorByTag(option string, fn Preprocessor) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.tagPreprocessors == nil {
+			f.tagPreprocessors = map[string]Preprocessor{}
+		}
+
+		f.tagPreprocessors[option] = fn
+	})
+}
+
+// RegisterBodyDecoder registers a decoder of request bodies of the media type used by BindRequest,
+// eg. "application/xml", nil disables decoding of the media type. "application/json" is decoded
+// with encoding/json unless another function is registered.
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) RegisterBodyDecoder(mediaType string, fn BodyDecodeFunc) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		if f.bodyDecoders == nil {
+			f.bodyDecoders = map[string]BodyDecodeFunc{}
+		}
+
+		f.bodyDecoders[mediaType] = fn
+	})
+}
+
+// SetIntParser replaces parsing of all signed integer values and map keys,
+// eg. with a locale-aware parser, nil restores strconv.ParseInt with base 10.
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) SetIntParser(fn ParseIntFunc) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		f.parseInt = fn
+	})
+}
+
+// SetUintParser replaces parsing of all unsigned integer values and map keys,
+// nil restores strconv.ParseUint with base 10.
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) SetUintParser(fn ParseUintFunc) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		f.parseUint = fn
+	})
+}
+
+// SetFloatParser replaces parsing of all floating-point values and map keys,
+// eg. with a faster parser, nil restores strconv.ParseFloat.
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) SetFloatParser(fn ParseFloatFunc) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		f.parseFloat = fn
+	})
+}
+
+// SetBoolStrings replaces strings accepted as true and false values of booleans and map keys,
+// eg. "on" and "off" of HTML checkboxes, empty values are false unless listed as true.
+// Passing no strings restores the defaults: "1", "t", "T", "true", "TRUE", "True", "on", "yes", "ok"
+// and "0", "f", "F", "false", "FALSE", "False", "off", "no".
+// It is safe to call concurrently with decoding.
+func (d *Decoder[DecodeFuncArgument]) SetBoolStrings(truthy, falsey []string) {
+	d.checkFrozen()
+
+	var boolStrings map[string]bool
+
+	if len(truthy)+len(falsey) > 0 {
+		boolStrings = make(map[string]bool, len(truthy)+len(falsey))
+
+		for _, s := range falsey {
+			boolStrings[s] = false
+		}
+
+		for _, s := range truthy {
+			boolStrings[s] = true
+		}
 	}
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		f.boolStrings = boolStrings
+	})
+}
+
+// SetErrorFormatter sets a function rendering messages of field errors, eg. in the language of the user,
+// nil restores the default messages. The original error remains available with FieldError.Unwrap.
+// It is safe to call concurrently with decoding.
+//
+//	decoder.SetErrorFormatter(func(e *form.FieldError) string {
+//		if errors.Is(e, form.ErrInvalidInt) {
+//			return e.Field() + " muss eine ganze Zahl sein"
+//		}
+//
+//		return e.Unwrap().Error()
+//	})
+func (d *Decoder[DecodeFuncArgument]) SetErrorFormatter(fn ErrorFormatter) {
+	d.checkFrozen()
+
+	d.updateFuncs(func(f *decodeFuncs[DecodeFuncArgument]) {
+		f.formatError = fn
+	})
+}
+
+// updateFuncs applies update to a copy of registered functions and publishes the copy.
+func (d *Decoder[DecodeFuncArgument]) updateFuncs(update func(f *decodeFuncs[DecodeFuncArgument])) {
+	d.funcsLock.Lock()
+	defer d.funcsLock.Unlock()
+
+	f := d.funcs.Load().clone()
+	update(f)
+	d.funcs.Store(f)
 }
 
 // Decode parses the given values and sets the corresponding struct and/or type values
 //
+// Decoding is best effort, every value that parses successfully is set even when others fail,
+// including valid elements of slices, arrays and maps, and the failures are returned together as DecodeErrors.
+// So a form can be shown again with all field errors while keeping the valid inputs.
+// Only exceeded limits and the timeout stop decoding early.
+//
+// collectGoValues, if given, receives the decoded values of top-level fields by name,
+// see DecodeCollect for values of nested fields.
+//
 // Decode returns an InvalidDecoderError if interface passed is invalid.
 func (d *Decoder[DecodeFuncArgument]) Decode(v interface{}, values url.Values, argument DecodeFuncArgument, collectGoValues ...map[string]interface{}) error {
+	return d.decode(v, values, argument, func(dec *decoder[DecodeFuncArgument]) {
+		if len(collectGoValues) > 0 {
+			dec.goValues = collectGoValues[0]
+		}
+	})
+}
+
+// DecodeCollect parses the given values like Decode and additionally returns the decoded values keyed by
+// namespace with the types of their fields, eg. "Users[0].Name", for audit logging or change tracking.
+// Nested structs are collected by their fields, so the keys match those Encoder.EncodeCollect returns.
+func (d *Decoder[DecodeFuncArgument]) DecodeCollect(v interface{}, values url.Values, argument DecodeFuncArgument) (map[string]interface{}, error) {
+	goValues := make(map[string]interface{})
+
+	err := d.decode(v, values, argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.goValues = goValues
+		dec.collectNested = true
+	})
+
+	return goValues, err
+}
+
+// DecodeFieldSet parses the given values like Decode and additionally returns the namespaces of fields
+// that received a value, so PATCH handlers can distinguish omitted fields from fields set to zero values.
+//
+//	fields, err := decoder.DecodeFieldSet(&patch, values, nil)
+//	if fields.Has("email") {
+//		user.Email = patch.Email
+//	}
+func (d *Decoder[DecodeFuncArgument]) DecodeFieldSet(v interface{}, values url.Values, argument DecodeFuncArgument) (FieldSet, error) {
+	fields := make(FieldSet)
+
+	err := d.decode(v, values, argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.fieldSet = fields
+	})
+
+	return fields, err
+}
+
+// DecodeWithOptions parses the given values and sets the corresponding struct and/or type values
+// using the given options instead of the decoder settings.
+//
+// It allows a single shared Decoder to serve different requirements without racing on setters.
+//
+//	opts := decoder.Options()
+//	opts.Mode = form.ModeExplicit
+//	opts.MaxArraySize = 100
+//
+//	err := decoder.DecodeWithOptions(&v, values, nil, opts)
+func (d *Decoder[DecodeFuncArgument]) DecodeWithOptions(v interface{}, values url.Values, argument DecodeFuncArgument, opts DecodeOptions) error {
+	opts = opts.withDefaults(d.opts)
+
+	return d.decode(v, values, argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.opts = opts
+	})
+}
+
+// withDefaults returns the options with settings a struct literal leaves empty, and which would decode
+// nothing, taken from defaults.
+func (o DecodeOptions) withDefaults(defaults DecodeOptions) DecodeOptions {
+	if o.TagName == blank {
+		o.TagName = defaults.TagName
+	}
+
+	if o.MaxArraySize == 0 {
+		o.MaxArraySize = defaults.MaxArraySize
+	}
+
+	if o.NamespacePrefix == blank && o.NamespaceSuffix == blank {
+		o.NamespacePrefix, o.NamespaceSuffix = defaults.NamespacePrefix, defaults.NamespaceSuffix
+	}
+
+	return o
+}
+
+// DecodeWithProvenance parses the given values like Decode and additionally records in provenance
+// the source name and received key of each decoded value, keyed by its namespace.
+//
+// Layered binding can decode several sources into the same value with the same map,
+// entries of values overwritten by a later source are replaced:
+//
+//	provenance := make(map[string]form.Provenance)
+//
+//	err := decoder.DecodeWithProvenance(&v, pathValues, nil, "path", provenance)
+//	...
+//	err = decoder.DecodeWithProvenance(&v, r.URL.Query(), nil, "query", provenance)
+func (d *Decoder[DecodeFuncArgument]) DecodeWithProvenance(v interface{}, values url.Values, argument DecodeFuncArgument, source string, provenance map[string]Provenance) error {
+	return d.decode(v, values, argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.source = source
+		dec.provenance = provenance
+	})
+}
+
+// decode runs decoding with per-call state configured by setup and validates the successfully decoded value.
+func (d *Decoder[DecodeFuncArgument]) decode(v interface{}, values url.Values, argument DecodeFuncArgument, setup func(dec *decoder[DecodeFuncArgument])) error {
+	err := d.decodeValues(v, values, argument, setup)
+	if err == nil {
+		err = d.validate(v)
+	}
+
+	return err
+}
+
+// decodeValues runs decoding with per-call state configured by setup.
+func (d *Decoder[DecodeFuncArgument]) decodeValues(v interface{}, values url.Values, argument DecodeFuncArgument, setup func(dec *decoder[DecodeFuncArgument])) error {
 	val := reflect.ValueOf(v)
 
 	if val.Kind() != reflect.Ptr || val.IsNil() {
 		return &InvalidDecoderError{Type: reflect.TypeOf(v)}
 	}
 
+	var start time.Time
+
+	if d.recorder != nil {
+		start = time.Now()
+	}
+
 	dec := d.dataPool.Get().(*decoder[DecodeFuncArgument]) //nolint:errcheck
+	dec.opts = d.opts
+	dec.funcs = d.funcs.Load()
+	dec.structCache = d.structCache.Load()
 	dec.values = values
 	dec.decodeFuncArgument = argument
+	dec.argumentProvider, _ = interface{}(argument).(ArgumentProvider[DecodeFuncArgument])
 	dec.dm = dec.dm[0:0]
 
+	if setup != nil {
+		setup(dec)
+	}
+
 	val = val.Elem()
 
-	if typ := val.Type(); val.Kind() == reflect.Struct && typ != timeType {
-		if len(collectGoValues) > 0 {
-			dec.goValues = collectGoValues[0]
-		}
+	err := dec.checkLimits()
+	if err == nil {
+		err = dec.run(val)
+	}
+
+	dec.reset()
+
+	d.dataPool.Put(dec)
 
-		dec.traverseStruct(val, typ, dec.namespace[0:0])
-	} else {
-		dec.setFieldByType(val, false, dec.namespace[0:0], 0)
+	if d.recorder != nil {
+		d.recorder(DecodeEvent{Type: val.Type().String(), Duration: time.Since(start), Err: err})
 	}
 
-	var err error
+	return err
+}
 
-	if len(dec.errs) > 0 {
-		err = dec.errs
-		dec.errs = nil
+// Check runs matching and type conversion of values against the given type
+// and discards the results, only the errors Decode would return are reported.
+//
+// The typ can be a reflect.Type or a value (or a pointer to a value) of the checked type.
+func (d *Decoder[DecodeFuncArgument]) Check(typ interface{}, values url.Values, argument DecodeFuncArgument) error {
+	t, ok := typ.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(typ)
 	}
 
-	dec.dmDone = false
+	if t == nil {
+		return &InvalidDecoderError{Type: t}
+	}
 
-	d.dataPool.Put(dec)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 
-	return err
+	return d.Decode(reflect.New(t).Interface(), values, argument)
 }
@@ -2,15 +2,25 @@ package form
 
 import (
 	"bytes"
+	"io"
 	"net/url"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // EncodeFunc allows for registering/overriding types to be parsed.
 type EncodeFunc func(x interface{}) (string, error)
 
+// EncodeArgumentFunc is an EncodeFunc receiving the argument of Encoder.EncodeWithArgument,
+// eg. a locale or an API version, it is nil for other encoding methods.
+type EncodeArgumentFunc func(x interface{}, argument interface{}) (string, error)
+
+// IsZeroFunc reports whether a value is empty for the `omitempty` tag option, see Encoder.RegisterIsZeroFunc.
+type IsZeroFunc func(x interface{}) bool
+
 // EncodeErrors is a map of errors encountered during form encoding.
 type EncodeErrors map[string]error
 
@@ -28,6 +38,26 @@ func (e EncodeErrors) Error() string {
 	return strings.TrimSpace(buff.String())
 }
 
+// Unwrap returns errors of all fields ordered by namespace, so errors.Is and errors.As
+// match errors of any field.
+func (e EncodeErrors) Unwrap() []error {
+	keys := make([]string, 0, len(e))
+
+	for k := range e {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	errs := make([]error, len(keys))
+
+	for i, k := range keys {
+		errs[i] = e[k]
+	}
+
+	return errs
+}
+
 // An InvalidEncodeError describes an invalid argument passed to Encode.
 type InvalidEncodeError struct {
 	Type reflect.Type
@@ -43,34 +73,172 @@ func (e *InvalidEncodeError) Error() string {
 
 // Encoder is the main encode instance.
 type Encoder struct {
-	tagName         string
-	structCache     *structCacheMap
-	customTypeFuncs map[reflect.Type]EncodeFunc
-	dataPool        *sync.Pool
-	mode            Mode
-	embedAnonymous  bool
+	tagName           string
+	structCache       atomic.Pointer[structCacheMap]
+	funcs             atomic.Pointer[encodeFuncs]
+	funcsLock         sync.Mutex
+	dataPool          *sync.Pool
+	mode              Mode
+	embedAnonymous    bool
+	embeddedNilZero   bool
+	conflictMode      ConflictMode
+	dualWriteFormerly bool
+	emptyCleared      bool
+	nilPointerEmpty   bool
+	omitZero          bool
+	omitUnchecked     bool
+	bareMapKeys       bool
+	indexBase         int
+	indexArrays       bool
+	paramStyle        ParamStyle
+	paramExplode      bool
+	keys              keyFormat
+	locale            Locale
+}
+
+// keyFormat holds settings of how keys are written, a key style sets them at once.
+type keyFormat struct {
 	namespacePrefix string
 	namespaceSuffix string
+	arrayDots       bool
+	emptyBrackets   bool
+	indexSlices     bool
+	objectBrackets  bool
+	commaSlices     bool
+}
+
+// setKeyStyle sets settings of the key style.
+func (k *keyFormat) setKeyStyle(style KeyStyle) {
+	switch style {
+	case StyleRails:
+		*k = keyFormat{namespacePrefix: "[", namespaceSuffix: "]", emptyBrackets: true}
+	case StyleQS:
+		k.setQSOptions(QSOptions{})
+	default:
+		*k = keyFormat{namespacePrefix: "."}
+	}
+}
+
+// setQSOptions sets settings of StyleQS with the options.
+func (k *keyFormat) setQSOptions(opts QSOptions) {
+	*k = keyFormat{
+		namespacePrefix: "[",
+		namespaceSuffix: "]",
+		indexSlices:     opts.ArrayFormat == QSIndices,
+		emptyBrackets:   opts.ArrayFormat == QSBrackets,
+		objectBrackets:  opts.ArrayFormat == QSBrackets,
+		commaSlices:     opts.ArrayFormat == QSComma,
+	}
+
+	if opts.AllowDots {
+		k.namespacePrefix, k.namespaceSuffix = ".", blank
+	}
+}
+
+// encodeFuncs holds registered custom functions, registration publishes an updated copy
+// so that encoding in progress is not affected.
+type encodeFuncs struct {
+	customTypeFuncs map[reflect.Type]EncodeArgumentFunc
+	customTagFuncs  map[string]EncodeArgumentFunc
+	isZeroFuncs     map[reflect.Type]IsZeroFunc
+}
+
+func (f *encodeFuncs) clone() *encodeFuncs {
+	c := &encodeFuncs{}
+
+	if f.customTypeFuncs != nil {
+		c.customTypeFuncs = make(map[reflect.Type]EncodeArgumentFunc, len(f.customTypeFuncs)+1)
+
+		for k, v := range f.customTypeFuncs {
+			c.customTypeFuncs[k] = v
+		}
+	}
+
+	if f.customTagFuncs != nil {
+		c.customTagFuncs = make(map[string]EncodeArgumentFunc, len(f.customTagFuncs)+1)
+
+		for k, v := range f.customTagFuncs {
+			c.customTagFuncs[k] = v
+		}
+	}
+
+	if f.isZeroFuncs != nil {
+		c.isZeroFuncs = make(map[reflect.Type]IsZeroFunc, len(f.isZeroFuncs)+1)
+
+		for k, v := range f.isZeroFuncs {
+			c.isZeroFuncs[k] = v
+		}
+	}
+
+	return c
+}
+
+func (f *encodeFuncs) tagFunc(options tagOptions) (EncodeArgumentFunc, string) {
+	for _, o := range options {
+		if fn, ok := f.customTagFuncs[o.name]; ok {
+			return fn, o.name
+		}
+	}
+
+	return nil, blank
 }
 
 // NewEncoder creates a new encoder instance with sane defaults.
 func NewEncoder() *Encoder {
 	e := &Encoder{
-		tagName:         "form",
-		mode:            ModeImplicit,
-		structCache:     newStructCacheMap(),
-		embedAnonymous:  true,
-		namespacePrefix: ".",
+		tagName:        "form",
+		mode:           ModeImplicit,
+		embedAnonymous: true,
+		keys:           keyFormat{namespacePrefix: "."},
 	}
 
+	e.structCache.Store(newStructCacheMap())
+	e.funcs.Store(&encodeFuncs{})
+	e.initPool()
+
+	return e
+}
+
+func (e *Encoder) initPool() {
 	e.dataPool = &sync.Pool{New: func() interface{} {
 		return &encoder{
 			e:         e,
 			namespace: make([]byte, 0, 64),
 		}
 	}}
+}
 
-	return e
+// Clone returns a copy of the encoder with the same settings and registered functions.
+//
+// The copy shares the struct cache with the original, so it is cheap to create,
+// eg. for per-route configuration, and its settings can be changed independently.
+// Functions registered on the copy do not affect the original and vice versa.
+func (e *Encoder) Clone() *Encoder {
+	c := &Encoder{
+		tagName:           e.tagName,
+		mode:              e.mode,
+		embedAnonymous:    e.embedAnonymous,
+		embeddedNilZero:   e.embeddedNilZero,
+		conflictMode:      e.conflictMode,
+		dualWriteFormerly: e.dualWriteFormerly,
+		emptyCleared:      e.emptyCleared,
+		nilPointerEmpty:   e.nilPointerEmpty,
+		omitZero:          e.omitZero,
+		omitUnchecked:     e.omitUnchecked,
+		bareMapKeys:       e.bareMapKeys,
+		indexBase:         e.indexBase,
+		indexArrays:       e.indexArrays,
+		paramStyle:        e.paramStyle,
+		paramExplode:      e.paramExplode,
+		keys:              e.keys,
+		locale:            e.locale,
+	}
+
+	c.structCache.Store(e.structCache.Load())
+	c.funcs.Store(e.funcs.Load())
+	c.initPool()
+
+	return c
 }
 
 // SetTagName sets the given tag name to be used by the encoder.
@@ -89,12 +257,12 @@ func (e *Encoder) SetMode(mode Mode) {
 
 // SetNamespacePrefix sets a struct namespace prefix.
 func (e *Encoder) SetNamespacePrefix(namespacePrefix string) {
-	e.namespacePrefix = namespacePrefix
+	e.keys.namespacePrefix = namespacePrefix
 }
 
 // SetNamespaceSuffix sets a struct namespace suffix.
 func (e *Encoder) SetNamespaceSuffix(namespaceSuffix string) {
-	e.namespaceSuffix = namespaceSuffix
+	e.keys.namespaceSuffix = namespaceSuffix
 }
 
 // SetAnonymousMode sets the mode the encoder should run.
@@ -104,31 +272,284 @@ func (e *Encoder) SetAnonymousMode(mode AnonymousMode) {
 	e.embedAnonymous = mode == AnonymousEmbed
 }
 
-// RegisterTagNameFunc registers a custom tag name parser function
-// NOTE: This method is not thread-safe it is intended that these all be registered prior to any parsing
+// SetEmbeddedNilMode sets how nil pointers to embedded structs are encoded.
+//
+// Default is EmbeddedNilSkip.
+func (e *Encoder) SetEmbeddedNilMode(mode EmbeddedNilMode) {
+	e.embeddedNilZero = mode == EmbeddedNilZero
+}
+
+// SetConflictMode sets how a field of a promoted embedded struct is encoded when a field of the embedding struct
+// has the same name, eg. ConflictNamespace encodes "Field" and "A.Field" instead of two values of "Field".
+//
+// Default is ConflictShared.
+func (e *Encoder) SetConflictMode(mode ConflictMode) {
+	e.conflictMode = mode
+}
+
+// SetNilPointerMode sets whether nil pointer fields are omitted or emitted as empty strings.
+//
+// Default is NilPointerSkip.
+func (e *Encoder) SetNilPointerMode(mode NilPointerMode) {
+	e.nilPointerEmpty = mode == NilPointerEmpty
+}
+
+// SetZeroValueMode sets whether non-pointer fields with zero values are omitted
+// without tagging every field with `omitempty`.
+//
+// Default is ZeroValueKeep.
+func (e *Encoder) SetZeroValueMode(mode ZeroValueMode) {
+	e.omitZero = mode == ZeroValueOmit
+}
+
+// SetBareMapKeys sets whether keys of an encoded map[string]interface{} are written without brackets
+// like field names, eg. "user[name]" and "tags[0]" instead of "[user][name]" and "[tags][0]", as decoding
+// into map[string]interface{} expects. Keys of nested maps are bracketed regardless.
+//
+// Default is false.
+func (e *Encoder) SetBareMapKeys(bare bool) {
+	e.bareMapKeys = bare
+}
+
+// SetCheckboxMode sets whether false values of fields with `checkbox` tag option are omitted,
+// eg. `form:"newsletter,checkbox"`.
+//
+// Default is CheckboxKeep.
+func (e *Encoder) SetCheckboxMode(mode CheckboxMode) {
+	e.omitUnchecked = mode == CheckboxOmitUnchecked
+}
+
+// SetKeyMigrationMode sets whether fields renamed with `formerly` tag option,
+// eg. `form:"user_id,formerly=uid"`, are also emitted under their old name.
+//
+// Default is KeyMigrationOff.
+func (e *Encoder) SetKeyMigrationMode(mode KeyMigrationMode) {
+	e.dualWriteFormerly = mode == KeyMigrationDualWrite
+}
+
+// SetClearedMode sets how values implementing Clearable are encoded.
+//
+// Default is ClearedDefault.
+func (e *Encoder) SetClearedMode(mode ClearedMode) {
+	e.emptyCleared = mode == ClearedEmpty
+}
+
+// SetIndexBase sets the number of the first element in emitted indexes,
+// eg. 1 for clients expecting `items[1]` as the first element.
+//
+// Default is 0.
+func (e *Encoder) SetIndexBase(base uint) {
+	e.indexBase = int(base)
+}
+
+// SetArraySyntax sets how array and slice indexes are written in keys.
+//
+// Default is ArrayBrackets.
+func (e *Encoder) SetArraySyntax(syntax ArraySyntax) {
+	e.keys.arrayDots = syntax == ArrayDots
+}
+
+// SetArrayMode sets how elements of fixed-size arrays are written, eg. of `[4]float64` bounding boxes,
+// ArrayIndexed writes them with indexes the decoder checks against the array length.
+//
+// Default is ArrayRepeat.
+func (e *Encoder) SetArrayMode(mode ArrayMode) {
+	e.indexArrays = mode == ArrayIndexed
+}
+
+// SetKeyStyle sets key conventions of another framework at once, eg. StyleRails encodes
+// "user[address][city]=Paris&user[tags][]=a" and arrays of structs with indexes as Rails forms do,
+// eg. "user[items][0][name]". It sets namespace prefix and suffix and array syntax, which can be
+// adjusted by their setters afterwards.
+//
+// Default is StyleDots.
+//
+// The encoder key style is independent of the decoder, eg. when proxying between systems, and can be
+// overridden per call with EncodeWithKeyStyle.
+func (e *Encoder) SetKeyStyle(style KeyStyle) {
+	e.keys.setKeyStyle(style)
+}
+
+// SetQSOptions sets StyleQS with options of the qs library, eg. AllowDots encodes "user.tags[0]=a"
+// and ArrayFormat QSBrackets encodes "user[tags][]=a&user[items][][name]=b". Depth is not used
+// by the encoder.
+//
+// Default is QSOptions{}, StyleQS is not set.
+func (e *Encoder) SetQSOptions(opts QSOptions) {
+	e.keys.setQSOptions(opts)
+}
+
+// SetLocale sets separators of encoded numbers and the layout of time.Time values, eg.
+// Locale{DecimalSeparator: ',', TimeLayout: "02.01.2006"} encodes "3,14" and "24.12.2024",
+// see Decoder.SetLocale. Map keys are formatted the same way.
+//
+// Default is Locale{}, plain numbers and RFC3339 times.
+func (e *Encoder) SetLocale(locale Locale) {
+	e.locale = locale
+}
+
+// SetParamStyle sets the OpenAPI serialization style of fields without `style`, `explode`, `delim` tag options
+// or collectionFormat tag, eg. ParamDeepObject writes "filter[role]=admin" for a struct field.
+//
+// Default is ParamNative.
+func (e *Encoder) SetParamStyle(style ParamStyle, explode bool) {
+	e.paramStyle = style
+	e.paramExplode = explode
+}
+
+// Precompute populates the struct cache with the given types and struct types reachable from them,
+// so that the first Encode call does not pay the reflection parsing cost.
+//
+// Structs are parsed for current tag name and mode, registering a tag name function resets the cache.
+func (e *Encoder) Precompute(types ...reflect.Type) {
+	sc := e.structCache.Load()
+	seen := make(map[reflect.Type]bool)
+
+	for _, t := range types {
+		sc.precompute(e.mode, t, e.tagName, seen)
+	}
+}
+
+// RegisterTagNameFunc registers a custom tag name parser function.
+// It is safe to call concurrently with encoding, the encoder starts with a new struct cache,
+// that is no longer shared with clones.
 //
 // ADDITIONAL: once a custom function has been registered the default, or custom set, tag name is ignored
 // and relies 100% on the function for the name data. The return value WILL BE CACHED and so return value
 // must be consistent.
 func (e *Encoder) RegisterTagNameFunc(fn TagNameFunc) {
-	e.structCache.tagFn = fn
+	sc := newStructCacheMap()
+	sc.tagFn = fn
+	sc.nameFn = e.structCache.Load().nameFn
+
+	e.structCache.Store(sc)
+}
+
+// SetNameTransform sets a function transforming names of fields without an explicit name in the tag,
+// eg. SnakeCase to encode field UserID as "user_id". nil restores the field names.
+// It is safe to call concurrently with encoding, the encoder starts with a new struct cache,
+// that is no longer shared with clones.
+func (e *Encoder) SetNameTransform(fn NameTransform) {
+	sc := newStructCacheMap()
+	sc.tagFn = e.structCache.Load().tagFn
+	sc.nameFn = fn
+
+	e.structCache.Store(sc)
 }
 
 // RegisterFunc registers a EncodeFunc against a number of types.
 //
-// NOTE: this method is not thread-safe it is intended that these all be registered prior to any parsing.
+// It is safe to call concurrently with encoding, calls in progress keep using previously registered functions.
 func (e *Encoder) RegisterFunc(fn EncodeFunc, types ...interface{}) {
-	if e.customTypeFuncs == nil {
-		e.customTypeFuncs = map[reflect.Type]EncodeFunc{}
-	}
+	e.RegisterArgumentFunc(func(x interface{}, _ interface{}) (string, error) {
+		return fn(x)
+	}, types...)
+}
 
-	for _, t := range types {
-		e.customTypeFuncs[reflect.TypeOf(t)] = fn
-	}
+// RegisterArgumentFunc registers a EncodeArgumentFunc against a number of types, it receives the argument
+// of EncodeWithArgument, see RegisterFunc.
+//
+// It is safe to call concurrently with encoding, calls in progress keep using previously registered functions.
+func (e *Encoder) RegisterArgumentFunc(fn EncodeArgumentFunc, types ...interface{}) {
+	e.updateFuncs(func(f *encodeFuncs) {
+		if f.customTypeFuncs == nil {
+			f.customTypeFuncs = map[reflect.Type]EncodeArgumentFunc{}
+		}
+
+		for _, t := range types {
+			f.customTypeFuncs[reflect.TypeOf(t)] = fn
+		}
+	})
+}
+
+// RegisterFuncByTag registers a EncodeFunc against a field tag option,
+// eg. `form:"ids,csv"` would use function registered for "csv" option.
+//
+// Tag option functions receive the whole field value and take precedence over type functions.
+//
+// It is safe to call concurrently with encoding, calls in progress keep using previously registered functions.
+func (e *Encoder) RegisterFuncByTag(option string, fn EncodeFunc) {
+	e.RegisterArgumentFuncByTag(option, func(x interface{}, _ interface{}) (string, error) {
+		return fn(x)
+	})
+}
+
+// RegisterArgumentFuncByTag registers a EncodeArgumentFunc against a field tag option, it receives
+// the argument of EncodeWithArgument, see RegisterFuncByTag.
+//
+// It is safe to call concurrently with encoding, calls in progress keep using previously registered functions.
+func (e *Encoder) RegisterArgumentFuncByTag(option string, fn EncodeArgumentFunc) {
+	e.updateFuncs(func(f *encodeFuncs) {
+		if f.customTagFuncs == nil {
+			f.customTagFuncs = map[string]EncodeArgumentFunc{}
+		}
+
+		f.customTagFuncs[option] = fn
+	})
+}
+
+// RegisterIsZeroFunc registers a IsZeroFunc against a number of types, it decides whether fields
+// of the types with `omitempty` tag option are omitted, eg. a struct with only empty fields.
+//
+// It is safe to call concurrently with encoding, calls in progress keep using previously registered functions.
+func (e *Encoder) RegisterIsZeroFunc(fn IsZeroFunc, types ...interface{}) {
+	e.updateFuncs(func(f *encodeFuncs) {
+		if f.isZeroFuncs == nil {
+			f.isZeroFuncs = map[reflect.Type]IsZeroFunc{}
+		}
+
+		for _, t := range types {
+			f.isZeroFuncs[reflect.TypeOf(t)] = fn
+		}
+	})
+}
+
+// updateFuncs applies update to a copy of registered functions and publishes the copy.
+func (e *Encoder) updateFuncs(update func(f *encodeFuncs)) {
+	e.funcsLock.Lock()
+	defer e.funcsLock.Unlock()
+
+	f := e.funcs.Load().clone()
+	update(f)
+	e.funcs.Store(f)
 }
 
 // Encode encodes the given values and sets the corresponding struct values.
+//
+// collectGoValues, if given, receives the encoded values of a struct keyed by namespace, see EncodeCollect.
 func (e *Encoder) Encode(v interface{}, collectGoValues ...map[string]interface{}) (values url.Values, err error) {
+	var goValues map[string]interface{}
+
+	if len(collectGoValues) > 0 {
+		goValues = collectGoValues[0]
+	}
+
+	return e.encode(v, e.keys, goValues, nil)
+}
+
+// EncodeWithArgument encodes the given value like Encode passing argument to functions registered with
+// RegisterArgumentFunc and RegisterArgumentFuncByTag, as Decoder.Decode passes its argument to decode
+// functions. An argument implementing ArgumentProvider[interface{}], eg. FuncArguments[any], routes
+// a different argument to each function.
+func (e *Encoder) EncodeWithArgument(v interface{}, argument interface{}) (values url.Values, err error) {
+	return e.encode(v, e.keys, nil, argument)
+}
+
+// EncodeWithKeyStyle encodes the given value like Encode with keys of the given style instead of
+// the encoder key style, eg. to encode for another system than the one the encoder is set up for.
+//
+//	values, err := encoder.EncodeWithKeyStyle(v, form.StyleRails)
+func (e *Encoder) EncodeWithKeyStyle(v interface{}, style KeyStyle) (values url.Values, err error) {
+	var keys keyFormat
+
+	keys.setKeyStyle(style)
+
+	return e.encode(v, keys, nil, nil)
+}
+
+// encode encodes the given value with keys and collects encoded values of a struct into goValues if not nil,
+// argument is passed to registered functions.
+func (e *Encoder) encode(v interface{}, keys keyFormat, goValues map[string]interface{}, argument interface{}) (values url.Values, err error) {
 	val, kind := ExtractType(reflect.ValueOf(v))
 
 	if kind == reflect.Ptr || kind == reflect.Interface || kind == reflect.Invalid {
@@ -136,14 +557,19 @@ func (e *Encoder) Encode(v interface{}, collectGoValues ...map[string]interface{
 	}
 
 	enc := e.dataPool.Get().(*encoder) //nolint:errcheck
+	enc.funcs = e.funcs.Load()
+	enc.structCache = e.structCache.Load()
 	enc.values = make(url.Values)
+	enc.keys = keys
+	enc.argument = argument
+	enc.argumentProvider, _ = argument.(ArgumentProvider[interface{}])
 
-	if kind == reflect.Struct && val.Type() != timeType {
-		if len(collectGoValues) > 0 {
-			enc.goValues = collectGoValues[0]
-		}
+	if seq, ok := formValues(val); ok {
+		enc.setProduced(enc.namespace[0:0], val, seq)
+	} else if kind == reflect.Struct && !isValueStruct(val.Type()) {
+		enc.goValues = goValues
 
-		enc.traverseStruct(val, enc.namespace[0:0], -1)
+		enc.traverseStruct(val, enc.rootNamespace(val.Type()), -1, blank)
 	} else {
 		enc.setFieldByType(val, enc.namespace[0:0], -1, cachedField{})
 	}
@@ -154,12 +580,23 @@ func (e *Encoder) Encode(v interface{}, collectGoValues ...map[string]interface{
 	}
 
 	values = enc.values
+	enc.goValues = nil
+	enc.argument, enc.argumentProvider = nil, nil
 
 	e.dataPool.Put(enc)
 
 	return
 }
 
+// EncodeCollect encodes the given value like Encode and additionally returns the encoded values of a struct
+// keyed by namespace with their Go types, eg. "Users[0].Name", like Decoder.DecodeCollect returns decoded values.
+func (e *Encoder) EncodeCollect(v interface{}) (values url.Values, goValues map[string]interface{}, err error) {
+	goValues = make(map[string]interface{})
+	values, err = e.Encode(v, goValues)
+
+	return values, goValues, err
+}
+
 // EncodeWithColumns encodes the given values and sets the corresponding struct values,
 // additionally returning slice of column names in original order.
 func (e *Encoder) EncodeWithColumns(v interface{}) (values url.Values, columns []string, err error) {
@@ -170,11 +607,16 @@ func (e *Encoder) EncodeWithColumns(v interface{}) (values url.Values, columns [
 	}
 
 	enc := e.dataPool.Get().(*encoder) //nolint:errcheck
+	enc.funcs = e.funcs.Load()
+	enc.structCache = e.structCache.Load()
 	enc.values = make(url.Values)
 	enc.columns = make([]string, 0)
+	enc.keys = e.keys
 
-	if kind == reflect.Struct && val.Type() != timeType {
-		enc.traverseStruct(val, enc.namespace[0:0], -1)
+	if seq, ok := formValues(val); ok {
+		enc.setProduced(enc.namespace[0:0], val, seq)
+	} else if kind == reflect.Struct && !isValueStruct(val.Type()) {
+		enc.traverseStruct(val, enc.rootNamespace(val.Type()), -1, blank)
 	} else {
 		enc.setFieldByType(val, enc.namespace[0:0], -1, cachedField{})
 	}
@@ -191,3 +633,102 @@ func (e *Encoder) EncodeWithColumns(v interface{}) (values url.Values, columns [
 
 	return
 }
+
+// Pair is a key and a value of encoded output, see Encoder.EncodePairs.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// EncodePairs encodes the given value into key/value pairs in a stable order, eg. for request signatures
+// requiring exact parameter order: struct fields in order of declaration, slice and array elements
+// by index and map entries sorted by key. Keys with several values are repeated.
+func (e *Encoder) EncodePairs(v interface{}) (pairs []Pair, err error) {
+	err = e.EncodeFunc(v, func(key, value string) error {
+		pairs = append(pairs, Pair{Key: key, Value: value})
+
+		return nil
+	})
+
+	return
+}
+
+// EncodeFunc encodes the given value passing key/value pairs to fn as they are encoded,
+// in the order of EncodePairs, without collecting them, eg. to feed a request signer or a writer.
+// Keys and values are not escaped.
+//
+// An error returned by fn stops encoding and is returned as is.
+func (e *Encoder) EncodeFunc(v interface{}, fn func(key, value string) error) (err error) {
+	val, kind := ExtractType(reflect.ValueOf(v))
+
+	if kind == reflect.Ptr || kind == reflect.Interface || kind == reflect.Invalid {
+		return &InvalidEncodeError{Type: reflect.TypeOf(v)}
+	}
+
+	var fnErr error
+
+	enc := e.dataPool.Get().(*encoder) //nolint:errcheck
+	enc.funcs = e.funcs.Load()
+	enc.structCache = e.structCache.Load()
+	enc.keys = e.keys
+	enc.emit = func(key, value string) {
+		if fnErr == nil {
+			fnErr = fn(key, value)
+			enc.stopped = fnErr != nil
+		}
+	}
+
+	if seq, ok := formValues(val); ok {
+		enc.setProduced(enc.namespace[0:0], val, seq)
+	} else if kind == reflect.Struct && !isValueStruct(val.Type()) {
+		enc.traverseStruct(val, enc.rootNamespace(val.Type()), -1, blank)
+	} else {
+		enc.setFieldByType(val, enc.namespace[0:0], -1, cachedField{})
+	}
+
+	if len(enc.errs) > 0 {
+		err = enc.errs
+		enc.errs = nil
+	}
+
+	enc.emit = nil
+	enc.stopped = false
+
+	e.dataPool.Put(enc)
+
+	if fnErr != nil {
+		return fnErr
+	}
+
+	return
+}
+
+// EncodeTo writes the given value url-encoded to w as pairs are encoded, in the order of EncodePairs,
+// without allocating url.Values, so very large values, including FormValues() iter.Seq2[string, string]
+// producers, can be encoded in constant memory.
+//
+// A write error stops encoding and is returned as is.
+func (e *Encoder) EncodeTo(w io.Writer, v interface{}) error {
+	var (
+		buf   []byte
+		first = true
+	)
+
+	return e.EncodeFunc(v, func(key, value string) error {
+		buf = buf[:0]
+
+		if !first {
+			buf = append(buf, '&')
+		}
+
+		first = false
+
+		buf = append(buf, url.QueryEscape(key)...)
+		buf = append(buf, '=')
+		buf = append(buf, url.QueryEscape(value)...)
+
+		_, err := w.Write(buf)
+
+		return err
+	})
+}
@@ -0,0 +1,104 @@
+package form
+
+import (
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DecodeHeader parses the given HTTP header and sets the corresponding struct values.
+//
+// Field names are matched to canonical header keys, eg. `form:"x-request-id"` receives "X-Request-Id".
+// Additionally to regular decoding, well-known header types are handled without custom functions:
+// time.Time fields are parsed with http.ParseTime (eg. Date, If-Modified-Since) and
+// slice fields receive items of comma-separated lists (eg. Accept-Encoding, Vary).
+func (d *Decoder[DecodeFuncArgument]) DecodeHeader(v interface{}, h http.Header, argument DecodeFuncArgument) error {
+	return d.decode(v, headerValues(h), argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.isHeader = true
+		dec.valuesOwned = true
+	})
+}
+
+// headerValues copies header values with canonical keys.
+func headerValues(h http.Header) url.Values {
+	values := make(url.Values, len(h))
+
+	for k, vals := range h {
+		k = textproto.CanonicalMIMEHeaderKey(k)
+		values[k] = append(values[k], vals...)
+	}
+
+	return values
+}
+
+// splitHeaderList replaces comma-separated header values with list items for slice fields.
+func (d *decoder[DecodeFuncArgument]) splitHeaderList(typ reflect.Type, namespace []byte) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+		return
+	}
+
+	arr, ok := d.lookup(namespace)
+	if !ok {
+		return
+	}
+
+	items := make([]string, 0, len(arr))
+
+	for _, val := range arr {
+		for _, item := range strings.Split(val, ",") {
+			if item = strings.TrimSpace(item); item != blank {
+				items = append(items, item)
+			}
+		}
+	}
+
+	d.replaceValues(string(namespace), items)
+}
+
+// parseTime parses time value with the layouts and zone of the field or decoder, HTTP date formats
+// are accepted for headers.
+func (d *decoder[DecodeFuncArgument]) parseTime(s string) (time.Time, error) {
+	if d.isHeader {
+		if t, err := http.ParseTime(s); err == nil {
+			return t, nil
+		}
+	}
+
+	if d.unixUnit != 0 {
+		return d.parseUnixTime(s)
+	}
+
+	layout := d.opts.TimeLayout
+	if layout == blank {
+		layout = time.RFC3339
+	}
+
+	t, err := d.parseTimeLayout(layout, s)
+	if err == nil {
+		return t, nil
+	}
+
+	for _, layout := range d.opts.TimeLayouts {
+		if t, e := d.parseTimeLayout(layout, s); e == nil {
+			return t, nil
+		}
+	}
+
+	return t, err
+}
+
+// parseTimeLayout parses time value with the layout in the zone of the field or decoder.
+func (d *decoder[DecodeFuncArgument]) parseTimeLayout(layout, s string) (time.Time, error) {
+	if d.opts.Location != nil {
+		return time.ParseInLocation(layout, s, d.opts.Location)
+	}
+
+	return time.Parse(layout, s)
+}
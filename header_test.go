@@ -0,0 +1,53 @@
+package form_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/amerium/form/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_DecodeHeader(t *testing.T) {
+	t.Parallel()
+
+	type Headers struct {
+		Date            time.Time  `form:"Date"`
+		IfModifiedSince *time.Time `form:"if-modified-since"`
+		ContentLength   int64      `form:"Content-Length"`
+		AcceptEncoding  []string   `form:"Accept-Encoding"`
+		RequestID       string     `form:"X-Request-ID"`
+		UserAgent       string     `form:"User-Agent"`
+	}
+
+	h := http.Header{}
+	h.Set("Date", "Tue, 15 Nov 1994 08:12:31 GMT")
+	h.Set("If-Modified-Since", "Sunday, 06-Nov-94 08:49:37 GMT")
+	h.Set("Content-Length", "348")
+	h.Add("Accept-Encoding", "gzip, deflate")
+	h.Add("Accept-Encoding", "br")
+	h.Set("X-Request-Id", "abc")
+	h["User-Agent"] = []string{"Mozilla/5.0 (X11; Linux x86_64)"}
+
+	dec := form.NewDecoder[any]()
+
+	var v Headers
+
+	require.NoError(t, dec.DecodeHeader(&v, h, nil))
+	assert.Equal(t, time.Date(1994, 11, 15, 8, 12, 31, 0, time.UTC), v.Date.UTC())
+	require.NotNil(t, v.IfModifiedSince)
+	assert.Equal(t, time.Date(1994, 11, 6, 8, 49, 37, 0, time.UTC), v.IfModifiedSince.UTC())
+	assert.Equal(t, int64(348), v.ContentLength)
+	assert.Equal(t, []string{"gzip", "deflate", "br"}, v.AcceptEncoding)
+	assert.Equal(t, "abc", v.RequestID)
+	assert.Equal(t, "Mozilla/5.0 (X11; Linux x86_64)", v.UserAgent)
+	assert.Equal(t, []string{"gzip, deflate", "br"}, h.Values("Accept-Encoding"), "header must not be modified")
+
+	h.Set("Date", "yesterday")
+
+	err := dec.DecodeHeader(&v, h, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Field Namespace:Date ERROR:")
+}
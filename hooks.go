@@ -0,0 +1,80 @@
+package form
+
+import (
+	"reflect"
+)
+
+// AfterDecoder is implemented by structs normalizing or validating themselves after their fields are decoded.
+// The decoder calls it for the decoded value and for nested structs with decoded fields,
+// errors are reported in DecodeErrors under the namespace of the struct.
+type AfterDecoder interface {
+	AfterFormDecode() error
+}
+
+// BeforeEncoder is implemented by structs preparing themselves before their fields are encoded.
+// The encoder calls it for every encoded struct, structs passed by value are copied to call pointer methods.
+// Errors are reported in EncodeErrors under the namespace of the struct, which is then not encoded.
+type BeforeEncoder interface {
+	BeforeFormEncode() error
+}
+
+// afterDecode calls AfterFormDecode of a decoded struct implementing AfterDecoder.
+func (d *decoder[DecodeFuncArgument]) afterDecode(v reflect.Value, namespace []byte) {
+	if !v.CanInterface() {
+		return
+	}
+
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+
+	if hook, ok := v.Interface().(AfterDecoder); ok {
+		if err := hook.AfterFormDecode(); err != nil {
+			d.setError(namespace, err)
+		}
+	}
+}
+
+// beforeEncode calls BeforeFormEncode of a struct implementing BeforeEncoder and returns the struct to encode,
+// which is a copy when the method has a pointer receiver and the struct is not addressable.
+func (e *encoder) beforeEncode(v reflect.Value, namespace []byte) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		return v, true
+	}
+
+	hv := v
+
+	switch {
+	case v.CanAddr():
+		hv = v.Addr()
+	case reflect.PointerTo(v.Type()).Implements(beforeEncoderType) && !v.Type().Implements(beforeEncoderType):
+		hv = reflect.New(v.Type())
+		hv.Elem().Set(v)
+		v = hv.Elem()
+	}
+
+	if hook, ok := hv.Interface().(BeforeEncoder); ok {
+		if err := hook.BeforeFormEncode(); err != nil {
+			e.setError(namespace, err)
+
+			return v, false
+		}
+	}
+
+	return v, true
+}
+
+var (
+	afterDecoderType  = reflect.TypeOf((*AfterDecoder)(nil)).Elem()
+	beforeEncoderType = reflect.TypeOf((*BeforeEncoder)(nil)).Elem()
+)
+
+// hasAfterDecode reports whether struct type or pointer to it implements AfterDecoder.
+func hasAfterDecode(t reflect.Type) bool {
+	return t.Implements(afterDecoderType) || reflect.PointerTo(t).Implements(afterDecoderType)
+}
+
+// hasBeforeEncode reports whether struct type or pointer to it implements BeforeEncoder.
+func hasBeforeEncode(t reflect.Type) bool {
+	return t.Implements(beforeEncoderType) || reflect.PointerTo(t).Implements(beforeEncoderType)
+}
@@ -0,0 +1,117 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type hookAddress struct {
+	City    string `form:"city"`
+	Country string `form:"country"`
+}
+
+func (a *hookAddress) AfterFormDecode() error {
+	if a.City == "" {
+		return errors.New("city is required")
+	}
+
+	a.Country = strings.ToUpper(a.Country)
+
+	return nil
+}
+
+func (a *hookAddress) BeforeFormEncode() error {
+	a.Country = strings.ToLower(a.Country)
+
+	return nil
+}
+
+type hookUser struct {
+	Name    string        `form:"name"`
+	Address hookAddress   `form:"address"`
+	Billing *hookAddress  `form:"billing"`
+	Others  []hookAddress `form:"others"`
+	decoded bool
+}
+
+func (u *hookUser) AfterFormDecode() error {
+	u.decoded = true
+
+	return nil
+}
+
+type hookSecret struct {
+	Value string `form:"value"`
+}
+
+func (s hookSecret) BeforeFormEncode() error {
+	return errors.New("secret can not be encoded")
+}
+
+func TestAfterDecoder(t *testing.T) {
+	t.Parallel()
+
+	var user hookUser
+
+	err := NewDecoder[any]().Decode(&user, url.Values{
+		"name":              {"joe"},
+		"address.city":      {"Berlin"},
+		"address.country":   {"de"},
+		"others[0].city":    {"Paris"},
+		"others[0].country": {"fr"},
+	}, nil)
+	NoError(t, err)
+	True(t, user.decoded)
+	Equal(t, hookAddress{City: "Berlin", Country: "DE"}, user.Address)
+	Equal(t, []hookAddress{{City: "Paris", Country: "FR"}}, user.Others)
+
+	// hooks are not called for nested structs without values
+	Nil(t, user.Billing)
+
+	user = hookUser{}
+
+	err = NewDecoder[any]().Decode(&user, url.Values{"name": {"joe"}, "billing.country": {"de"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 1, len(errs))
+	Contains(t, errs["billing"].Error(), "city is required")
+	True(t, user.decoded)
+}
+
+func TestBeforeEncoder(t *testing.T) {
+	t.Parallel()
+
+	user := hookUser{
+		Name:    "joe",
+		Address: hookAddress{City: "Berlin", Country: "DE"},
+		Others:  []hookAddress{{City: "Paris", Country: "FR"}},
+	}
+
+	values, err := NewEncoder().Encode(user)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"name":              {"joe"},
+		"address.city":      {"Berlin"},
+		"address.country":   {"de"},
+		"others[0].city":    {"Paris"},
+		"others[0].country": {"fr"},
+	}, values)
+
+	// values passed by value are not modified
+	Equal(t, "DE", user.Address.Country)
+
+	type Test struct {
+		Name   string     `form:"name"`
+		Secret hookSecret `form:"secret"`
+	}
+
+	values, err = NewEncoder().Encode(Test{Name: "n", Secret: hookSecret{Value: "s"}})
+	NotNil(t, err)
+	Contains(t, err.(EncodeErrors)["secret"].Error(), "secret can not be encoded")
+	Equal(t, url.Values{"name": {"n"}}, values)
+}
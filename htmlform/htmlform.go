@@ -0,0 +1,210 @@
+// Package htmlform describes fields of a struct as HTML form controls named as the form decoder reads
+// them, with current values converted by the form encoder, to render forms with html/template.
+//
+// The decoder and encoder must use the same key settings, eg. both StyleRails, so that names of rendered
+// controls are the keys values are encoded with and decoded from.
+package htmlform
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/amerium/form/v6"
+)
+
+var (
+	urlType         = reflect.TypeOf(url.URL{})
+	mailAddressType = reflect.TypeOf(mail.Address{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+)
+
+// Field describes an HTML form control of a struct field.
+type Field struct {
+	// Name is the name attribute, the key the decoder reads, eg. "address.city".
+	Name string
+
+	// Label is the Go name of the struct field, eg. "City".
+	Label string
+
+	// Type is the type attribute of an input inferred from the Go type, eg. "text", "number", "checkbox",
+	// or "select" for fields with `enum` tag option.
+	Type string
+
+	// Value is the first encoded value, for checkboxes the value sent when checked.
+	Value string
+
+	// Values are all encoded values, eg. of slices.
+	Values []string
+
+	// Multiple reports whether the field accepts several values, eg. a slice.
+	Multiple bool
+
+	// Checked reports whether a checkbox is checked.
+	Checked bool
+
+	// Required is set by `required` tag option.
+	Required bool
+
+	// Options are values of `enum` tag option for select elements.
+	Options []string
+
+	// Min, Max and Step are attributes of number inputs, empty when not set.
+	Min, Max, Step string
+
+	// MinLength, MaxLength and Pattern are attributes of text inputs, empty when not set.
+	MinLength, MaxLength, Pattern string
+}
+
+// Fields returns form controls of the fields of struct v in order, fields of nested structs follow as
+// the decoder reads them, see form.Decoder.StructSpec. Values are encoded from v unless it is a nil
+// pointer, eg. (*User)(nil) describes an empty form.
+//
+// Fields of slice and map elements, maps and fields with `src` tag option other than query or body
+// can not be posted by forms and are left out.
+func Fields[DecodeFuncArgument any](decoder *form.Decoder[DecodeFuncArgument], encoder *form.Encoder, v interface{}) ([]Field, error) {
+	specs, err := decoder.StructSpec(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	var values url.Values
+
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Ptr || !rv.IsNil() {
+		if values, err = encoder.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make([]Field, 0, len(specs))
+
+	for _, spec := range specs {
+		if spec.Nested || spec.Kind == reflect.Map || strings.Contains(spec.Namespace, "[]") {
+			continue
+		}
+
+		if src, ok := option(spec, "src"); ok && src != "query" && src != "body" {
+			continue
+		}
+
+		f := Field{Name: spec.Namespace, Label: spec.Field, Values: values[spec.Namespace]}
+		f.setType(spec)
+
+		if len(f.Values) > 0 {
+			f.Value = f.Values[0]
+		}
+
+		if f.Type == "checkbox" {
+			f.Value = "true"
+
+			// `bool=on|off` option sets strings the encoder emits
+			if format, ok := option(spec, "bool"); ok && strings.Contains(format, "|") {
+				f.Value = format[:strings.IndexByte(format, '|')]
+			}
+
+			f.Checked = len(f.Values) > 0 && f.Values[0] == f.Value
+		}
+
+		for _, o := range spec.Options {
+			switch o.Name {
+			case "required":
+				f.Required = true
+			case "enum":
+				f.Type = "select"
+				f.Options = strings.Split(o.Value, "|")
+			case "match":
+				f.Pattern = o.Value
+			case "min", "max", "len":
+				f.setLimit(o)
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// setType sets the input type of values of the field type, elements of slices and arrays are
+// values of a multiple field.
+func (f *Field) setType(spec form.FieldSpec) {
+	typ := spec.Type
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && !isText(typ) {
+		f.Multiple = true
+		typ = typ.Elem()
+
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+	}
+
+	switch {
+	case typ == urlType:
+		f.Type = "url"
+	case typ == mailAddressType:
+		f.Type = "email"
+	case typ == durationType, isText(typ):
+		f.Type = "text"
+	default:
+		switch typ.Kind() {
+		case reflect.Bool:
+			f.Type = "checkbox"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.Type = "number"
+		case reflect.Float32, reflect.Float64:
+			f.Type = "number"
+			f.Step = "any"
+		default:
+			f.Type = "text"
+		}
+	}
+}
+
+// setLimit sets attributes of `min`, `max` and `len` tag options, limits of numbers are values
+// and limits of texts are lengths.
+func (f *Field) setLimit(o form.TagOption) {
+	switch {
+	case f.Type == "number":
+		switch o.Name {
+		case "min":
+			f.Min = o.Value
+		case "max":
+			f.Max = o.Value
+		}
+	case f.Multiple:
+	case o.Name == "min":
+		f.MinLength = o.Value
+	case o.Name == "max":
+		f.MaxLength = o.Value
+	default:
+		f.MinLength, f.MaxLength = o.Value, o.Value
+	}
+}
+
+// isText reports whether values of typ are converted from text, eg. time.Time or a UUID.
+func isText(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Struct {
+		return true
+	}
+
+	return typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8
+}
+
+// option returns the value of a tag option of the field.
+func option(spec form.FieldSpec, name string) (string, bool) {
+	for _, o := range spec.Options {
+		if o.Name == name {
+			return o.Value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,132 @@
+package htmlform
+
+import (
+	"errors"
+	"html/template"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amerium/form/v6"
+	. "github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `form:"city,min=2,max=40"`
+	Zip  string `form:"zip,len=5,match=^[0-9]+$"`
+}
+
+type user struct {
+	ID       int           `form:"id,src=path"`
+	Name     string        `form:"name,required"`
+	Age      uint8         `form:"age,min=18,max=150"`
+	Score    float64       `form:"score"`
+	Admin    bool          `form:"admin,bool=on|off"`
+	Active   bool          `form:"active"`
+	Role     string        `form:"role,enum=admin|user"`
+	Tags     []string      `form:"tags"`
+	Born     time.Time     `form:"born"`
+	Timeout  time.Duration `form:"timeout"`
+	Address  address       `form:"address"`
+	Contacts []address     `form:"contacts"`
+	Labels   map[string]string
+}
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+
+	decoder := form.NewDecoder[any]()
+	encoder := form.NewEncoder()
+
+	u := user{
+		ID:     1,
+		Name:   "joe",
+		Age:    30,
+		Admin:  true,
+		Role:   "user",
+		Tags:   []string{"a", "b"},
+		Born:   time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Active: false,
+		Address: address{
+			City: "Berlin",
+			Zip:  "10115",
+		},
+	}
+
+	fields, err := Fields(decoder, encoder, &u)
+	NoError(t, err)
+
+	names := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+
+	Equal(t, []string{"name", "age", "score", "admin", "active", "role", "tags", "born", "timeout", "address.city", "address.zip"}, names)
+
+	Equal(t, Field{Name: "name", Label: "Name", Type: "text", Value: "joe", Values: []string{"joe"}, Required: true}, fields[0])
+	Equal(t, Field{Name: "age", Label: "Age", Type: "number", Value: "30", Values: []string{"30"}, Min: "18", Max: "150"}, fields[1])
+	Equal(t, "any", fields[2].Step)
+	Equal(t, "checkbox", fields[3].Type)
+	Equal(t, "on", fields[3].Value)
+	True(t, fields[3].Checked)
+	Equal(t, "true", fields[4].Value)
+	False(t, fields[4].Checked)
+	Equal(t, "select", fields[5].Type)
+	Equal(t, []string{"admin", "user"}, fields[5].Options)
+	True(t, fields[6].Multiple)
+	Equal(t, []string{"a", "b"}, fields[6].Values)
+	Equal(t, "text", fields[7].Type)
+	Equal(t, "2000-01-02T00:00:00Z", fields[7].Value)
+	Equal(t, "text", fields[8].Type)
+	Equal(t, Field{Name: "address.city", Label: "City", Type: "text", Value: "Berlin", Values: []string{"Berlin"}, MinLength: "2", MaxLength: "40"}, fields[9])
+	Equal(t, "5", fields[10].MinLength)
+	Equal(t, "5", fields[10].MaxLength)
+	Equal(t, "^[0-9]+$", fields[10].Pattern)
+
+	// posted values of rendered controls decode to the same struct
+	values := make(url.Values)
+
+	for _, f := range fields {
+		if f.Type != "checkbox" || f.Checked {
+			values[f.Name] = f.Values
+		}
+	}
+
+	var decoded user
+
+	NoError(t, decoder.Decode(&decoded, values, nil))
+	Equal(t, u.Name, decoded.Name)
+	Equal(t, u.Tags, decoded.Tags)
+	True(t, decoded.Admin)
+	True(t, u.Born.Equal(decoded.Born))
+	Equal(t, u.Address, decoded.Address)
+
+	tmpl := template.Must(template.New("").Parse(
+		`{{range .}}<input type="{{.Type}}" name="{{.Name}}" value="{{.Value}}"{{if .Checked}} checked{{end}}>{{end}}`))
+
+	var b strings.Builder
+
+	NoError(t, tmpl.Execute(&b, fields[3:5]))
+	Equal(t, `<input type="checkbox" name="admin" value="on" checked><input type="checkbox" name="active" value="true">`, b.String())
+}
+
+func TestFieldsEmpty(t *testing.T) {
+	t.Parallel()
+
+	decoder := form.NewDecoder[any]()
+	decoder.SetKeyStyle(form.StyleRails)
+
+	encoder := form.NewEncoder()
+	encoder.SetKeyStyle(form.StyleRails)
+
+	fields, err := Fields(decoder, encoder, (*user)(nil))
+	NoError(t, err)
+	Equal(t, "address[city]", fields[9].Name)
+	Empty(t, fields[9].Value)
+	Nil(t, fields[9].Values)
+
+	_, err = Fields(decoder, encoder, 1)
+	True(t, errors.Is(err, form.ErrUnsupportedType))
+}
@@ -0,0 +1,56 @@
+package form
+
+import (
+	"strings"
+)
+
+// dropIgnoredKeys removes keys matching patterns of Decoder.SetIgnoredKeys from values, the values
+// are copied before the first removal.
+func (d *decoder[DecodeFuncArgument]) dropIgnoredKeys() {
+	for k := range d.values {
+		if !d.isIgnoredKey(k) {
+			continue
+		}
+
+		if !d.valuesOwned {
+			d.replaceValues(k, nil)
+		}
+
+		delete(d.values, k)
+	}
+}
+
+// isIgnoredKey reports whether the key matches any pattern of Decoder.SetIgnoredKeys.
+func (d *decoder[DecodeFuncArgument]) isIgnoredKey(key string) bool {
+	for _, pattern := range d.opts.IgnoredKeys {
+		if matchGlob(pattern, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob reports whether s matches pattern where '*' matches any sequence of characters,
+// including none, and all other characters match themselves.
+func matchGlob(pattern, s string) bool {
+	star := strings.IndexByte(pattern, '*')
+	if star == -1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, pattern[:star]) {
+		return false
+	}
+
+	s = s[star:]
+	pattern = pattern[star+1:]
+
+	for i := 0; i <= len(s); i++ {
+		if matchGlob(pattern, s[i:]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDecoder_SetIgnoredKeys(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name   string     `form:"name"`
+		Method string     `form:"_method"`
+		Extra  url.Values `form:",remainder"`
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.SetIgnoredKeys("csrf_*", "_method", "utf8")
+
+	values := url.Values{
+		"name":             {"joe"},
+		"_method":          {"PUT"},
+		"csrf_token":       {"secret"},
+		"csrf_":            {"x"},
+		"utf8":             {"✓"},
+		"utf8mb4":          {"y"},
+		"session.csrf_key": {"z"},
+	}
+
+	var test Test
+
+	NoError(t, decoder.Decode(&test, values, nil))
+	Equal(t, "joe", test.Name)
+	Empty(t, test.Method)
+	Equal(t, url.Values{"utf8mb4": {"y"}, "session.csrf_key": {"z"}}, test.Extra)
+
+	// values of the caller are not modified
+	Equal(t, 7, len(values))
+
+	clone := decoder.Clone()
+	clone.SetIgnoredKeys()
+
+	test = Test{}
+
+	NoError(t, clone.Decode(&test, values, nil))
+	Equal(t, "PUT", test.Method)
+	Equal(t, []string{"csrf_*", "_method", "utf8"}, decoder.Options().IgnoredKeys)
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	True(t, matchGlob("a", "a"))
+	False(t, matchGlob("a", "ab"))
+	True(t, matchGlob("*", ""))
+	True(t, matchGlob("a*", "a"))
+	True(t, matchGlob("*.token", "form.token"))
+	True(t, matchGlob("a*b*c", "axxbyyc"))
+	False(t, matchGlob("a*b*c", "axxbyy"))
+	True(t, matchGlob("items[*]", "items[0]"))
+}
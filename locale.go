@@ -0,0 +1,143 @@
+package form
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale specifies formats of numbers and times of a region, eg. Locale{DecimalSeparator: ',',
+// TimeLayout: "02.01.2006"} for "3,14" and "24.12.2024", see Decoder.SetLocale and Encoder.SetLocale.
+type Locale struct {
+	// DecimalSeparator separates fractions of floats, 0 is '.'.
+	DecimalSeparator rune
+
+	// ThousandsSeparator groups digits of integer parts by three, 0 does not group them.
+	ThousandsSeparator rune
+
+	// TimeLayout is the layout of time.Time values as used by time.Format, empty is time.RFC3339.
+	TimeLayout string
+}
+
+// formatNumber formats a decimal number, eg. "-1234" or "1234.5", with separators of the locale.
+func (l *Locale) formatNumber(s string) string {
+	if l.ThousandsSeparator == 0 && (l.DecimalSeparator == 0 || l.DecimalSeparator == '.') ||
+		s == "NaN" || strings.HasSuffix(s, "Inf") {
+		return s
+	}
+
+	var b strings.Builder
+
+	b.Grow(len(s) + len(s)/3)
+
+	if s[0] == '-' {
+		b.WriteByte('-')
+		s = s[1:]
+	}
+
+	digits := s
+	fraction := blank
+
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		digits, fraction = s[:i], s[i+1:]
+	}
+
+	for i := 0; i < len(digits); i++ {
+		if i > 0 && l.ThousandsSeparator != 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune(l.ThousandsSeparator)
+		}
+
+		b.WriteByte(digits[i])
+	}
+
+	if fraction != blank {
+		if l.DecimalSeparator == 0 {
+			b.WriteByte('.')
+		} else {
+			b.WriteRune(l.DecimalSeparator)
+		}
+
+		b.WriteString(fraction)
+	}
+
+	return b.String()
+}
+
+// timeLayout returns the layout of times of the locale.
+func (l *Locale) timeLayout() string {
+	if l.TimeLayout == blank {
+		return time.RFC3339
+	}
+
+	return l.TimeLayout
+}
+
+// formatInt formats a signed integer with the encoder locale, durations are plain nanoseconds
+// as the decoder parses them.
+func (e *encoder) formatInt(v reflect.Value) string {
+	if v.Type() == durationType {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+
+	return e.e.locale.formatNumber(strconv.FormatInt(v.Int(), 10))
+}
+
+// formatUint formats an unsigned integer with the encoder locale.
+func (e *encoder) formatUint(u uint64) string {
+	return e.e.locale.formatNumber(strconv.FormatUint(u, 10))
+}
+
+// formatFloat formats a float with the encoder locale, without exponent.
+func (e *encoder) formatFloat(f float64, bitSize int) string {
+	return e.e.locale.formatNumber(strconv.FormatFloat(f, 'f', -1, bitSize))
+}
+
+// formatTime formats a time as a timestamp of `unix`, `unixmilli` or `unixnano` tag options, with the layout
+// and zone of `layout` and `tz` tag options of the field or with the encoder locale.
+func (e *encoder) formatTime(t time.Time, f cachedField) string {
+	switch f.unixUnit {
+	case time.Second:
+		return strconv.FormatInt(t.Unix(), 10)
+	case time.Millisecond:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case time.Nanosecond:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+
+	if f.location != nil {
+		t = t.In(f.location)
+	}
+
+	if f.timeLayout != blank {
+		return t.Format(f.timeLayout)
+	}
+
+	return t.Format(e.e.locale.timeLayout())
+}
+
+// parseUnixTime parses a timestamp of `unix`, `unixmilli` or `unixnano` tag options, times are in UTC
+// or in the location of the field or decoder.
+func (d *decoder[DecodeFuncArgument]) parseUnixTime(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var t time.Time
+
+	switch d.unixUnit {
+	case time.Second:
+		t = time.Unix(n, 0)
+	case time.Millisecond:
+		t = time.UnixMilli(n)
+	default:
+		t = time.Unix(0, n)
+	}
+
+	if d.opts.Location != nil {
+		return t.In(d.opts.Location), nil
+	}
+
+	return t.UTC(), nil
+}
@@ -0,0 +1,194 @@
+package form
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestLocale(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Price  float64           `form:"price"`
+		Small  float32           `form:"small"`
+		Count  int               `form:"count"`
+		Size   uint              `form:"size"`
+		Date   time.Time         `form:"date"`
+		Rates  map[float64]int64 `form:"rates"`
+		Ratios []float64         `form:"ratios"`
+		Wait   time.Duration     `form:"wait"`
+	}
+
+	locale := Locale{DecimalSeparator: ',', ThousandsSeparator: '.', TimeLayout: "02.01.2006"}
+
+	encoder := NewEncoder()
+	encoder.SetLocale(locale)
+
+	test := Test{
+		Price:  -1234567.5,
+		Small:  3.25,
+		Count:  -1000,
+		Size:   999,
+		Date:   time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC),
+		Rates:  map[float64]int64{0.5: 12345},
+		Ratios: []float64{3.14, 1000},
+		Wait:   30 * time.Second,
+	}
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"price":      {"-1.234.567,5"},
+		"small":      {"3,25"},
+		"count":      {"-1.000"},
+		"size":       {"999"},
+		"date":       {"24.12.2024"},
+		"rates[0,5]": {"12.345"},
+		"ratios":     {"3,14", "1.000"},
+		"wait":       {"30000000000"},
+	}, values)
+
+	decoder := NewDecoder[any]()
+	decoder.SetLocale(locale)
+
+	var decoded Test
+
+	NoError(t, decoder.Decode(&decoded, values, nil))
+	Equal(t, test, decoded)
+
+	// clones keep the locale
+	values, err = encoder.Clone().Encode(struct {
+		Ratio float64 `form:"ratio"`
+	}{Ratio: math.Inf(1)})
+	NoError(t, err)
+	Equal(t, "+Inf", values.Get("ratio"))
+
+	err = decoder.Decode(&decoded, url.Values{"date": {"2024-12-24T00:00:00Z"}}, nil)
+	NotNil(t, err)
+}
+
+func TestDecoder_SetLocation(t *testing.T) {
+	t.Parallel()
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	NoError(t, err)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	NoError(t, err)
+
+	type Test struct {
+		At     time.Time   `form:"at,layout=2006-01-02T15:04,tz=Europe/Paris"`
+		Starts []time.Time `form:"starts,layout=2006-01-02T15:04"`
+		Due    *time.Time  `form:"due"`
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.SetLocation(tokyo)
+
+	var test Test
+
+	err = decoder.Decode(&test, url.Values{
+		"at":     {"2024-07-01T09:30"},
+		"starts": {"2024-07-01T10:00"},
+		"due":    {"2024-07-01T12:00:00Z"},
+	}, nil)
+	NoError(t, err)
+	True(t, test.At.Equal(time.Date(2024, 7, 1, 9, 30, 0, 0, paris)))
+	True(t, test.Starts[0].Equal(time.Date(2024, 7, 1, 10, 0, 0, 0, tokyo)))
+
+	// values with zone keep it
+	True(t, test.Due.Equal(time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)))
+
+	// the encoder writes times in the layout and zone of the field
+	values, err := NewEncoder().Encode(Test{At: time.Date(2024, 7, 1, 7, 30, 0, 0, time.UTC)})
+	NoError(t, err)
+	Equal(t, "2024-07-01T09:30", values.Get("at"))
+
+	type Invalid struct {
+		At time.Time `form:"at,tz=Mars/Olympus"`
+	}
+
+	var invalid Invalid
+
+	err = decoder.Decode(&invalid, url.Values{"at": {"2024-07-01T09:30:00Z"}}, nil)
+	NotNil(t, err)
+	Equal(t, "invalid tag option 'tz=Mars/Olympus' namespace 'at'", err.(DecodeErrors)["at"].Error())
+}
+
+func TestUnixTime(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Created time.Time   `form:"created,unix"`
+		Updated *time.Time  `form:"updated,unixmilli"`
+		Seen    []time.Time `form:"seen,unixnano"`
+		Plain   time.Time   `form:"plain"`
+	}
+
+	at := time.Date(2024, 7, 1, 9, 30, 15, 123456789, time.UTC)
+	updated := at.Truncate(time.Millisecond)
+
+	test := Test{Created: at.Truncate(time.Second), Updated: &updated, Seen: []time.Time{at}, Plain: at.Truncate(time.Second)}
+
+	values, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"created": {"1719826215"},
+		"updated": {"1719826215123"},
+		"seen[0]": {"1719826215123456789"},
+		"plain":   {"2024-07-01T09:30:15Z"},
+	}, values)
+
+	decoder := NewDecoder[any]()
+
+	var decoded Test
+
+	NoError(t, decoder.Decode(&decoded, values, nil))
+	Equal(t, test, decoded)
+
+	err = decoder.Decode(&decoded, url.Values{"created": {"2024-07-01T09:30:15Z"}}, nil)
+	NotNil(t, err)
+	NotNil(t, err.(DecodeErrors)["created"])
+}
+
+func TestDecoder_SetTimeLayouts(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Times []time.Time `form:"times"`
+		Date  time.Time   `form:"date,layout=2006-01-02"`
+	}
+
+	decoder := NewDecoder[any]()
+	decoder.SetTimeLayouts("2006-01-02T15:04", time.DateOnly)
+	decoder.SetLocation(time.UTC)
+
+	var test Test
+
+	err := decoder.Decode(&test, url.Values{"times": {"2024-07-01T09:30:15Z", "2024-07-01T09:30", "2024-07-01"}}, nil)
+	NoError(t, err)
+	Equal(t, []time.Time{
+		time.Date(2024, 7, 1, 9, 30, 15, 0, time.UTC),
+		time.Date(2024, 7, 1, 9, 30, 0, 0, time.UTC),
+		time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	}, test.Times)
+
+	// errors of the first layout are reported
+	err = decoder.Decode(&test, url.Values{"times": {"01.07.2024"}}, nil)
+	NotNil(t, err)
+
+	var perr *time.ParseError
+
+	True(t, errors.As(err.(DecodeErrors)["times"], &perr))
+	Equal(t, time.RFC3339, perr.Layout)
+
+	// `layout` tag option accepts only its layout
+	err = decoder.Decode(&test, url.Values{"date": {"2024-07-01T09:30"}}, nil)
+	NotNil(t, err)
+	NotNil(t, err.(DecodeErrors)["date"])
+}
@@ -0,0 +1,38 @@
+package form
+
+import (
+	"net/url"
+	"sync"
+)
+
+var (
+	defaultDecoderOnce sync.Once
+	defaultDecoder     *Decoder[any]
+
+	defaultEncoderOnce sync.Once
+	defaultEncoder     *Encoder
+)
+
+// Unmarshal decodes values into a new value of T with a default Decoder.
+//
+// As with Decode, the value is returned along with decoding errors.
+func Unmarshal[T any](values url.Values) (T, error) {
+	defaultDecoderOnce.Do(func() {
+		defaultDecoder = NewDecoder[any]()
+	})
+
+	var v T
+
+	err := defaultDecoder.Decode(&v, values, nil)
+
+	return v, err
+}
+
+// Marshal encodes v into url.Values with a default Encoder.
+func Marshal[T any](v T) (url.Values, error) {
+	defaultEncoderOnce.Do(func() {
+		defaultEncoder = NewEncoder()
+	})
+
+	return defaultEncoder.Encode(v)
+}
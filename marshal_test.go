@@ -0,0 +1,33 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name string   `form:"name"`
+		Tags []string `form:"tags"`
+	}
+
+	values, err := Marshal(Test{Name: "joe", Tags: []string{"a", "b"}})
+	NoError(t, err)
+	Equal(t, url.Values{"name": {"joe"}, "tags": {"a", "b"}}, values)
+
+	test, err := Unmarshal[Test](values)
+	NoError(t, err)
+	Equal(t, Test{Name: "joe", Tags: []string{"a", "b"}}, test)
+
+	n, err := Unmarshal[int](url.Values{"": {"x"}})
+	NotNil(t, err)
+	Equal(t, 0, n)
+
+	ptr, err := Unmarshal[*Test](url.Values{"name": {"bob"}})
+	NoError(t, err)
+	Equal(t, &Test{Name: "bob"}, ptr)
+}
@@ -0,0 +1,96 @@
+package form
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameTransform transforms a struct field name into a key name,
+// it is applied to fields without an explicit name in the tag.
+type NameTransform func(name string) string
+
+// SnakeCase transforms field names into snake_case, eg. "UserID" into "user_id".
+func SnakeCase(name string) string {
+	return joinWords(splitWords(name), '_')
+}
+
+// KebabCase transforms field names into kebab-case, eg. "UserID" into "user-id".
+func KebabCase(name string) string {
+	return joinWords(splitWords(name), '-')
+}
+
+// CamelCase transforms field names into camelCase, eg. "UserID" into "userId".
+func CamelCase(name string) string {
+	words := splitWords(name)
+
+	var b strings.Builder
+
+	b.Grow(len(name))
+
+	for i, w := range words {
+		if i == 0 {
+			b.WriteString(strings.ToLower(w))
+
+			continue
+		}
+
+		r := []rune(strings.ToLower(w))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	return b.String()
+}
+
+// joinWords joins lower cased words with a separator.
+func joinWords(words []string, sep byte) string {
+	var b strings.Builder
+
+	b.Grow(len(words) * 8)
+
+	for i, w := range words {
+		if i > 0 {
+			b.WriteByte(sep)
+		}
+
+		b.WriteString(strings.ToLower(w))
+	}
+
+	return b.String()
+}
+
+// splitWords splits a Go identifier into words, acronyms are kept together,
+// eg. "HTTPServerID" into "HTTP", "Server" and "ID". Digits belong to the preceding word
+// and underscores separate words.
+func splitWords(name string) []string {
+	r := []rune(name)
+	words := make([]string, 0, 4)
+	start := 0
+
+	for i := 0; i < len(r); i++ {
+		switch {
+		case r[i] == '_':
+			if i > start {
+				words = append(words, string(r[start:i]))
+			}
+
+			start = i + 1
+
+		case i > start && unicode.IsUpper(r[i]):
+			prev := r[i-1]
+
+			// boundary after a lower case letter or digit, or at last upper case letter of an acronym
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+				words = append(words, string(r[start:i]))
+				start = i
+			}
+		}
+	}
+
+	if start < len(r) {
+		words = append(words, string(r[start:]))
+	}
+
+	return words
+}
@@ -0,0 +1,79 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestNameTransforms(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		snake string
+		kebab string
+		camel string
+	}{
+		{"Name", "name", "name", "name"},
+		{"UserID", "user_id", "user-id", "userId"},
+		{"HTTPServer", "http_server", "http-server", "httpServer"},
+		{"ID", "id", "id", "id"},
+		{"Address2Line", "address2_line", "address2-line", "address2Line"},
+		{"already_snake", "already_snake", "already-snake", "alreadySnake"},
+	}
+
+	for _, tt := range tests {
+		Equal(t, tt.snake, SnakeCase(tt.name))
+		Equal(t, tt.kebab, KebabCase(tt.name))
+		Equal(t, tt.camel, CamelCase(tt.name))
+	}
+}
+
+func TestNameTransformDecodeEncode(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		StreetName string
+	}
+
+	type Test struct {
+		UserID   int
+		FullName string `form:"name"`
+		HomeAddr Address
+		Ignored  string `form:"-"`
+	}
+
+	values := url.Values{
+		"user_id":               {"7"},
+		"name":                  {"joe"},
+		"home_addr.street_name": {"Main"},
+		"ignored":               {"x"},
+		"UserID":                {"8"},
+	}
+
+	d := NewDecoder[any]()
+	d.SetNameTransform(SnakeCase)
+
+	var test Test
+	NoError(t, d.Decode(&test, values, nil))
+	Equal(t, Test{UserID: 7, FullName: "joe", HomeAddr: Address{StreetName: "Main"}}, test)
+
+	e := NewEncoder()
+	e.SetNameTransform(SnakeCase)
+
+	encoded, err := e.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"user_id":               {"7"},
+		"name":                  {"joe"},
+		"home_addr.street_name": {"Main"},
+	}, encoded)
+
+	e.SetNameTransform(nil)
+
+	encoded, err = e.Encode(test)
+	NoError(t, err)
+	Equal(t, []string{"7"}, encoded["UserID"])
+}
@@ -0,0 +1,69 @@
+package form
+
+import (
+	"reflect"
+)
+
+// Namespace marks a struct with the namespace of its keys when it is the decoded or encoded value,
+// set by the tag of a field of this type, so that top-level keys can be wrapped without a wrapper struct.
+//
+//	type User struct {
+//		form.Namespace `form:"user"`
+//		Name string    `form:"name"` // user.name
+//	}
+type Namespace struct{}
+
+// Namespacer is implemented by structs providing the namespace of their keys when they are the decoded
+// or encoded value like Namespace, the method is called on the zero value once per type.
+type Namespacer interface {
+	FormNamespace() string
+}
+
+var (
+	namespaceType  = reflect.TypeOf(Namespace{})
+	namespacerType = reflect.TypeOf((*Namespacer)(nil)).Elem()
+)
+
+// typeNamespace returns the namespace of a struct type implementing Namespacer by value or by pointer.
+func typeNamespace(t reflect.Type) string {
+	switch {
+	case t.Implements(namespacerType):
+		return reflect.Zero(t).Interface().(Namespacer).FormNamespace() //nolint:errcheck
+	case reflect.PointerTo(t).Implements(namespacerType):
+		return reflect.New(t).Interface().(Namespacer).FormNamespace() //nolint:errcheck
+	}
+
+	return blank
+}
+
+// rootNamespace returns the namespace of keys of the decoded struct type.
+func (d *decoder[DecodeFuncArgument]) rootNamespace(typ reflect.Type) []byte {
+	s, ok := d.structCache.Get(d.opts.Mode, typ, d.opts.TagName)
+	if !ok {
+		s = d.structCache.parseStruct(d.opts.Mode, typ, d.opts.TagName)
+	}
+
+	return append(d.namespace[0:0], s.namespace...)
+}
+
+// rootNamespace returns the namespace of keys of the struct type as the decoded value, see StructSpec.
+func (d *Decoder[DecodeFuncArgument]) rootNamespace(typ reflect.Type) string {
+	structCache := d.structCache.Load()
+
+	s, ok := structCache.Get(d.opts.Mode, typ, d.opts.TagName)
+	if !ok {
+		s = structCache.parseStruct(d.opts.Mode, typ, d.opts.TagName)
+	}
+
+	return s.namespace
+}
+
+// rootNamespace returns the namespace of keys of the encoded struct type.
+func (e *encoder) rootNamespace(typ reflect.Type) []byte {
+	s, ok := e.structCache.Get(e.e.mode, typ, e.e.tagName)
+	if !ok {
+		s = e.structCache.parseStruct(e.e.mode, typ, e.e.tagName)
+	}
+
+	return append(e.namespace[0:0], s.namespace...)
+}
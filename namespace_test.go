@@ -0,0 +1,85 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type namespacedAddress struct {
+	City string `form:"city"`
+}
+
+type namespacedUser struct {
+	Namespace `form:"user"`
+	Name      string            `form:"name"`
+	Address   namespacedAddress `form:"address"`
+	Tags      []string          `form:"tags"`
+}
+
+type namespacedAccount struct {
+	ID int `form:"id"`
+}
+
+func (*namespacedAccount) FormNamespace() string {
+	return "account"
+}
+
+type namespacedWrapper struct {
+	User namespacedUser `form:"owner"`
+}
+
+func TestNamespaceField(t *testing.T) {
+	t.Parallel()
+
+	user := namespacedUser{Name: "Alex", Address: namespacedAddress{City: "Oslo"}, Tags: []string{"a"}}
+	values := url.Values{
+		"user.name":         {"Alex"},
+		"user.address.city": {"Oslo"},
+		"user.tags":         {"a"},
+	}
+
+	encoded, err := NewEncoder().Encode(&user)
+	NoError(t, err)
+	Equal(t, values, encoded)
+
+	var decoded namespacedUser
+
+	err = NewDecoder[any]().Decode(&decoded, values, nil)
+	NoError(t, err)
+	Equal(t, user, decoded)
+
+	// keys of other styles are wrapped the same way
+	encoder := NewEncoder()
+	encoder.SetKeyStyle(StyleRails)
+
+	encoded, err = encoder.Encode(user)
+	NoError(t, err)
+	Equal(t, []string{"Oslo"}, encoded["user[address][city]"])
+
+	// nested structs are keyed by their fields
+	encoded, err = NewEncoder().Encode(namespacedWrapper{User: user})
+	NoError(t, err)
+	Equal(t, []string{"Alex"}, encoded["owner.name"])
+}
+
+func TestNamespacer(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := NewEncoder().Encode(namespacedAccount{ID: 1})
+	NoError(t, err)
+	Equal(t, url.Values{"account.id": {"1"}}, encoded)
+
+	var pairs []Pair
+
+	pairs, err = NewEncoder().EncodePairs(namespacedAccount{ID: 2})
+	NoError(t, err)
+	Equal(t, []Pair{{Key: "account.id", Value: "2"}}, pairs)
+
+	var decoded namespacedAccount
+
+	err = NewDecoder[any]().Decode(&decoded, url.Values{"account.id": {"3"}, "id": {"4"}}, nil)
+	NoError(t, err)
+	Equal(t, 3, decoded.ID)
+}
@@ -0,0 +1,421 @@
+package form
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// OpenAPIParameter is an OpenAPI 3 parameter object, it marshals to JSON as in the specification.
+type OpenAPIParameter struct {
+	// Name is the key of the parameter, eg. "page" or "filter.role".
+	Name string `json:"name"`
+
+	// In is the location of the parameter, "query" or the `src` tag option, eg. "path" or "header".
+	In string `json:"in"`
+
+	// Required is set by `required` tag option and for path parameters.
+	Required bool `json:"required,omitempty"`
+
+	// Style and Explode are set for arrays and objects not serialized as the OpenAPI default, eg. "deepObject".
+	Style   string `json:"style,omitempty"`
+	Explode *bool  `json:"explode,omitempty"`
+
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema is the subset of an OpenAPI 3 schema object describing parameters.
+type OpenAPISchema struct {
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Default              interface{}               `json:"default,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+}
+
+var (
+	urlType         = reflect.TypeOf(url.URL{})
+	mailAddressType = reflect.TypeOf(mail.Address{})
+)
+
+// OpenAPIParameters returns OpenAPI 3 parameters of a struct type as the decoder reads them, see StructSpec,
+// so that API documentation follows the binding code. Types, formats and `min`, `max`, `len`, `match`,
+// `enum`, `required` and `default` tags are described, `src` tag option sets the location of a parameter
+// and fields with `src=body` are left out. Fields of elements of slices and maps can not be described
+// as parameters and are left out too.
+//
+// It returns an error wrapping ErrInvalidTagOption for constraint or style options the decoder would reject.
+func (d *Decoder[DecodeFuncArgument]) OpenAPIParameters(typ reflect.Type) ([]OpenAPIParameter, error) {
+	specs, err := d.StructSpec(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := d.funcs.Load()
+
+	// parents are nested structs enclosing the current field, their fields are keyed by key unless flattened
+	type parent struct {
+		namespace string
+		key       string
+	}
+
+	var (
+		params  = make([]OpenAPIParameter, 0, len(specs))
+		parents []parent
+	)
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	// fields of a struct type with a namespace are keyed under it, see Namespace
+	if root := d.rootNamespace(typ); root != blank {
+		parents = append(parents, parent{namespace: root + d.opts.NamespacePrefix, key: root})
+	}
+
+	for i := 0; i < len(specs); i++ {
+		f := specs[i]
+
+		if strings.Contains(f.Namespace, "[]") {
+			continue
+		}
+
+		for len(parents) > 0 && !strings.HasPrefix(f.Namespace, parents[len(parents)-1].namespace) {
+			parents = parents[:len(parents)-1]
+		}
+
+		key := f.Name
+
+		if len(parents) > 0 && parents[len(parents)-1].key != blank {
+			key = parents[len(parents)-1].key + d.opts.NamespacePrefix + f.Name + d.opts.NamespaceSuffix
+		}
+
+		options := specOptions(f.Options)
+
+		in, _ := options.get("src")
+		if in == srcBody {
+			continue
+		}
+
+		if in == blank {
+			in = srcQuery
+		}
+
+		style, explode, valid := parseParamStyle(options)
+		if !valid {
+			name, _ := options.get("style")
+
+			return nil, newError(ErrInvalidTagOption, "invalid tag option '%s' namespace '%s'", "style="+name, f.Namespace)
+		}
+
+		if style == ParamNative {
+			style, explode = d.opts.ParamStyle, d.opts.ParamExplode
+		}
+
+		param := OpenAPIParameter{Name: key, In: in, Required: in == srcPath || options.has("required")}
+
+		if f.Nested {
+			if f.Kind != reflect.Struct {
+				continue
+			}
+
+			childPrefix := f.Namespace + d.opts.NamespacePrefix
+
+			switch {
+			case style == ParamDeepObject || style == ParamForm && !explode:
+				// the struct is a single parameter, its fields are properties
+				param.Schema = &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+				param.setStyle(style, explode)
+
+				for ; i+1 < len(specs) && strings.HasPrefix(specs[i+1].Namespace, childPrefix); i++ {
+					c := specs[i+1]
+
+					if c.Namespace != childPrefix+c.Name+d.opts.NamespaceSuffix {
+						continue
+					}
+
+					if param.Schema.Properties[c.Name], err = d.openAPIFieldSchema(c, funcs); err != nil {
+						return nil, err
+					}
+				}
+
+				params = append(params, param)
+			case style == ParamForm:
+				// fields of exploded structs are keyed by their names as the decoder flattens them
+				parents = append(parents, parent{namespace: childPrefix, key: blank})
+
+				if len(parents) > 1 {
+					parents[len(parents)-1].key = parents[len(parents)-2].key
+				}
+			default:
+				parents = append(parents, parent{namespace: childPrefix, key: key})
+			}
+
+			continue
+		}
+
+		if param.Schema, err = d.openAPIFieldSchema(f, funcs); err != nil {
+			return nil, err
+		}
+
+		switch f.Kind {
+		case reflect.Slice, reflect.Array:
+			if param.Schema.Type != "array" {
+				break
+			}
+
+			if style != ParamNative && style != ParamDeepObject {
+				param.setStyle(style, explode)
+
+				break
+			}
+
+			switch delim, _ := options.get("delim"); delim {
+			case ",":
+				param.setStyle(ParamForm, false)
+			case " ":
+				param.setStyle(ParamSpaceDelimited, false)
+			case "|":
+				param.setStyle(ParamPipeDelimited, false)
+			}
+		case reflect.Map:
+			switch {
+			case style == ParamDeepObject || style == ParamForm && !explode:
+				param.setStyle(style, explode)
+			case d.opts.NamespacePrefix == "[":
+				param.setStyle(ParamDeepObject, true)
+			}
+		}
+
+		params = append(params, param)
+	}
+
+	return params, nil
+}
+
+// setStyle sets style and explode of the parameter.
+func (p *OpenAPIParameter) setStyle(style ParamStyle, explode bool) {
+	for name, s := range paramStyles {
+		if s == style {
+			p.Style = name
+		}
+	}
+
+	p.Explode = &explode
+}
+
+// openAPIFieldSchema returns the schema of a field with its constraints and default.
+func (d *Decoder[DecodeFuncArgument]) openAPIFieldSchema(f FieldSpec, funcs *decodeFuncs[DecodeFuncArgument]) (*OpenAPISchema, error) {
+	schema := openAPISchema(f.Type, funcs)
+
+	if f.Nested {
+		return schema, nil
+	}
+
+	c := parseConstraints(f.Type, specOptions(f.Options))
+
+	if c != nil {
+		invalid := c.invalid
+		if invalid == blank {
+			invalid = c.invalidEnum
+		}
+
+		if invalid != blank {
+			return nil, newError(ErrInvalidTagOption, "invalid tag option '%s' namespace '%s'", invalid, f.Namespace)
+		}
+
+		for _, o := range c.options {
+			schema.constrain(o, c)
+		}
+
+		if c.enum != nil {
+			schema.constrain(tagOption{name: "enum", value: c.enumParam}, c)
+		}
+	}
+
+	if f.Default != blank {
+		schema.Default = d.openAPIDefault(f.Type, schema, f.Default)
+	}
+
+	return schema, nil
+}
+
+// constrain applies a constraint option to the schema, see parseConstraints.
+func (s *OpenAPISchema) constrain(o tagOption, c *fieldConstraints) {
+	if !c.isLength {
+		switch o.name {
+		case "min":
+			s.Minimum = &c.min
+		case "max":
+			s.Maximum = &c.max
+		}
+
+		return
+	}
+
+	minLen, maxLen := &s.MinLength, &s.MaxLength
+	if s.Type == "array" {
+		minLen, maxLen = &s.MinItems, &s.MaxItems
+	}
+
+	values := s
+	if s.Items != nil {
+		values = s.Items
+	} else if s.AdditionalProperties != nil {
+		values = s.AdditionalProperties
+	}
+
+	switch o.name {
+	case "min":
+		n := int(c.min)
+		*minLen = &n
+	case "max":
+		n := int(c.max)
+		*maxLen = &n
+	case "len":
+		*minLen, *maxLen = &c.length, &c.length
+	case "match":
+		values.Pattern = o.value
+	case "enum":
+		for _, v := range strings.Split(o.value, "|") {
+			values.Enum = append(values.Enum, v)
+		}
+	}
+
+	// lengths of maps are not described
+	if s.AdditionalProperties != nil {
+		s.MinLength, s.MaxLength = nil, nil
+	}
+}
+
+// openAPISchema returns the schema of values of typ as the decoder converts them.
+func openAPISchema[DecodeFuncArgument any](typ reflect.Type, funcs *decodeFuncs[DecodeFuncArgument]) *OpenAPISchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if _, ok := funcs.customTypeFuncs[typ]; ok {
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	switch {
+	case typ == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case typ == durationType:
+		return &OpenAPISchema{Type: "string", Format: "duration"}
+	case typ == uuidType || isUUIDType(typ):
+		return &OpenAPISchema{Type: "string", Format: "uuid"}
+	case typ == urlType:
+		return &OpenAPISchema{Type: "string", Format: "uri"}
+	case typ == mailAddressType:
+		return &OpenAPISchema{Type: "string", Format: "email"}
+	case reflect.PointerTo(typ).Implements(textUnmarshalerType):
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	var zero float64
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16:
+		return &OpenAPISchema{Type: "integer", Format: "int32", Minimum: &zero}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: openAPISchema(typ.Elem(), funcs)}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: openAPISchema(typ.Elem(), funcs)}
+	case reflect.Struct:
+		return &OpenAPISchema{Type: "object"}
+	default:
+		return &OpenAPISchema{Type: "string"}
+	}
+}
+
+// openAPIDefault returns the value of a `default` tag in the type of the schema, it is converted
+// by the decoder as defaults are. Values that can not be converted are returned as they are.
+func (d *Decoder[DecodeFuncArgument]) openAPIDefault(typ reflect.Type, schema *OpenAPISchema, s string) interface{} {
+	if schema.Type == "string" {
+		return s
+	}
+
+	var argument DecodeFuncArgument
+
+	v := reflect.New(typ)
+
+	if err := d.decodeValues(v.Interface(), url.Values{blank: {s}}, argument, nil); err != nil {
+		return s
+	}
+
+	if value := openAPIValue(v.Elem()); value != nil {
+		return value
+	}
+
+	return s
+}
+
+// openAPIValue returns a decoded value as a JSON value, nil for values of other kinds.
+func openAPIValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		values := make([]interface{}, v.Len())
+
+		for i := range values {
+			if values[i] = openAPIValue(v.Index(i)); values[i] == nil {
+				return nil
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// specOptions returns tag options of a field spec.
+func specOptions(options []TagOption) tagOptions {
+	o := make(tagOptions, len(options))
+
+	for i, opt := range options {
+		o[i] = tagOption{name: opt.Name, value: opt.Value}
+	}
+
+	return o
+}
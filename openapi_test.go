@@ -0,0 +1,148 @@
+package form
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type openAPIFilter struct {
+	Role   string `form:"role,enum=admin|user"`
+	Active bool   `form:"active"`
+}
+
+type openAPIQuery struct {
+	ID      UUID              `form:"id,src=path"`
+	Page    int               `form:"page,min=1" default:"1"`
+	Size    uint8             `form:"size,max=100"`
+	Sort    string            `form:"sort,required,enum=asc|desc" default:"asc"`
+	Tags    []string          `form:"tags,max=3,match=^[a-z]+$"`
+	IDs     []int             `form:"ids,delim=|"`
+	Since   *time.Time        `form:"since"`
+	Token   string            `form:"X-Token,src=header"`
+	Filter  openAPIFilter     `form:"filter"`
+	Range   openAPIFilter     `form:"range,style=deepObject"`
+	Labels  map[string]string `form:"labels"`
+	Items   []openAPIFilter   `form:"items"`
+	Payload string            `form:"payload,src=body"`
+}
+
+func TestDecoder_OpenAPIParameters(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder[any]()
+
+	params, err := decoder.OpenAPIParameters(reflect.TypeOf(openAPIQuery{}))
+	NoError(t, err)
+
+	names := make([]string, 0, len(params))
+
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+
+	Equal(t, []string{
+		"id", "page", "size", "sort", "tags", "ids", "since", "X-Token", "filter.role", "filter.active", "range", "labels",
+	}, names)
+
+	one, zero, hundred := 1.0, 0.0, 100.0
+	three := 3
+	f := false
+	explode := true
+
+	Equal(t, OpenAPIParameter{Name: "id", In: "path", Required: true, Schema: &OpenAPISchema{Type: "string", Format: "uuid"}}, params[0])
+	Equal(t, &OpenAPISchema{Type: "integer", Format: "int64", Minimum: &one, Default: int64(1)}, params[1].Schema)
+	Equal(t, &OpenAPISchema{Type: "integer", Format: "int32", Minimum: &zero, Maximum: &hundred}, params[2].Schema)
+	True(t, params[3].Required)
+	Equal(t, &OpenAPISchema{Type: "string", Default: "asc", Enum: []interface{}{"asc", "desc"}}, params[3].Schema)
+	Equal(t, &OpenAPISchema{Type: "array", MaxItems: &three, Items: &OpenAPISchema{Type: "string", Pattern: "^[a-z]+$"}}, params[4].Schema)
+	Equal(t, "pipeDelimited", params[5].Style)
+	Equal(t, &f, params[5].Explode)
+	Equal(t, &OpenAPISchema{Type: "string", Format: "date-time"}, params[6].Schema)
+	Equal(t, "header", params[7].In)
+	Equal(t, &OpenAPISchema{Type: "string", Enum: []interface{}{"admin", "user"}}, params[8].Schema)
+	Equal(t, "deepObject", params[10].Style)
+	Equal(t, &explode, params[10].Explode)
+	Equal(t, &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{
+		"role":   {Type: "string", Enum: []interface{}{"admin", "user"}},
+		"active": {Type: "boolean"},
+	}}, params[10].Schema)
+	Equal(t, &OpenAPISchema{Type: "object", AdditionalProperties: &OpenAPISchema{Type: "string"}}, params[11].Schema)
+	Empty(t, params[11].Style)
+
+	b, err := json.Marshal(params[1])
+	NoError(t, err)
+	Equal(t, `{"name":"page","in":"query","schema":{"type":"integer","format":"int64","default":1,"minimum":1}}`, string(b))
+
+	// maps in brackets and exploded structs as the decoder reads them
+	decoder.SetKeyStyle(StyleRails)
+	decoder.SetParamStyle(ParamForm, true)
+
+	params, err = decoder.OpenAPIParameters(reflect.TypeOf(&openAPIQuery{}))
+	NoError(t, err)
+	Equal(t, "role", params[8].Name)
+	Equal(t, "active", params[9].Name)
+	Equal(t, "labels", params[11].Name)
+	Equal(t, "deepObject", params[11].Style)
+}
+
+func TestDecoder_OpenAPIParametersNamespace(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Namespace `form:"q"`
+		Page      int           `form:"page"`
+		Filter    openAPIFilter `form:"filter"`
+	}
+
+	decoder := NewDecoder[any]()
+
+	specs, err := decoder.StructSpec(reflect.TypeOf(Test{}))
+	NoError(t, err)
+	Equal(t, "q.page", specs[0].Namespace)
+
+	params, err := decoder.OpenAPIParameters(reflect.TypeOf(&Test{}))
+	NoError(t, err)
+
+	names := make([]string, 0, len(params))
+
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+
+	Equal(t, []string{"q.page", "q.filter.role", "q.filter.active"}, names)
+
+	// documented names are the keys the decoder reads
+	var test Test
+
+	NoError(t, decoder.Decode(&test, url.Values{"q.page": {"2"}, "q.filter.role": {"admin"}}, nil))
+	Equal(t, 2, test.Page)
+	Equal(t, "admin", test.Filter.Role)
+
+	decoder.SetKeyStyle(StyleRails)
+
+	params, err = decoder.OpenAPIParameters(reflect.TypeOf(Test{}))
+	NoError(t, err)
+	Equal(t, "q[page]", params[0].Name)
+	Equal(t, "q[filter][role]", params[1].Name)
+}
+
+func TestDecoder_OpenAPIParametersInvalid(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Num int `form:"num,match=^1"`
+	}
+
+	_, err := NewDecoder[any]().OpenAPIParameters(reflect.TypeOf(Test{}))
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidTagOption))
+
+	_, err = NewDecoder[any]().OpenAPIParameters(reflect.TypeOf(""))
+	True(t, errors.Is(err, ErrUnsupportedType))
+}
@@ -0,0 +1,103 @@
+package form
+
+import "reflect"
+
+// Optional is a scalar value that tracks whether it was set and whether it was explicitly cleared,
+// eg. for PATCH-style updates. Decoding a present key sets the value and decoding an empty value
+// clears it, values of missing keys stay unset. It implements Clearable, see Encoder.SetClearedMode.
+type Optional[T any] struct {
+	value   T
+	set     bool
+	cleared bool
+}
+
+// Some returns an Optional set to value v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// Cleared returns an Optional which is explicitly cleared.
+func Cleared[T any]() Optional[T] {
+	return Optional[T]{set: true, cleared: true}
+}
+
+// optional is implemented by pointers to Optional, decoders and encoders convert the value
+// with their own settings and registered functions instead of the text methods.
+type optional interface {
+	Clearable
+	Clear()
+
+	// optionalValue returns the addressable value.
+	optionalValue() reflect.Value
+
+	// markSet marks the value set and not cleared after it is decoded.
+	markSet()
+}
+
+func (o *Optional[T]) optionalValue() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+func (o *Optional[T]) markSet() {
+	o.set, o.cleared = true, false
+}
+
+// Get returns the value and whether it was set and not cleared.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set && !o.cleared
+}
+
+// Set sets the value.
+func (o *Optional[T]) Set(v T) {
+	*o = Some(v)
+}
+
+// Clear marks the value explicitly cleared.
+func (o *Optional[T]) Clear() {
+	*o = Cleared[T]()
+}
+
+// IsSet reports whether a value was provided, including a cleared one.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsCleared reports whether the value was explicitly cleared.
+func (o Optional[T]) IsCleared() bool {
+	return o.cleared
+}
+
+// MarshalText implements encoding.TextMarshaler, the value is encoded with a default Encoder.
+// Encoders encode fields of Optional values with their own settings and registered functions.
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	if o.cleared {
+		return nil, nil
+	}
+
+	values, err := Marshal(o.value)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(values.Get(blank)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, the value is decoded with a default Decoder
+// and empty text clears it. Decoders decode fields of Optional values with their own settings
+// and registered functions.
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		o.Clear()
+
+		return nil
+	}
+
+	v, err := Unmarshal[T](map[string][]string{blank: {string(text)}})
+	if err != nil {
+		return err
+	}
+
+	o.Set(v)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestOptional(t *testing.T) {
+	t.Parallel()
+
+	type Patch struct {
+		Name  Optional[string] `form:"name"`
+		Age   Optional[int]    `form:"age"`
+		Email Optional[string] `form:"email"`
+	}
+
+	var patch Patch
+
+	d := NewDecoder[any]()
+	NoError(t, d.Decode(&patch, url.Values{"name": {""}, "age": {"42"}}, nil))
+
+	True(t, patch.Name.IsSet())
+	True(t, patch.Name.IsCleared())
+
+	age, ok := patch.Age.Get()
+	True(t, ok)
+	Equal(t, 42, age)
+
+	False(t, patch.Email.IsSet())
+
+	_, ok = patch.Email.Get()
+	False(t, ok)
+
+	err := d.Decode(&patch, url.Values{"age": {"old"}}, nil)
+	NotNil(t, err)
+
+	e := NewEncoder()
+	e.SetClearedMode(ClearedEmpty)
+
+	values, err := e.Encode(Patch{Name: Cleared[string](), Age: Some(7)})
+	NoError(t, err)
+	Equal(t, url.Values{"name": {""}, "age": {"7"}}, values)
+
+	e.SetClearedMode(ClearedDefault)
+
+	values, err = e.Encode(Patch{Name: Cleared[string](), Age: Some(7)})
+	NoError(t, err)
+	Equal(t, url.Values{"name": {""}, "age": {"7"}, "email": {""}}, values)
+
+	var o Optional[int]
+	o.Set(1)
+	o.Clear()
+	True(t, o.IsCleared())
+}
+
+func TestOptionalSettings(t *testing.T) {
+	t.Parallel()
+
+	type Patch struct {
+		Active Optional[bool]   `form:"active"`
+		Tags   []Optional[bool] `form:"tags"`
+	}
+
+	d := NewDecoder[any]()
+	d.SetBoolStrings([]string{"ja"}, []string{"nein"})
+
+	var patch Patch
+	NoError(t, d.Decode(&patch, url.Values{"active": {"ja"}, "tags": {"nein", ""}}, nil))
+
+	active, ok := patch.Active.Get()
+	True(t, ok)
+	True(t, active)
+	Len(t, patch.Tags, 2)
+	True(t, patch.Tags[0].IsSet())
+	True(t, patch.Tags[1].IsCleared())
+
+	e := NewEncoder()
+	e.RegisterFunc(func(x interface{}) (string, error) {
+		if x.(bool) {
+			return "ja", nil
+		}
+
+		return "nein", nil
+	}, true)
+
+	values, err := e.Encode(Patch{Active: Some(true), Tags: []Optional[bool]{Some(false), Cleared[bool]()}})
+	NoError(t, err)
+	Equal(t, url.Values{"active": {"ja"}, "tags[0]": {"nein"}, "tags[1]": {""}}, values)
+}
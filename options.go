@@ -0,0 +1,204 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnsupportedOption is returned by NewDecoderWith and NewEncoderWith for options of settings
+// that only the other one has, eg. WithMaxArraySize for an encoder.
+var ErrUnsupportedOption = errors.New("form: unsupported option")
+
+// Option configures a Decoder created by NewDecoderWith or an Encoder created by NewEncoderWith.
+type Option func(s settings) error
+
+// settings are common to Decoder and Encoder.
+type settings interface {
+	SetTagName(tagName string)
+	SetMode(mode Mode)
+	SetNamespacePrefix(namespacePrefix string)
+	SetNamespaceSuffix(namespaceSuffix string)
+	SetIndexBase(base uint)
+	SetArraySyntax(syntax ArraySyntax)
+	SetNameTransform(fn NameTransform)
+}
+
+// decoderSettings are only supported by Decoder.
+type decoderSettings interface {
+	SetMaxArraySize(size uint)
+	SetTimeout(timeout time.Duration)
+	SetMaxKeys(n uint)
+	SetMaxDepth(n uint)
+}
+
+// encoderSettings are only supported by Encoder.
+type encoderSettings interface {
+	SetAnonymousMode(mode AnonymousMode)
+}
+
+// NewDecoderWith creates a new decoder configured with options, eg.
+//
+//	d, err := form.NewDecoderWith[any](form.WithTagName("query"), form.WithNameTransform(form.SnakeCase))
+//
+// The configured decoder is immutable so that it can be shared, its Set* and Register* methods panic,
+// Clone returns a decoder that can be changed. An ErrUnsupportedOption error is returned for options
+// of encoder settings.
+func NewDecoderWith[DecodeFuncArgument any](opts ...Option) (*Decoder[DecodeFuncArgument], error) {
+	d := NewDecoder[DecodeFuncArgument]()
+
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return nil, err
+		}
+	}
+
+	d.frozen = true
+
+	return d, nil
+}
+
+// NewEncoderWith creates a new encoder configured with options, see NewDecoderWith.
+// An ErrUnsupportedOption error is returned for options of decoder settings.
+func NewEncoderWith(opts ...Option) (*Encoder, error) {
+	e := NewEncoder()
+
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// unsupportedOption returns the error of an option of settings s does not have.
+func unsupportedOption(name string, s settings) error {
+	return fmt.Errorf("%w '%s' of %T", ErrUnsupportedOption, name, s)
+}
+
+// WithTagName sets the field tag name, see Decoder.SetTagName.
+func WithTagName(tagName string) Option {
+	return func(s settings) error {
+		s.SetTagName(tagName)
+
+		return nil
+	}
+}
+
+// WithMode sets the mode, see Decoder.SetMode.
+func WithMode(mode Mode) Option {
+	return func(s settings) error {
+		s.SetMode(mode)
+
+		return nil
+	}
+}
+
+// WithNamespace sets prefix and suffix of nested struct field names, eg. "[" and "]" for "user[name]".
+func WithNamespace(prefix, suffix string) Option {
+	return func(s settings) error {
+		s.SetNamespacePrefix(prefix)
+		s.SetNamespaceSuffix(suffix)
+
+		return nil
+	}
+}
+
+// WithIndexBase sets the number of the first element in indexes, see Decoder.SetIndexBase.
+func WithIndexBase(base uint) Option {
+	return func(s settings) error {
+		s.SetIndexBase(base)
+
+		return nil
+	}
+}
+
+// WithArraySyntax sets syntax of array and slice indexes, see Decoder.SetArraySyntax.
+func WithArraySyntax(syntax ArraySyntax) Option {
+	return func(s settings) error {
+		s.SetArraySyntax(syntax)
+
+		return nil
+	}
+}
+
+// WithNameTransform sets the transform of field names without an explicit name in the tag,
+// see Decoder.SetNameTransform.
+func WithNameTransform(fn NameTransform) Option {
+	return func(s settings) error {
+		s.SetNameTransform(fn)
+
+		return nil
+	}
+}
+
+// WithMaxArraySize sets the maximum array size of the decoder, see Decoder.SetMaxArraySize.
+func WithMaxArraySize(size uint) Option {
+	return func(s settings) error {
+		ds, ok := s.(decoderSettings)
+		if !ok {
+			return unsupportedOption("WithMaxArraySize", s)
+		}
+
+		ds.SetMaxArraySize(size)
+
+		return nil
+	}
+}
+
+// WithTimeout sets the decoding timeout, see Decoder.SetTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s settings) error {
+		ds, ok := s.(decoderSettings)
+		if !ok {
+			return unsupportedOption("WithTimeout", s)
+		}
+
+		ds.SetTimeout(timeout)
+
+		return nil
+	}
+}
+
+// WithMaxKeys sets the maximum number of distinct keys of the decoder, see Decoder.SetMaxKeys.
+func WithMaxKeys(n uint) Option {
+	return func(s settings) error {
+		ds, ok := s.(decoderSettings)
+		if !ok {
+			return unsupportedOption("WithMaxKeys", s)
+		}
+
+		ds.SetMaxKeys(n)
+
+		return nil
+	}
+}
+
+// WithMaxDepth sets the maximum nesting depth of keys of the decoder, see Decoder.SetMaxDepth.
+func WithMaxDepth(n uint) Option {
+	return func(s settings) error {
+		ds, ok := s.(decoderSettings)
+		if !ok {
+			return unsupportedOption("WithMaxDepth", s)
+		}
+
+		ds.SetMaxDepth(n)
+
+		return nil
+	}
+}
+
+// WithAnonymousMode sets how the encoder names fields of embedded structs, see Encoder.SetAnonymousMode.
+func WithAnonymousMode(mode AnonymousMode) Option {
+	return func(s settings) error {
+		es, ok := s.(encoderSettings)
+		if !ok {
+			return unsupportedOption("WithAnonymousMode", s)
+		}
+
+		es.SetAnonymousMode(mode)
+
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		FullName string
+	}
+
+	type Test struct {
+		UserID int   `query:"uid"`
+		Inner  Inner `query:"inner"`
+		Items  []int
+	}
+
+	opts := []Option{
+		WithTagName("query"),
+		WithMode(ModeImplicit),
+		WithNamespace("[", "]"),
+		WithIndexBase(1),
+		WithNameTransform(SnakeCase),
+		WithMaxArraySize(3),
+		WithTimeout(time.Second),
+		WithMaxKeys(10),
+		WithMaxDepth(5),
+	}
+
+	d, err := NewDecoderWith[any](opts...)
+	NoError(t, err)
+
+	o := d.Options()
+	Equal(t, "query", o.TagName)
+	Equal(t, "[", o.NamespacePrefix)
+	Equal(t, "]", o.NamespaceSuffix)
+	Equal(t, 3, o.MaxArraySize)
+	Equal(t, time.Second, o.Timeout)
+	Equal(t, 10, o.MaxKeys)
+	Equal(t, 5, o.MaxDepth)
+
+	var test Test
+	NoError(t, d.Decode(&test, url.Values{"uid": {"1"}, "inner[full_name]": {"joe"}, "items[1]": {"7"}}, nil))
+	Equal(t, Test{UserID: 1, Inner: Inner{FullName: "joe"}, Items: []int{7}}, test)
+
+	e, err := NewEncoderWith(append(opts[:5:5], WithAnonymousMode(AnonymousSeparate))...)
+	NoError(t, err)
+
+	values, err := e.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"uid": {"1"}, "inner[full_name]": {"joe"}, "items": {"7"}}, values)
+
+	// configured decoders are immutable, their clones are not
+	Panics(t, func() { d.SetTagName("form") })
+	Panics(t, func() { d.RegisterFunc(nil, timeType) })
+	NotPanics(t, func() { d.Clone().SetTagName("form") })
+	Equal(t, "query", d.Options().TagName)
+}
+
+func TestNewWithUnsupportedOptions(t *testing.T) {
+	t.Parallel()
+
+	d, err := NewDecoderWith[any](WithTagName("query"), WithAnonymousMode(AnonymousSeparate))
+	Nil(t, d)
+	True(t, errors.Is(err, ErrUnsupportedOption))
+	Equal(t, "form: unsupported option 'WithAnonymousMode' of *form.Decoder[interface {}]", err.Error())
+
+	e, err := NewEncoderWith(WithMaxArraySize(3))
+	Nil(t, e)
+	True(t, errors.Is(err, ErrUnsupportedOption))
+	Equal(t, "form: unsupported option 'WithMaxArraySize' of *form.Encoder", err.Error())
+}
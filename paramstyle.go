@@ -0,0 +1,154 @@
+package form
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// paramStyles are styles by names of `style` tag option.
+var paramStyles = map[string]ParamStyle{
+	"form":           ParamForm,
+	"spaceDelimited": ParamSpaceDelimited,
+	"pipeDelimited":  ParamPipeDelimited,
+	"deepObject":     ParamDeepObject,
+}
+
+var (
+	// objectItemEscaper escapes commas of keys and values of joined objects, and percent signs to keep
+	// escapes unambiguous.
+	objectItemEscaper = strings.NewReplacer("%", "%25", ",", "%2C")
+
+	// objectItemUnescaper reverses objectItemEscaper.
+	objectItemUnescaper = strings.NewReplacer("%25", "%", "%2C", ",", "%2c", ",")
+)
+
+// parseParamStyle returns the style and explode of `style` and `explode` tag options, explode defaults
+// to true for form and deepObject styles as in OpenAPI, a field with only `explode` option has form style.
+// It returns false for unknown styles.
+func parseParamStyle(options tagOptions) (style ParamStyle, explode bool, valid bool) {
+	name, hasStyle := options.get("style")
+	exp, hasExplode := options.get("explode")
+
+	if !hasStyle && !hasExplode {
+		return ParamNative, false, true
+	}
+
+	style = ParamForm
+
+	if hasStyle {
+		var ok bool
+
+		if style, ok = paramStyles[name]; !ok {
+			return ParamNative, false, false
+		}
+	}
+
+	explode = style == ParamForm || style == ParamDeepObject
+
+	if hasExplode {
+		explode = exp != "false"
+	}
+
+	return style, explode, true
+}
+
+// styleField returns the field with options of a parameter style applied to values of typ.
+func styleField(f cachedField, typ reflect.Type, style ParamStyle, explode bool) cachedField {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		f.sliceSeparator = blank
+
+		if !explode {
+			switch style {
+			case ParamForm:
+				f.sliceSeparator = ","
+			case ParamSpaceDelimited:
+				f.sliceSeparator = " "
+			case ParamPipeDelimited:
+				f.sliceSeparator = "|"
+			}
+		}
+
+	case reflect.Struct, reflect.Map:
+		// structs converted from text are single values
+		if typ.Kind() == reflect.Struct && (isValueStruct(typ) || reflect.PointerTo(typ).Implements(textUnmarshalerType)) {
+			return f
+		}
+
+		switch {
+		case style == ParamDeepObject:
+			f.isDeepObject = true
+		case style != ParamForm:
+		case !explode:
+			f.isDeepObject = true
+			f.isJoinedObject = true
+		case typ.Kind() == reflect.Struct && !f.isRecursive:
+			// recursive structs keep their namespace, their flattened fields would be endless
+			f.isFlatten = true
+			f.flattenPrefix = blank
+		}
+	}
+
+	return f
+}
+
+// setJoinedObject decodes an object of a field with `style=form` and `explode=false`,
+// eg. "filter=role,admin,name,Alex", the list is decoded as bracketed keys of its own values.
+// Commas of keys and values are escaped as "%2C" and percent signs as "%25".
+func (d *decoder[DecodeFuncArgument]) setJoinedObject(fv reflect.Value, namespace []byte) bool {
+	arr, ok := d.lookup(namespace)
+	if !ok || len(arr) == 0 || arr[0] == blank {
+		return false
+	}
+
+	parts := strings.Split(arr[0], ",")
+	if len(parts)%2 != 0 {
+		d.setValueError(namespace, fv.Kind(), arr[0], newError(ErrInvalidObject, "odd number of items in object '%s'", arr[0]))
+
+		return false
+	}
+
+	ns := string(namespace)
+	values := make(url.Values, len(parts)/2)
+
+	for i := 0; i < len(parts); i += 2 {
+		k := ns + "[" + objectItemUnescaper.Replace(parts[i]) + "]"
+		values[k] = append(values[k], objectItemUnescaper.Replace(parts[i+1]))
+	}
+
+	// parsed map data is not reused as it refers to keys of values
+	vals, owned, dm, dmDone, maxKeyLen := d.values, d.valuesOwned, d.dm, d.dmDone, d.maxKeyLen
+	d.values, d.valuesOwned, d.dm, d.dmDone = values, true, nil, false
+	set := d.setFieldByType(fv, false, namespace, 0)
+	d.values, d.valuesOwned, d.dm, d.dmDone, d.maxKeyLen = vals, owned, dm, dmDone, maxKeyLen
+
+	return set
+}
+
+// setJoinedObject encodes an object of a field with `style=form` and `explode=false` as a key-value list,
+// eg. "filter=role,admin,name,Alex", commas of keys and values are escaped.
+func (e *encoder) setJoinedObject(fv reflect.Value, namespace []byte, idx int, f cachedField) {
+	emit := e.emit
+	ns := string(namespace)
+
+	var items []string
+
+	e.emit = func(key, value string) {
+		if strings.HasPrefix(key, ns+"[") && strings.HasSuffix(key, "]") {
+			items = append(items, objectItemEscaper.Replace(key[len(ns)+1:len(key)-1]), objectItemEscaper.Replace(value))
+		}
+	}
+
+	e.setFieldByType(fv, namespace, idx, f)
+
+	e.emit = emit
+
+	if len(items) > 0 {
+		e.setVal(namespace, fv, strings.Join(items, ","))
+	}
+}
@@ -0,0 +1,229 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type styleFilter struct {
+	Role string `form:"role"`
+	Name string `form:"firstName"`
+}
+
+func TestParamStyleTags(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Form     []int             `form:"form,style=form,explode=false"`
+		Space    []string          `form:"space,style=spaceDelimited"`
+		Pipe     []string          `form:"pipe,style=pipeDelimited"`
+		Exploded []string          `form:"exploded,style=pipeDelimited,explode=true"`
+		Deep     styleFilter       `form:"deep,style=deepObject"`
+		DeepMap  map[string]string `form:"deepMap,style=deepObject"`
+		Joined   *styleFilter      `form:"joined,style=form,explode=false"`
+		Labels   map[string]string `form:"labels,explode=false"`
+		Flat     styleFilter       `form:"flat,style=form"`
+	}
+
+	values := url.Values{
+		"form":            {"1,2"},
+		"space":           {"a b"},
+		"pipe":            {"a|b"},
+		"exploded":        {"a", "b"},
+		"deep[role]":      {"admin"},
+		"deep[firstName]": {"Alex"},
+		"deepMap[x]":      {"1"},
+		"joined":          {"role,user,firstName,Sam"},
+		"labels":          {"env,dev"},
+		"role":            {"guest"},
+		"firstName":       {"Kim"},
+	}
+
+	expected := Test{
+		Form:     []int{1, 2},
+		Space:    []string{"a", "b"},
+		Pipe:     []string{"a", "b"},
+		Exploded: []string{"a", "b"},
+		Deep:     styleFilter{Role: "admin", Name: "Alex"},
+		DeepMap:  map[string]string{"x": "1"},
+		Joined:   &styleFilter{Role: "user", Name: "Sam"},
+		Labels:   map[string]string{"env": "dev"},
+		Flat:     styleFilter{Role: "guest", Name: "Kim"},
+	}
+
+	var test Test
+
+	err := NewDecoder[any]().Decode(&test, values, nil)
+	NoError(t, err)
+	Equal(t, expected, test)
+
+	encoded, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, values, encoded)
+
+	err = NewDecoder[any]().Decode(&Test{}, url.Values{"joined": {"role,user,firstName"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidObject))
+}
+
+func TestParamStyleJoinedEscaping(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Filter styleFilter       `form:"filter,explode=false"`
+		Labels map[string]string `form:"labels,explode=false"`
+	}
+
+	test := Test{
+		Filter: styleFilter{Role: "admin,x", Name: "100%2C"},
+		Labels: map[string]string{"a,b": "c"},
+	}
+
+	values, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"filter": {"role,admin%2Cx,firstName,100%252C"},
+		"labels": {"a%2Cb,c"},
+	}, values)
+
+	var decoded Test
+
+	NoError(t, NewDecoder[any]().Decode(&decoded, values, nil))
+	Equal(t, test, decoded)
+}
+
+func TestParamStyleInvalid(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		IDs []int `form:"ids,style=matrix"`
+	}
+
+	err := NewDecoder[any]().Decode(&Test{}, url.Values{"ids": {"1"}}, nil)
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrInvalidTagOption))
+	Equal(t, "invalid tag option 'style=matrix' namespace 'ids'", err.(DecodeErrors)["ids"].Error())
+}
+
+func TestSetParamStyle(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		IDs    []int       `form:"ids"`
+		Filter styleFilter `form:"filter"`
+		Tags   []string    `form:"tags,explode=true"`
+	}
+
+	test := Test{IDs: []int{1, 2}, Filter: styleFilter{Role: "admin", Name: "Alex"}, Tags: []string{"a", "b"}}
+
+	tests := []struct {
+		style   ParamStyle
+		explode bool
+		values  url.Values
+	}{
+		{
+			style: ParamNative,
+			values: url.Values{
+				"ids":              {"1", "2"},
+				"filter.role":      {"admin"},
+				"filter.firstName": {"Alex"},
+				"tags":             {"a", "b"},
+			},
+		},
+		{
+			style:   ParamForm,
+			explode: true,
+			values: url.Values{
+				"ids":       {"1", "2"},
+				"role":      {"admin"},
+				"firstName": {"Alex"},
+				"tags":      {"a", "b"},
+			},
+		},
+		{
+			style: ParamForm,
+			values: url.Values{
+				"ids":    {"1,2"},
+				"filter": {"role,admin,firstName,Alex"},
+				"tags":   {"a", "b"},
+			},
+		},
+		{
+			style: ParamPipeDelimited,
+			values: url.Values{
+				"ids":              {"1|2"},
+				"filter.role":      {"admin"},
+				"filter.firstName": {"Alex"},
+				"tags":             {"a", "b"},
+			},
+		},
+		{
+			style:   ParamDeepObject,
+			explode: true,
+			values: url.Values{
+				"ids":               {"1", "2"},
+				"filter[role]":      {"admin"},
+				"filter[firstName]": {"Alex"},
+				"tags":              {"a", "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		encoder := NewEncoder()
+		encoder.SetParamStyle(tt.style, tt.explode)
+
+		values, err := encoder.Clone().Encode(test)
+		NoError(t, err)
+		Equal(t, tt.values, values, tt.style)
+
+		decoder := NewDecoder[any]()
+		decoder.SetParamStyle(tt.style, tt.explode)
+
+		var decoded Test
+
+		err = decoder.Decode(&decoded, values, nil)
+		NoError(t, err)
+		Equal(t, test, decoded, tt.style)
+	}
+}
+
+func TestSetParamStyleRecursive(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name     string `form:"name"`
+		Next     *Node  `form:"next"`
+		Children []Node `form:"children"`
+	}
+
+	type Test struct {
+		Filter styleFilter `form:"filter"`
+		Node   Node        `form:"node"`
+	}
+
+	test := Test{Filter: styleFilter{Role: "admin"}, Node: Node{Name: "a", Next: &Node{Name: "b"}}}
+
+	encoder := NewEncoder()
+	encoder.SetParamStyle(ParamForm, true)
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+	Equal(t, url.Values{"role": {"admin"}, "firstName": {""}, "node.name": {"a"}, "node.next.name": {"b"}}, values)
+
+	decoder := NewDecoder[any]()
+	decoder.SetParamStyle(ParamForm, true)
+
+	var decoded Test
+
+	NoError(t, decoder.Decode(&decoded, values, nil))
+	Equal(t, test, decoded)
+
+	var node Node
+
+	NoError(t, decoder.Decode(&node, url.Values{"name": {"a"}}, nil))
+	Equal(t, Node{Name: "a"}, node)
+}
@@ -0,0 +1,173 @@
+package form
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Plan sets fields of T one at a time by path, with the same conversion and tag option
+// handling as Decode, for frameworks and code generators assigning fields individually.
+//
+//	plan := form.PlanFor[User](decoder)
+//
+//	err := plan.Set(&user, "Address.City", []string{"Berlin"}, nil)
+//
+// Paths of struct fields are resolved once and cached, other paths, eg. with indexes or map keys,
+// are decoded as a single key. A Plan is safe for concurrent use.
+type Plan[T any, DecodeFuncArgument any] struct {
+	d      *Decoder[DecodeFuncArgument]
+	opts   DecodeOptions
+	fields sync.Map // map[string]*planField
+}
+
+// planField is a resolved struct field path.
+type planField struct {
+	// chain holds indexes of struct fields leading to the field, the last one is the field itself.
+	chain []int
+	field cachedField
+	ok    bool
+}
+
+// PlanFor returns a Plan for type T using settings and registered functions of the decoder,
+// settings are captured when the plan is created.
+func PlanFor[T any, DecodeFuncArgument any](d *Decoder[DecodeFuncArgument]) *Plan[T, DecodeFuncArgument] {
+	return &Plan[T, DecodeFuncArgument]{d: d, opts: d.opts}
+}
+
+// Set decodes values into the field of target at path, eg. "Items[0].Name" or "Address.City".
+//
+// Errors are returned as DecodeErrors keyed by path like Decode returns them.
+func (p *Plan[T, DecodeFuncArgument]) Set(target *T, path string, values []string, argument DecodeFuncArgument) error {
+	if target == nil {
+		return &InvalidDecoderError{Type: reflect.TypeOf(target)}
+	}
+
+	pf := p.field(path)
+	if !pf.ok {
+		return p.d.DecodeWithOptions(target, url.Values{path: values}, argument, p.opts)
+	}
+
+	v := reflect.ValueOf(target).Elem()
+	last := len(pf.chain) - 1
+
+	for _, idx := range pf.chain[:last] {
+		v = v.Field(idx)
+
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+	}
+
+	dec := p.d.dataPool.Get().(*decoder[DecodeFuncArgument]) //nolint:errcheck
+	dec.opts = p.opts
+	dec.funcs = p.d.funcs.Load()
+	dec.structCache = p.d.structCache.Load()
+	dec.values = url.Values{path: values}
+	dec.decodeFuncArgument = argument
+	dec.argumentProvider, _ = interface{}(argument).(ArgumentProvider[DecodeFuncArgument])
+	dec.dm = dec.dm[0:0]
+
+	dec.setField(v.Field(pf.chain[last]), pf.field, append(dec.namespace[0:0], path...))
+
+	var err error
+
+	if len(dec.errs) > 0 {
+		err = dec.errs
+	}
+
+	dec.reset()
+	p.d.dataPool.Put(dec)
+
+	return err
+}
+
+// field returns resolved path, paths that are not plain struct field paths are not ok.
+func (p *Plan[T, DecodeFuncArgument]) field(path string) *planField {
+	if pf, ok := p.fields.Load(path); ok {
+		return pf.(*planField) //nolint:forcetypeassert
+	}
+
+	pf := &planField{}
+
+	if typ := reflect.TypeOf((*T)(nil)).Elem(); typ.Kind() == reflect.Struct && !isValueStruct(typ) &&
+		strings.IndexByte(path, '[') == -1 {
+		pf.chain, pf.field, pf.ok = p.resolve(typ, path, true, blank)
+	}
+
+	p.fields.Store(path, pf)
+
+	return pf
+}
+
+// resolve finds struct field at path within typ, names are joined like traverseStruct joins them.
+func (p *Plan[T, DecodeFuncArgument]) resolve(typ reflect.Type, path string, first bool, namePrefix string) ([]int, cachedField, bool) {
+	sc := p.d.structCache.Load()
+
+	s, ok := sc.Get(p.opts.Mode, typ, p.opts.TagName)
+	if !ok {
+		s = sc.parseStruct(p.opts.Mode, typ, p.opts.TagName)
+	}
+
+	for _, f := range s.fields {
+		// pointers to unexported embedded structs cannot be allocated, such paths are decoded
+		if !f.canSet || f.isUnexportedPtr {
+			continue
+		}
+
+		ft := typ.Field(f.idx).Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		nested := ft.Kind() == reflect.Struct && !isValueStruct(ft)
+
+		if f.isFlatten {
+			if chain, cf, ok := p.resolve(ft, path, first, namePrefix+f.flattenPrefix); ok {
+				return append([]int{f.idx}, chain...), cf, true
+			}
+
+			continue
+		}
+
+		// fields of embedded structs are also decoded without the embedded struct name
+		if f.isAnonymous && f.hasExportedScalar && nested && f.embed != embedNamespace {
+			if chain, cf, ok := p.resolve(ft, path, first, blank); ok {
+				return append([]int{f.idx}, chain...), cf, true
+			}
+		}
+
+		if f.isAnonymous && f.embed == embedPromote {
+			continue
+		}
+
+		for i := -1; i < len(f.aliases); i++ {
+			name := f.name
+			if i >= 0 {
+				name = f.aliases[i]
+			}
+
+			name = namePrefix + name
+			if !first {
+				name = p.opts.NamespacePrefix + name + p.opts.NamespaceSuffix
+			}
+
+			if path == name {
+				return []int{f.idx}, f, true
+			}
+
+			if nested && strings.HasPrefix(path, name) {
+				if chain, cf, ok := p.resolve(ft, path[len(name):], false, blank); ok {
+					return append([]int{f.idx}, chain...), cf, true
+				}
+			}
+		}
+	}
+
+	return nil, cachedField{}, false
+}
@@ -0,0 +1,72 @@
+package form
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+		Zip  int    `form:"zip"`
+	}
+
+	type Base struct {
+		ID int `form:"id"`
+	}
+
+	type User struct {
+		Base
+		Name    string   `form:"name,maxlen=5"`
+		Tags    []string `form:"tags" collectionFormat:"csv"`
+		Address *Address `form:"address"`
+		Items   []Address
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterFunc(func(s string, _ any) (interface{}, error) {
+		return strings.ToUpper(s), nil
+	}, reflect.TypeOf(""))
+
+	plan := PlanFor[User](d)
+
+	var u User
+
+	NoError(t, plan.Set(&u, "id", []string{"7"}, nil))
+	NoError(t, plan.Set(&u, "name", []string{"joe"}, nil))
+	NoError(t, plan.Set(&u, "tags", []string{"a,b"}, nil))
+	NoError(t, plan.Set(&u, "address.city", []string{"berlin"}, nil))
+	NoError(t, plan.Set(&u, "Items[1].city", []string{"paris"}, nil))
+	NoError(t, plan.Set(&u, "unknown", []string{"x"}, nil))
+
+	Equal(t, User{
+		Base:    Base{ID: 7},
+		Name:    "JOE",
+		Tags:    []string{"A", "B"},
+		Address: &Address{City: "BERLIN"},
+		Items:   []Address{{}, {City: "PARIS"}},
+	}, u)
+
+	err := plan.Set(&u, "address.zip", []string{"x"}, nil)
+	NotNil(t, err)
+	Equal(t, "Field Namespace:address.zip ERROR:invalid integer value 'x' type 'int' namespace 'address.zip'", err.Error())
+	True(t, errors.Is(err, ErrInvalidInt))
+
+	err = plan.Set(&u, "name", []string{"joseph"}, nil)
+	NotNil(t, err)
+
+	var tooLong *TooLongError
+
+	True(t, errors.As(err, &tooLong))
+	Equal(t, "JOE", u.Name)
+
+	err = plan.Set(nil, "name", []string{"joe"}, nil)
+	NotNil(t, err)
+	Equal(t, "form: Decode(nil *form.User)", err.Error())
+}
@@ -0,0 +1,71 @@
+package form
+
+import (
+	"strings"
+)
+
+// Preprocessor transforms raw values of a key before conversion, eg. trimming spaces.
+// It must not modify raw in place, values are owned by the caller of Decode.
+type Preprocessor func(namespace string, raw []string) []string
+
+// builtinPreprocessors are applied to fields with the tag option of their name, eg. `form:"email,trim,lower"`,
+// preprocessors registered with Decoder.RegisterPreprocessorByTag take precedence.
+var builtinPreprocessors = map[string]Preprocessor{
+	"trim":  mapValues(strings.TrimSpace),
+	"lower": mapValues(strings.ToLower),
+	"upper": mapValues(strings.ToUpper),
+	"nozw":  mapValues(stripZeroWidth),
+}
+
+// mapValues returns a Preprocessor applying fn to every value.
+func mapValues(fn func(s string) string) Preprocessor {
+	return func(_ string, raw []string) []string {
+		vals := make([]string, len(raw))
+
+		for i, s := range raw {
+			vals[i] = fn(s)
+		}
+
+		return vals
+	}
+}
+
+// stripZeroWidth removes zero-width spaces, joiners and byte order marks, eg. pasted with copied text.
+func stripZeroWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+			return -1
+		default:
+			return r
+		}
+	}, s)
+}
+
+// fieldPreprocessors returns preprocessors of the field tag options in order, or nil.
+func (f *decodeFuncs[DecodeFuncArgument]) fieldPreprocessors(options tagOptions) []Preprocessor {
+	var pre []Preprocessor
+
+	for _, o := range options {
+		if fn, ok := f.tagPreprocessors[o.name]; ok {
+			pre = append(pre, fn)
+		} else if fn, ok := builtinPreprocessors[o.name]; ok {
+			pre = append(pre, fn)
+		}
+	}
+
+	return pre
+}
+
+// preprocess applies registered preprocessors and then preprocessors of the decoded field to values of a key.
+func (d *decoder[DecodeFuncArgument]) preprocess(namespace string, vals []string) []string {
+	for _, fn := range d.funcs.preprocessors {
+		vals = fn(namespace, vals)
+	}
+
+	for _, fn := range d.fieldPre {
+		vals = fn(namespace, vals)
+	}
+
+	return vals
+}
@@ -0,0 +1,87 @@
+package form
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestPreprocessors(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		City string `form:"city"`
+		Zip  string `form:"zip,nozw"`
+	}
+
+	type Test struct {
+		Email    string            `form:"email,trim,lower"`
+		Code     string            `form:"code,upper"`
+		Tags     []string          `form:"tags,trim"`
+		Indexed  []string          `form:"indexed,lower"`
+		Labels   map[string]string `form:"labels,trim"`
+		Address  Address           `form:"address,trim"`
+		Age      int               `form:"age,trim"`
+		Name     string            `form:"name"`
+		Nickname string            `form:"nickname,squash"`
+	}
+
+	values := url.Values{
+		"email":        {"  Joe@Example.COM "},
+		"code":         {"ab1"},
+		"tags":         {" a ", "b "},
+		"indexed[0]":   {"X"},
+		"labels[k]":    {" v "},
+		"address.city": {" Berlin "},
+		"address.zip":  {"10\u200b115"},
+		"age":          {" 42 "},
+		"name":         {"  Joe  "},
+		"nickname":     {"j  o   e"},
+	}
+
+	d := NewDecoder[any]()
+	d.RegisterPreprocessorByTag("squash", func(_ string, raw []string) []string {
+		vals := make([]string, len(raw))
+
+		for i, s := range raw {
+			vals[i] = strings.Join(strings.Fields(s), " ")
+		}
+
+		return vals
+	})
+
+	var test Test
+
+	NoError(t, d.Decode(&test, values, nil))
+	Equal(t, Test{
+		Email:    "joe@example.com",
+		Code:     "AB1",
+		Tags:     []string{"a", "b"},
+		Indexed:  []string{"x"},
+		Labels:   map[string]string{"k": "v"},
+		Address:  Address{City: "Berlin", Zip: "10115"},
+		Age:      42,
+		Name:     "  Joe  ",
+		Nickname: "j o e",
+	}, test)
+
+	// values of the caller are not modified
+	Equal(t, "  Joe@Example.COM ", values.Get("email"))
+
+	var seen []string
+
+	d.RegisterPreprocessor(func(ns string, raw []string) []string {
+		seen = append(seen, ns)
+
+		return mapValues(strings.TrimSpace)(ns, raw)
+	})
+
+	test = Test{}
+
+	NoError(t, d.Decode(&test, url.Values{"name": {"  Joe  "}, "email": {" A@B.C "}}, nil))
+	Equal(t, "Joe", test.Name)
+	Equal(t, "a@b.c", test.Email)
+	Contains(t, seen, "name")
+}
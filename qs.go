@@ -0,0 +1,131 @@
+package form
+
+import (
+	"reflect"
+	"strings"
+)
+
+// qsDefaultDepth is the default depth of the qs library.
+const qsDefaultDepth = 5
+
+// qsKeys rewrites keys of the qs library to bracketed keys, dotted keys of objects are bracketed when
+// AllowDots is set, eg. "a.b[c]" to "a[b][c]", and segments nested deeper than Depth are kept as a single key.
+func (d *decoder[DecodeFuncArgument]) qsKeys() {
+	depth := d.opts.QS.Depth
+	if depth <= 0 {
+		depth = qsDefaultDepth
+	}
+
+	keys := make([]string, 0, len(d.values))
+
+	// keys are collected first as replaceValues may write into the ranged values,
+	// rewritten keys must not be rewritten again
+	for k := range d.values {
+		keys = append(keys, k)
+	}
+
+	for _, k := range keys {
+		name := k
+
+		if d.opts.QS.AllowDots {
+			name = bracketDots(k)
+		}
+
+		// segments deeper than Depth are kept as one key like the qs library, eg. "a[b][[c][d]]" of Depth 1
+		if i := nthBracket(name, depth); i != -1 {
+			name = name[:i] + "[" + name[i:] + "]"
+		}
+
+		if name == k {
+			continue
+		}
+
+		existing := d.values[name]
+
+		d.replaceValues(name, append(existing[:len(existing):len(existing)], d.values[k]...))
+		d.setOrigKey(name, k)
+		delete(d.values, k)
+	}
+}
+
+// nthBracket returns the index of the opening bracket of the segment n of a key, counted from 0,
+// -1 when the key has fewer segments.
+func nthBracket(k string, n int) int {
+	for i := 0; i < len(k); i++ {
+		if k[i] != '[' {
+			continue
+		}
+
+		if n == 0 {
+			return i
+		}
+
+		n--
+	}
+
+	return -1
+}
+
+// bracketDots replaces dotted segments of a key with brackets like the qs library, eg. "a.b[c].d" to "a[b][c][d]".
+// Dots within brackets are kept.
+func bracketDots(k string) string {
+	if strings.IndexByte(k, '.') == -1 {
+		return k
+	}
+
+	var (
+		b     strings.Builder
+		inDot bool
+		depth int
+	)
+
+	b.Grow(len(k) + 4)
+
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+
+		switch {
+		case c == '[':
+			if inDot {
+				b.WriteByte(']')
+				inDot = false
+			}
+
+			depth++
+		case c == ']':
+			if depth > 0 {
+				depth--
+			}
+		case c == '.' && depth == 0 && i > 0 && i+1 < len(k) && k[i+1] != '.' && k[i+1] != '[':
+			if inDot {
+				b.WriteByte(']')
+			}
+
+			b.WriteByte('[')
+			inDot = true
+
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	if inDot {
+		b.WriteByte(']')
+	}
+
+	return b.String()
+}
+
+// qsCommaField returns the field with values of scalar slices joined by comma for QSComma.
+func qsCommaField(f cachedField, typ reflect.Type) cachedField {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && f.sliceSeparator == blank && isScalarType(typ.Elem()) {
+		f.sliceSeparator = ","
+	}
+
+	return f
+}
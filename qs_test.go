@@ -0,0 +1,192 @@
+package form
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type qsItem struct {
+	Name string `form:"name"`
+}
+
+type qsUser struct {
+	Name  string   `form:"name"`
+	Tags  []string `form:"tags"`
+	Items []qsItem `form:"items"`
+}
+
+type qsQuery struct {
+	User qsUser `form:"user"`
+}
+
+func TestQSArrayFormats(t *testing.T) {
+	t.Parallel()
+
+	query := qsQuery{User: qsUser{Name: "Alex", Tags: []string{"a", "b"}, Items: []qsItem{{Name: "x"}, {Name: "y"}}}}
+
+	tests := []struct {
+		opts     QSOptions
+		expected string
+	}{
+		{
+			opts:     QSOptions{},
+			expected: "user[name]=Alex&user[tags][0]=a&user[tags][1]=b&user[items][0][name]=x&user[items][1][name]=y",
+		},
+		{
+			opts:     QSOptions{ArrayFormat: QSBrackets},
+			expected: "user[name]=Alex&user[tags][]=a&user[tags][]=b&user[items][][name]=x&user[items][][name]=y",
+		},
+		{
+			opts:     QSOptions{ArrayFormat: QSRepeat},
+			expected: "user[name]=Alex&user[tags]=a&user[tags]=b&user[items][0][name]=x&user[items][1][name]=y",
+		},
+		{
+			opts:     QSOptions{ArrayFormat: QSComma},
+			expected: "user[name]=Alex&user[tags]=a,b&user[items][0][name]=x&user[items][1][name]=y",
+		},
+		{
+			opts:     QSOptions{AllowDots: true},
+			expected: "user.name=Alex&user.tags[0]=a&user.tags[1]=b&user.items[0].name=x&user.items[1].name=y",
+		},
+	}
+
+	for _, tt := range tests {
+		enc := NewEncoder()
+		enc.SetQSOptions(tt.opts)
+
+		var b strings.Builder
+
+		err := enc.EncodeTo(&b, query)
+		NoError(t, err)
+
+		s, err := url.QueryUnescape(b.String())
+		NoError(t, err)
+		Equal(t, tt.expected, s)
+
+		values, err := url.ParseQuery(b.String())
+		NoError(t, err)
+
+		dec := NewDecoder[any]()
+		dec.SetQSOptions(tt.opts)
+
+		var decoded qsQuery
+
+		err = dec.Decode(&decoded, values, nil)
+		NoError(t, err)
+		Equal(t, query, decoded)
+	}
+}
+
+func TestQSKeyStyle(t *testing.T) {
+	t.Parallel()
+
+	enc := NewEncoder()
+	enc.SetKeyStyle(StyleQS)
+
+	values, err := enc.Encode(qsQuery{User: qsUser{Tags: []string{"a"}}})
+	NoError(t, err)
+	Equal(t, url.Values{"user[name]": {""}, "user[tags][0]": {"a"}}, values)
+
+	dec := NewDecoder[any]()
+	dec.SetKeyStyle(StyleQS)
+
+	// qs accepts all array formats without the comma option
+	var decoded qsQuery
+
+	err = dec.Decode(&decoded, url.Values{
+		"user[tags][]":         {"a", "b"},
+		"user[items][][name]":  {"x"},
+		"user[items][1][name]": {"y"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, qsQuery{User: qsUser{Tags: []string{"a", "b"}, Items: []qsItem{{Name: "x"}, {Name: "y"}}}}, decoded)
+}
+
+func TestQSDepth(t *testing.T) {
+	t.Parallel()
+
+	type Level struct {
+		Name string            `form:"name"`
+		Next map[string]string `form:"next"`
+	}
+
+	type Test struct {
+		A map[string]Level `form:"a"`
+	}
+
+	values := url.Values{
+		"a[b][name]":       {"1"},
+		"a[b][next][c]":    {"2"},
+		"a.d.next.e":       {"3"},
+		"a[f][next][g][h]": {"4"},
+	}
+
+	dec := NewDecoder[any]()
+	dec.SetQSOptions(QSOptions{AllowDots: true, Depth: 3})
+
+	var test Test
+
+	err := dec.Decode(&test, values, nil)
+	NoError(t, err)
+	Equal(t, Test{A: map[string]Level{
+		"b": {Name: "1", Next: map[string]string{"c": "2"}},
+		"d": {Next: map[string]string{"e": "3"}},
+	}}, test)
+}
+
+func TestQSDepthRemainder(t *testing.T) {
+	t.Parallel()
+
+	dec := NewDecoder[any]()
+	dec.SetQSOptions(QSOptions{Depth: 2})
+
+	var test struct {
+		A map[string]map[string]map[string]string `form:"a"`
+	}
+
+	err := dec.Decode(&test, url.Values{"a[b][c][d]": {"1"}, "a[b][c][e][f]": {"2"}}, nil)
+	NoError(t, err)
+	Equal(t, map[string]map[string]map[string]string{"b": {"c": {"[d]": "1", "[e][f]": "2"}}}, test.A)
+
+	var dynamic map[string]interface{}
+
+	err = dec.Decode(&dynamic, url.Values{"a[b][c][d]": {"1"}}, nil)
+	NoError(t, err)
+	Equal(t, map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{"[d]": "1"}}}}, dynamic)
+
+	// rewritten keys are not rewritten again when values are already copied, eg. to drop ignored keys
+	dec.SetIgnoredKeys("x")
+
+	for i := 0; i < 10; i++ {
+		test.A = nil
+
+		err = dec.Decode(&test, url.Values{"a[b][c][d]": {"1"}, "a[b][c][e][f]": {"2"}, "x": {"3"}}, nil)
+		NoError(t, err)
+		Equal(t, map[string]map[string]map[string]string{"b": {"c": {"[d]": "1", "[e][f]": "2"}}}, test.A)
+	}
+}
+
+func TestBracketDots(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "a", expected: "a"},
+		{key: "a.b", expected: "a[b]"},
+		{key: "a.b.c", expected: "a[b][c]"},
+		{key: "a.b[0].c", expected: "a[b][0][c]"},
+		{key: "a[b.c]", expected: "a[b.c]"},
+		{key: "a..b", expected: "a.[b]"},
+		{key: ".a", expected: ".a"},
+		{key: "a.", expected: "a."},
+	}
+
+	for _, tt := range tests {
+		Equal(t, tt.expected, bracketDots(tt.key), tt.key)
+	}
+}
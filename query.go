@@ -0,0 +1,90 @@
+package form
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryOption configures Encoder.EncodeToString.
+type QueryOption func(o *queryOptions)
+
+type queryOptions struct {
+	spacePercent bool
+	sortKeys     bool
+	rawBrackets  bool
+}
+
+// WithSpaceAsPercent escapes spaces as "%20" instead of "+".
+func WithSpaceAsPercent() QueryOption {
+	return func(o *queryOptions) {
+		o.spacePercent = true
+	}
+}
+
+// WithSortedKeys sorts keys like url.Values.Encode, by default keys are in order of struct fields
+// and map entries are sorted by key, see Encoder.EncodePairs.
+func WithSortedKeys() QueryOption {
+	return func(o *queryOptions) {
+		o.sortKeys = true
+	}
+}
+
+// WithRawBrackets keeps brackets of keys unescaped, eg. "items[0]=a" instead of "items%5B0%5D=a".
+func WithRawBrackets() QueryOption {
+	return func(o *queryOptions) {
+		o.rawBrackets = true
+	}
+}
+
+// escape escapes a key or value of query string.
+func (o *queryOptions) escape(s string, isKey bool) string {
+	s = url.QueryEscape(s)
+
+	if o.spacePercent {
+		s = strings.ReplaceAll(s, "+", "%20")
+	}
+
+	if isKey && o.rawBrackets {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, "%5B", "["), "%5D", "]")
+	}
+
+	return s
+}
+
+// EncodeToString encodes the given value into a query string.
+//
+// Keys are in the order of EncodePairs unless WithSortedKeys is used, which sorts them like url.Values.Encode,
+// values of a key keep their order. As with Encode, values that encoded successfully are returned along with the errors.
+func (e *Encoder) EncodeToString(v interface{}, opts ...QueryOption) (string, error) {
+	o := queryOptions{}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	pairs, err := e.EncodePairs(v)
+	if _, invalid := err.(*InvalidEncodeError); invalid {
+		return blank, err
+	}
+
+	if o.sortKeys {
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i].Key < pairs[j].Key
+		})
+	}
+
+	var b strings.Builder
+
+	for i, p := range pairs {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+
+		b.WriteString(o.escape(p.Key, true))
+		b.WriteByte('=')
+		b.WriteString(o.escape(p.Value, false))
+	}
+
+	return b.String(), err
+}
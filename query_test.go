@@ -0,0 +1,44 @@
+package form
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestEncodeToString(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name  string
+		Tags  []string
+		Items []*string
+		Plus  string
+		Attrs map[string]string
+	}
+
+	item := "x y"
+	test := Test{Name: "joe bloggs", Tags: []string{"b", "a"}, Items: []*string{&item}, Plus: "1+1",
+		Attrs: map[string]string{"z": "1", "b": "2", "m": "3"}}
+
+	encoder := NewEncoder()
+
+	s, err := encoder.EncodeToString(test)
+	NoError(t, err)
+	Equal(t, "Name=joe+bloggs&Tags=b&Tags=a&Items%5B0%5D=x+y&Plus=1%2B1&Attrs%5Bb%5D=2&Attrs%5Bm%5D=3&Attrs%5Bz%5D=1", s)
+
+	s, err = encoder.EncodeToString(test, WithSpaceAsPercent(), WithSortedKeys(), WithRawBrackets())
+	NoError(t, err)
+	Equal(t, "Attrs[b]=2&Attrs[m]=3&Attrs[z]=1&Items[0]=x%20y&Name=joe%20bloggs&Plus=1%2B1&Tags=b&Tags=a", s)
+
+	values, err := encoder.Encode(test)
+	NoError(t, err)
+
+	s, err = encoder.EncodeToString(test, WithSortedKeys())
+	NoError(t, err)
+	Equal(t, values.Encode(), s)
+
+	s, err = encoder.EncodeToString(nil)
+	NotNil(t, err)
+	Equal(t, "", s)
+}
@@ -0,0 +1,67 @@
+package form
+
+import (
+	"errors"
+)
+
+// FieldState is the value and error of a field re-displayed in a form after a failed submit, see Repopulate.
+type FieldState struct {
+	// Value is the first value of the field.
+	Value string
+
+	// Values are all values of the field, eg. of a slice.
+	Values []string
+
+	// Err is the decoding error of the field, nil without error.
+	Err error
+
+	// Message is the message of Err, empty without error.
+	Message string
+}
+
+// Repopulate returns states of fields keyed by namespace with a default Encoder, see Encoder.Repopulate.
+func Repopulate(v interface{}, errs DecodeErrors) map[string]FieldState {
+	defaultEncoderOnce.Do(func() {
+		defaultEncoder = NewEncoder()
+	})
+
+	return defaultEncoder.Repopulate(v, errs)
+}
+
+// Repopulate returns values of v encoded by the encoder combined with errors of decoding into v keyed
+// by the same namespaces, so that a form can show user input along with messages after a failed submit.
+// Values that failed to decode are not in v, the raw input of their errors is used instead.
+//
+// The encoder should use the key settings of the decoder. Fields the encoder fails on are left out.
+func (e *Encoder) Repopulate(v interface{}, errs DecodeErrors) map[string]FieldState {
+	values, _ := e.Encode(v)
+
+	states := make(map[string]FieldState, len(values)+len(errs))
+
+	for k, vals := range values {
+		state := FieldState{Values: vals}
+
+		if len(vals) > 0 {
+			state.Value = vals[0]
+		}
+
+		states[k] = state
+	}
+
+	for ns, err := range errs {
+		state := states[ns]
+		state.Err = err
+		state.Message = err.Error()
+
+		var fe *FieldError
+
+		if errors.As(err, &fe) && fe.raw != blank {
+			state.Value = fe.raw
+			state.Values = []string{fe.raw}
+		}
+
+		states[ns] = state
+	}
+
+	return states
+}
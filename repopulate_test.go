@@ -0,0 +1,57 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRepopulate(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name  string   `form:"name,min=3"`
+		Age   int      `form:"age"`
+		Email string   `form:"email,required"`
+		Tags  []string `form:"tags"`
+	}
+
+	var test Test
+
+	decoder := NewDecoder[any]()
+	decoder.SetEnforceEnumRequired(true)
+
+	err := decoder.Decode(&test, url.Values{
+		"name": {"jo"},
+		"age":  {"abc"},
+		"tags": {"a", "b"},
+	}, nil)
+	NotNil(t, err)
+
+	states := Repopulate(test, err.(DecodeErrors))
+
+	Equal(t, "jo", states["name"].Value)
+	Equal(t, "length must be at least 3", states["name"].Message)
+
+	// values that failed to decode are shown as sent
+	Equal(t, "abc", states["age"].Value)
+	Equal(t, []string{"abc"}, states["age"].Values)
+	True(t, errors.Is(states["age"].Err, ErrInvalidInt))
+
+	Equal(t, "", states["email"].Value)
+	Equal(t, "value is required", states["email"].Message)
+
+	Equal(t, []string{"a", "b"}, states["tags"].Values)
+	Nil(t, states["tags"].Err)
+	Empty(t, states["tags"].Message)
+
+	encoder := NewEncoder()
+	encoder.SetKeyStyle(StyleRails)
+
+	states = encoder.Repopulate(struct {
+		Test Test `form:"test"`
+	}{Test: Test{Name: "joe"}}, nil)
+	Equal(t, "joe", states["test[name]"].Value)
+}
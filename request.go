@@ -0,0 +1,294 @@
+package form
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// sources of request values selected with `src` tag option
+const (
+	srcQuery  = "query"
+	srcBody   = "body"
+	srcHeader = "header"
+	srcCookie = "cookie"
+	srcPath   = "path"
+)
+
+// ErrUnsupportedMediaType is returned by BindRequest for bodies of media types without a registered decoder.
+var ErrUnsupportedMediaType = errors.New("form: unsupported media type")
+
+// BodyDecodeFunc decodes a request body into v, see Decoder.RegisterBodyDecoder.
+type BodyDecodeFunc func(body io.Reader, v interface{}) error
+
+// decodeJSON is the default decoder of "application/json" bodies.
+func decodeJSON(body io.Reader, v interface{}) error {
+	return json.NewDecoder(body).Decode(v)
+}
+
+// PathParamSource provides route parameters of a request to fields with `src=path` tag option,
+// eg. parameters of chi or gorilla/mux routers, see WithPathParams.
+type PathParamSource interface {
+	PathParam(name string) (string, bool)
+}
+
+// PathParamFunc is an adapter to use a function as PathParamSource, eg. chi.URLParam.
+type PathParamFunc func(name string) (string, bool)
+
+// PathParam returns f(name).
+func (f PathParamFunc) PathParam(name string) (string, bool) {
+	return f(name)
+}
+
+type pathParamsKey struct{}
+
+// WithPathParams returns a shallow copy of r with path parameters used by DecodeRequest.
+// Without it parameters of http.ServeMux patterns are used, see http.Request.PathValue.
+func WithPathParams(r *http.Request, params PathParamSource) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, params))
+}
+
+// pathValuer is implemented by http.Request since Go 1.22.
+type pathValuer interface {
+	PathValue(name string) string
+}
+
+// pathParams returns path parameters of a request set with WithPathParams or of http.ServeMux patterns.
+func pathParams(r *http.Request) PathParamSource {
+	if params, ok := r.Context().Value(pathParamsKey{}).(PathParamSource); ok {
+		return params
+	}
+
+	if pv, ok := interface{}(r).(pathValuer); ok {
+		return PathParamFunc(func(name string) (string, bool) {
+			v := pv.PathValue(name)
+
+			return v, v != blank
+		})
+	}
+
+	return nil
+}
+
+// DecodeRequest parses the form of an HTTP request and decodes it into a new value of T.
+//
+// Body values are decoded for POST, PUT and PATCH requests, multipart bodies are parsed with
+// the limit set by Decoder.SetMaxMemory. The URL query is decoded for requests with other methods,
+// POST requests can be decoded as others with Decoder.SetMethodOverride.
+// Fields with `src` tag option are decoded from the given source regardless of the method,
+// eg. `form:"id,src=query"` or `form:"name,src=body"`, so a key sent in both is never merged.
+// Headers and cookies are decoded with `src=header` and `src=cookie`, header fields are matched
+// and decoded as by DecodeHeader, eg. `form:"X-Request-ID,src=header"`. Route parameters are decoded
+// with `src=path`, eg. `form:"id,src=path"`, see WithPathParams.
+// As with Decode, the value is returned along with decoding errors, it is nil only when
+// the request can not be parsed.
+func DecodeRequest[T any, DecodeFuncArgument any](d *Decoder[DecodeFuncArgument], r *http.Request, argument DecodeFuncArgument) (*T, error) {
+	query, body, err := d.requestValues(r)
+	if err != nil {
+		return nil, err
+	}
+
+	values := query
+	if hasBody(d.requestMethod(r, query, body)) {
+		values = body
+	}
+
+	v := new(T)
+
+	return v, d.decode(v, values, argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.sources = map[string]url.Values{
+			srcQuery:  query,
+			srcBody:   body,
+			srcHeader: headerValues(r.Header),
+			srcCookie: cookieValues(r),
+		}
+		dec.pathParams = pathParams(r)
+	})
+}
+
+// cookieValues returns values of request cookies by name, values of cookies with the same name keep their order.
+func cookieValues(r *http.Request) url.Values {
+	cookies := r.Cookies()
+	values := make(url.Values, len(cookies))
+
+	for _, c := range cookies {
+		values[c.Name] = append(values[c.Name], c.Value)
+	}
+
+	return values
+}
+
+// requestValues parses the request form and returns query and body values,
+// body is parsed only for methods that send form values in the body.
+func (d *Decoder[DecodeFuncArgument]) requestValues(r *http.Request) (query, body url.Values, err error) {
+	query = r.URL.Query()
+
+	if !hasBody(r.Method) {
+		return query, nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		maxMemory := d.opts.MaxMemory
+		if maxMemory <= 0 {
+			maxMemory = defaultMaxMemory
+		}
+
+		if err = r.ParseMultipartForm(maxMemory); err != nil {
+			return nil, nil, err
+		}
+
+		return query, r.PostForm, nil
+	}
+
+	if err = r.ParseForm(); err != nil {
+		return nil, nil, err
+	}
+
+	return query, r.PostForm, nil
+}
+
+// requestMethod returns the method of a request, POST requests are overridden by the value
+// of the key set with Decoder.SetMethodOverride in body or query values.
+func (d *Decoder[DecodeFuncArgument]) requestMethod(r *http.Request, query, body url.Values) string {
+	key := d.opts.MethodOverride
+	if key == blank || r.Method != http.MethodPost {
+		return r.Method
+	}
+
+	method := body.Get(key)
+	if method == blank {
+		method = query.Get(key)
+	}
+
+	switch method = strings.ToUpper(method); method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return method
+	}
+
+	return r.Method
+}
+
+// hasBody reports whether form values of requests with the method are sent in the body.
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+
+	return false
+}
+
+// BindRequest decodes an HTTP request into a new value of T selecting the decoder by Content-Type.
+//
+// URL-encoded and multipart bodies, and requests without a body, are decoded as by DecodeRequest.
+// Other bodies are decoded with functions registered by Decoder.RegisterBodyDecoder, fields with `src`
+// tag option other than body are then decoded from their sources. Errors of body decoders are returned
+// as DecodeErrors like decoding errors, keyed by the field of a json.UnmarshalTypeError if any.
+func BindRequest[T any, DecodeFuncArgument any](d *Decoder[DecodeFuncArgument], r *http.Request, argument DecodeFuncArgument) (*T, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch {
+	case !hasBody(d.requestMethod(r, r.URL.Query(), nil)), mediaType == blank, mediaType == "application/x-www-form-urlencoded",
+		mediaType == "multipart/form-data":
+		return DecodeRequest[T](d, r, argument)
+	}
+
+	fn := d.bodyDecoder(mediaType)
+	if fn == nil {
+		return nil, fmt.Errorf("%w '%s'", ErrUnsupportedMediaType, mediaType)
+	}
+
+	v := new(T)
+	errs := DecodeErrors{}
+
+	if err := fn(r.Body, v); err != nil {
+		d.addBodyError(errs, err)
+	}
+
+	// only fields of other sources are decoded, fields decoded from body are kept
+	err := d.decodeValues(v, nil, argument, func(dec *decoder[DecodeFuncArgument]) {
+		dec.sourcesOnly = true
+		dec.sources = map[string]url.Values{
+			srcQuery:  r.URL.Query(),
+			srcHeader: headerValues(r.Header),
+			srcCookie: cookieValues(r),
+		}
+		dec.pathParams = pathParams(r)
+	})
+
+	var (
+		de DecodeErrors
+		te *TimeoutError
+	)
+
+	switch {
+	case err == nil:
+	case errors.As(err, &te):
+		// errors of the body are kept along with errors of fields decoded before the deadline
+		for k, e := range te.Errs {
+			errs[k] = e
+		}
+
+		if len(errs) == 0 {
+			errs = nil
+		}
+
+		return v, &TimeoutError{Errs: errs}
+	case errors.As(err, &de):
+		for k, e := range de {
+			errs[k] = e
+		}
+	default:
+		return v, err
+	}
+
+	if len(errs) > 0 {
+		return v, errs
+	}
+
+	return v, d.validate(v)
+}
+
+// bodyDecoder returns the decoder registered for the media type.
+func (d *Decoder[DecodeFuncArgument]) bodyDecoder(mediaType string) BodyDecodeFunc {
+	if fn, ok := d.funcs.Load().bodyDecoders[mediaType]; ok {
+		return fn
+	}
+
+	if mediaType == "application/json" {
+		return decodeJSON
+	}
+
+	return nil
+}
+
+// addBodyError adds an error of a body decoder to errs, DecodeErrors are merged.
+func (d *Decoder[DecodeFuncArgument]) addBodyError(errs DecodeErrors, err error) {
+	var de DecodeErrors
+
+	if errors.As(err, &de) {
+		for k, e := range de {
+			errs[k] = e
+		}
+
+		return
+	}
+
+	fe := &FieldError{err: err, format: d.funcs.Load().formatError}
+
+	var te *json.UnmarshalTypeError
+
+	if errors.As(err, &te) {
+		fe.namespace = te.Field
+		fe.kind = te.Type.Kind()
+	}
+
+	errs[fe.namespace] = fe
+}
@@ -0,0 +1,323 @@
+package form
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	d := NewDecoder[any]()
+
+	r := httptest.NewRequest(http.MethodGet, "/?id=1&name=joe", nil)
+
+	test, err := DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 1, Name: "joe"}, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/?id=1", strings.NewReader("name=bob&id=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 2, Name: "bob"}, test)
+
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+	NoError(t, w.WriteField("name", "ann"))
+	NoError(t, w.Close())
+
+	r = httptest.NewRequest(http.MethodPut, "/?id=1", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{Name: "ann"}, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("id=x"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NotNil(t, err)
+	NotNil(t, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("--x"))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NotNil(t, err)
+	Nil(t, test)
+}
+
+func TestDecodeRequestSources(t *testing.T) {
+	t.Parallel()
+
+	type Filter struct {
+		Tags []string `form:"tags"`
+	}
+
+	type Test struct {
+		ID     int    `form:"id,src=query"`
+		Name   string `form:"name,src=body"`
+		Note   string `form:"note"`
+		Filter Filter `form:"filter,src=query"`
+		Other  string `form:"other,src=cookie"`
+	}
+
+	d := NewDecoder[any]()
+
+	r := httptest.NewRequest(http.MethodPost, "/?id=1&name=query&note=query&filter.tags[0]=a&other=x",
+		strings.NewReader("id=2&name=body&note=body&filter.tags[0]=b"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err := DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 1, Name: "body", Note: "body", Filter: Filter{Tags: []string{"a"}}}, test)
+
+	r = httptest.NewRequest(http.MethodGet, "/?id=1&name=query&note=query", nil)
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 1, Note: "query"}, test)
+
+	var direct Test
+	NoError(t, d.Decode(&direct, url.Values{"id": {"3"}, "name": {"n"}}, nil))
+	Equal(t, Test{ID: 3, Name: "n"}, direct)
+}
+
+func TestDecodeRequestHeaderCookie(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		RequestID string    `form:"X-Request-ID,src=header"`
+		Accept    []string  `form:"Accept-Encoding,src=header"`
+		Date      time.Time `form:"Date,src=header"`
+		Session   string    `form:"session,src=cookie"`
+		Name      string    `form:"name"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?name=joe&session=query", nil)
+	r.Header.Set("x-request-id", "abc")
+	r.Header.Set("Accept-Encoding", "gzip, br")
+	r.Header.Set("Date", "Sun, 06 Nov 1994 08:49:37 GMT")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+
+	test, err := DecodeRequest[Test](NewDecoder[any](), r, nil)
+	NoError(t, err)
+	Equal(t, "abc", test.RequestID)
+	Equal(t, []string{"gzip", "br"}, test.Accept)
+	Equal(t, time.Date(1994, 11, 6, 8, 49, 37, 0, time.UTC), test.Date)
+	Equal(t, "s1", test.Session)
+	Equal(t, "joe", test.Name)
+}
+
+func TestDecodeRequestPathParams(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		ID   int    `form:"id,src=path"`
+		Slug string `form:"slug,src=path"`
+		Name string `form:"name"`
+	}
+
+	d := NewDecoder[any]()
+
+	r := httptest.NewRequest(http.MethodGet, "/?id=1", nil)
+	r = WithPathParams(r, PathParamFunc(func(name string) (string, bool) {
+		if name == "slug" {
+			return "hello", true
+		}
+
+		return "", false
+	}))
+
+	test, err := DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{Slug: "hello"}, test)
+
+	// http.Request.SetPathValue is available since Go 1.22
+	r = httptest.NewRequest(http.MethodGet, "/users/7?name=joe&slug=query", nil)
+	if pv, ok := interface{}(r).(interface{ SetPathValue(name, value string) }); ok {
+		pv.SetPathValue("id", "7")
+
+		test, err = DecodeRequest[Test](d, r, nil)
+		NoError(t, err)
+		Equal(t, &Test{ID: 7, Name: "joe"}, test)
+	}
+}
+
+func TestDecodeRequestMethodOverride(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	d := NewDecoder[any]()
+	d.SetMethodOverride("_method")
+
+	// DELETE decodes the URL query
+	r := httptest.NewRequest(http.MethodPost, "/?id=1", strings.NewReader("_method=delete&id=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err := DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 1}, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/?id=1&_method=PUT", strings.NewReader("id=2&name=joe"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 2, Name: "joe"}, test)
+
+	// other methods are not overridden
+	r = httptest.NewRequest(http.MethodPost, "/?id=1", strings.NewReader("_method=GET&id=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err = DecodeRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 2}, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/?id=1&_method=DELETE", strings.NewReader(`{"id":2}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	test, err = BindRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 1}, test)
+
+	// overrides are not honored by default
+	r = httptest.NewRequest(http.MethodPost, "/?id=1", strings.NewReader("_method=DELETE&id=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err = DecodeRequest[Test](NewDecoder[any](), r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 2}, test)
+}
+
+func TestBindRequest(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		ID    int    `form:"id,src=query" json:"-"`
+		Name  string `form:"name" json:"name"`
+		Age   int    `form:"age" json:"age"`
+		Trace string `form:"X-Trace,src=header" json:"-"`
+	}
+
+	d := NewDecoder[any]()
+
+	r := httptest.NewRequest(http.MethodPost, "/?id=1&name=query", strings.NewReader(`{"name":"joe","age":3}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("X-Trace", "t1")
+
+	test, err := BindRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{ID: 1, Name: "joe", Age: 3, Trace: "t1"}, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/?id=x", strings.NewReader(`{"age":"old"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	test, err = BindRequest[Test](d, r, nil)
+	NotNil(t, err)
+
+	var errs DecodeErrors
+
+	True(t, errors.As(err, &errs))
+	Len(t, errs, 2)
+	NotNil(t, errs["age"])
+	True(t, errors.Is(errs["id"], ErrInvalidInt))
+	NotNil(t, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=bob"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	test, err = BindRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{Name: "bob"}, test)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("<name>bob</name>"))
+	r.Header.Set("Content-Type", "application/xml")
+
+	test, err = BindRequest[Test](d, r, nil)
+	True(t, errors.Is(err, ErrUnsupportedMediaType))
+	Nil(t, test)
+
+	d.RegisterBodyDecoder("application/xml", func(body io.Reader, v interface{}) error {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+
+		v.(*Test).Name = strings.TrimSuffix(strings.TrimPrefix(string(b), "<name>"), "</name>")
+
+		return nil
+	})
+
+	test, err = BindRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{Name: "bob"}, test)
+}
+
+func TestBindRequestKeepsBodyFields(t *testing.T) {
+	t.Parallel()
+
+	type Meta struct {
+		Note string `form:"note,required" json:"note"`
+	}
+
+	type Test struct {
+		Name  string `form:"name,required" json:"name"`
+		Agree bool   `form:"agree,checkbox" json:"agree"`
+		ID    int    `form:"id,src=query" json:"-"`
+		Meta  Meta   `form:"meta" json:"meta"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/?id=7&name=query&agree=false",
+		strings.NewReader(`{"name":"bob","agree":true,"meta":{"note":"n"}}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	d := NewDecoder[any]()
+	d.SetEnforceEnumRequired(true)
+
+	test, err := BindRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{Name: "bob", Agree: true, ID: 7, Meta: Meta{Note: "n"}}, test)
+}
+
+func TestBindRequestMergeReplace(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name string `form:"name" json:"name"`
+		ID   int    `form:"id,src=query" json:"-"`
+	}
+
+	d := NewDecoder[any]()
+	d.SetMergeMode(MergeReplace)
+
+	r := httptest.NewRequest(http.MethodPost, "/?id=7", strings.NewReader(`{"name":"bob"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	test, err := BindRequest[Test](d, r, nil)
+	NoError(t, err)
+	Equal(t, &Test{Name: "bob", ID: 7}, test)
+}
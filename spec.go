@@ -0,0 +1,177 @@
+package form
+
+import (
+	"reflect"
+)
+
+// FieldSpec describes a field as the decoder sees it, see Decoder.StructSpec.
+type FieldSpec struct {
+	// Name is the name of the field in keys, eg. "city".
+	Name string
+
+	// Namespace is the key of the field, elements of slices, arrays and maps are written as "[]",
+	// eg. "items[].name".
+	Namespace string
+
+	// Field is the Go name of the struct field, eg. "City".
+	Field string
+
+	// Type is the Go type of the field.
+	Type reflect.Type
+
+	// Kind is the kind of the field type with pointers dereferenced.
+	Kind reflect.Kind
+
+	// Aliases are alternative names the decoder accepts, see `alt` and `formerly` tag options.
+	Aliases []string
+
+	// Options are options of the field tag in order, eg. `omitempty` and `min=3`.
+	Options []TagOption
+
+	// Default is the value of `default` tag applied when decoding and by ApplyDefaults, empty without it.
+	Default string
+
+	// Nested reports whether the field is a struct, or has struct elements, whose fields follow the field.
+	Nested bool
+}
+
+// TagOption is an option of a field tag, eg. `min=3` has name "min" and value "3".
+type TagOption struct {
+	Name  string
+	Value string
+}
+
+// StructSpec returns fields of a struct type as the decoder sees them, with the decoder settings, eg.
+// to generate HTML form fields or OpenAPI parameters from the same source as decoding. Fields of nested
+// structs follow the field of the struct, fields of promoted embedded structs are fields of the embedding
+// struct. Namespaces start with the namespace of the struct type, see Namespace. Recursive types are described
+// down to the first recursion.
+func (d *Decoder[DecodeFuncArgument]) StructSpec(typ reflect.Type) ([]FieldSpec, error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct || isValueStruct(typ) {
+		return nil, newError(ErrUnsupportedType, "unsupported type '%v', struct expected", typ)
+	}
+
+	sp := specBuilder[DecodeFuncArgument]{d: d, structCache: d.structCache.Load(), funcs: d.funcs.Load()}
+	sp.structFields(typ, []byte(d.rootNamespace(typ)), blank)
+
+	return sp.fields, nil
+}
+
+// specBuilder collects field specs of a struct type.
+type specBuilder[DecodeFuncArgument any] struct {
+	d           *Decoder[DecodeFuncArgument]
+	structCache *structCacheMap
+	funcs       *decodeFuncs[DecodeFuncArgument]
+	fields      []FieldSpec
+	path        []reflect.Type
+}
+
+// structFields adds fields of struct typ with keys under namespace, namePrefix is prepended to names
+// of flattened fields.
+func (sp *specBuilder[DecodeFuncArgument]) structFields(typ reflect.Type, namespace []byte, namePrefix string) {
+	for _, t := range sp.path {
+		if t == typ {
+			return
+		}
+	}
+
+	sp.path = append(sp.path, typ)
+	defer func() {
+		sp.path = sp.path[:len(sp.path)-1]
+	}()
+
+	opts := &sp.d.opts
+
+	s, ok := sp.structCache.Get(opts.Mode, typ, opts.TagName)
+	if !ok {
+		s = sp.structCache.parseStruct(opts.Mode, typ, opts.TagName)
+	}
+
+	first := len(namespace) == 0
+
+	for _, f := range s.fields {
+		ft := typ.Field(f.idx).Type
+		et := ft
+
+		for et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+
+		switch {
+		case f.isFlatten:
+			sp.structFields(et, namespace, namePrefix+f.flattenPrefix)
+
+			continue
+		case f.isAnonymous && f.embed != embedNamespace:
+			if f.hasExportedScalar && et.Kind() == reflect.Struct {
+				sp.structFields(et, namespace, blank)
+			}
+
+			continue
+		}
+
+		name := namePrefix + f.name
+		ns := append([]byte(nil), namespace...)
+
+		if first {
+			ns = append(ns, name...)
+		} else {
+			ns = append(ns, opts.NamespacePrefix...)
+			ns = append(ns, name...)
+			ns = append(ns, opts.NamespaceSuffix...)
+		}
+
+		spec := FieldSpec{
+			Name:      name,
+			Namespace: string(ns),
+			Field:     typ.Field(f.idx).Name,
+			Type:      ft,
+			Kind:      et.Kind(),
+			Aliases:   f.aliases,
+			Default:   typ.Field(f.idx).Tag.Get(defaultTag),
+		}
+
+		for _, o := range f.options {
+			spec.Options = append(spec.Options, TagOption{Name: o.name, Value: o.value})
+		}
+
+		sp.fields = append(sp.fields, spec)
+
+		if i := len(sp.fields) - 1; sp.nestedFields(et, ns) {
+			sp.fields[i].Nested = true
+		}
+	}
+}
+
+// nestedFields adds fields of structs decoded from keys under namespace, including structs of elements,
+// and reports whether typ is such a struct.
+func (sp *specBuilder[DecodeFuncArgument]) nestedFields(typ reflect.Type, namespace []byte) bool {
+	for {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array && typ.Kind() != reflect.Map {
+			break
+		}
+
+		namespace = append(namespace[:len(namespace):len(namespace)], "[]"...)
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct || isValueStruct(typ) || reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	if _, ok := sp.funcs.customTypeFuncs[typ]; ok {
+		return false
+	}
+
+	sp.structFields(typ, namespace, blank)
+
+	return true
+}
@@ -0,0 +1,83 @@
+package form
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type specAddress struct {
+	City string `form:"city,min=2"`
+}
+
+type specBase struct {
+	ID int `form:"id"`
+}
+
+type specNode struct {
+	Name string    `form:"name"`
+	Next *specNode `form:"next"`
+}
+
+type specUser struct {
+	specBase
+	Name     string            `form:"name,omitempty,alt=fullName"`
+	Created  time.Time         `form:"created"`
+	Address  *specAddress      `form:"address"`
+	Items    []specAddress     `form:"items"`
+	Labels   map[string]string `form:"labels"`
+	Billing  specAddress       `form:",prefix=billing_"`
+	Node     specNode          `form:"node"`
+	Ignored  string            `form:"-"`
+	internal string
+}
+
+func TestDecoder_StructSpec(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder[any]()
+
+	spec, err := decoder.StructSpec(reflect.TypeOf(&specUser{}))
+	NoError(t, err)
+
+	namespaces := make([]string, 0, len(spec))
+
+	for _, f := range spec {
+		namespaces = append(namespaces, f.Namespace)
+	}
+
+	Equal(t, []string{
+		"id", "name", "created", "address", "address.city", "items", "items[].city",
+		"labels", "billing_city", "node", "node.name", "node.next",
+	}, namespaces)
+
+	Equal(t, FieldSpec{
+		Name:      "name",
+		Namespace: "name",
+		Field:     "Name",
+		Type:      reflect.TypeOf(""),
+		Kind:      reflect.String,
+		Aliases:   []string{"fullName"},
+		Options:   []TagOption{{Name: "omitempty"}, {Name: "alt", Value: "fullName"}},
+	}, spec[1])
+
+	Equal(t, reflect.Struct, spec[3].Kind)
+	True(t, spec[3].Nested)
+	False(t, spec[4].Nested)
+	Equal(t, reflect.TypeOf(&specAddress{}), spec[3].Type)
+	Equal(t, []TagOption{{Name: "min", Value: "2"}}, spec[4].Options)
+	Equal(t, "City", spec[4].Field)
+
+	decoder.SetKeyStyle(StyleRails)
+
+	spec, err = decoder.StructSpec(reflect.TypeOf(specUser{}))
+	NoError(t, err)
+	Equal(t, "items[][city]", spec[6].Namespace)
+
+	_, err = decoder.StructSpec(reflect.TypeOf(1))
+	NotNil(t, err)
+	True(t, errors.Is(err, ErrUnsupportedType))
+}
@@ -0,0 +1,60 @@
+package form
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+)
+
+// valueType converts a struct type without text methods to and from a single value.
+type valueType struct {
+	decode func(s string) (interface{}, error)
+	encode func(v interface{}) string
+}
+
+// valueTypes are standard library structs decoded and encoded as single values, as time.Time is.
+// Types implementing text methods, eg. netip.Addr, netip.AddrPort, netip.Prefix and net.IP,
+// are converted with them. Registered custom type functions take precedence.
+var valueTypes = map[reflect.Type]valueType{
+	reflect.TypeOf(url.URL{}): {
+		decode: func(s string) (interface{}, error) {
+			u, err := url.Parse(s)
+			if err != nil {
+				return nil, err
+			}
+
+			return *u, nil
+		},
+		encode: func(v interface{}) string {
+			u := v.(url.URL)
+
+			return u.String()
+		},
+	},
+	reflect.TypeOf(mail.Address{}): {
+		decode: func(s string) (interface{}, error) {
+			a, err := mail.ParseAddress(s)
+			if err != nil {
+				return nil, err
+			}
+
+			return *a, nil
+		},
+		encode: func(v interface{}) string {
+			a := v.(mail.Address)
+
+			return a.String()
+		},
+	},
+}
+
+// isValueStruct reports whether a struct type is a single value instead of fields, see valueTypes.
+func isValueStruct(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+
+	_, ok := valueTypes[t]
+
+	return ok
+}
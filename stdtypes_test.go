@@ -0,0 +1,89 @@
+package form
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestStdlibValueTypes(t *testing.T) {
+	t.Parallel()
+
+	type Filter struct {
+		Addr     netip.Addr              `form:"addr"`
+		AddrPort netip.AddrPort          `form:"addr_port"`
+		Prefix   netip.Prefix            `form:"prefix"`
+		IP       net.IP                  `form:"ip"`
+		IPs      []net.IP                `form:"ips"`
+		URL      *url.URL                `form:"url"`
+		Homepage url.URL                 `form:"homepage"`
+		Links    []*url.URL              `form:"links"`
+		Contact  mail.Address            `form:"contact"`
+		CC       *mail.Address           `form:"cc"`
+		Routes   map[string]netip.Prefix `form:"routes"`
+		Unset    *url.URL                `form:"unset"`
+	}
+
+	values := url.Values{
+		"addr":        {"192.168.1.1"},
+		"addr_port":   {"[::1]:8080"},
+		"prefix":      {"10.0.0.0/8"},
+		"ip":          {"2001:db8::1"},
+		"ips":         {"1.1.1.1", "8.8.8.8"},
+		"url":         {"https://example.com/path?q=1"},
+		"homepage":    {"http://example.org"},
+		"links":       {"https://a.example", "https://b.example"},
+		"contact":     {"Bob <bob@example.com>"},
+		"cc":          {"alice@example.com"},
+		"routes[lan]": {"192.168.0.0/16"},
+	}
+
+	var filter Filter
+
+	NoError(t, NewDecoder[any]().Decode(&filter, values, nil))
+	Equal(t, netip.MustParseAddr("192.168.1.1"), filter.Addr)
+	Equal(t, netip.MustParseAddrPort("[::1]:8080"), filter.AddrPort)
+	Equal(t, netip.MustParsePrefix("10.0.0.0/8"), filter.Prefix)
+	Equal(t, net.ParseIP("2001:db8::1"), filter.IP)
+	Equal(t, []net.IP{net.ParseIP("1.1.1.1"), net.ParseIP("8.8.8.8")}, filter.IPs)
+	Equal(t, "https://example.com/path?q=1", filter.URL.String())
+	Equal(t, "example.org", filter.Homepage.Host)
+	Equal(t, 2, len(filter.Links))
+	Equal(t, "b.example", filter.Links[1].Host)
+	Equal(t, mail.Address{Name: "Bob", Address: "bob@example.com"}, filter.Contact)
+	Equal(t, &mail.Address{Address: "alice@example.com"}, filter.CC)
+	Equal(t, map[string]netip.Prefix{"lan": netip.MustParsePrefix("192.168.0.0/16")}, filter.Routes)
+	Nil(t, filter.Unset)
+
+	encoded, err := NewEncoder().Encode(filter)
+	NoError(t, err)
+	Equal(t, url.Values{
+		"addr":        {"192.168.1.1"},
+		"addr_port":   {"[::1]:8080"},
+		"prefix":      {"10.0.0.0/8"},
+		"ip":          {"2001:db8::1"},
+		"ips[0]":      {"1.1.1.1"},
+		"ips[1]":      {"8.8.8.8"},
+		"url":         {"https://example.com/path?q=1"},
+		"homepage":    {"http://example.org"},
+		"links[0]":    {"https://a.example"},
+		"links[1]":    {"https://b.example"},
+		"contact":     {`"Bob" <bob@example.com>`},
+		"cc":          {"<alice@example.com>"},
+		"routes[lan]": {"192.168.0.0/16"},
+	}, encoded)
+
+	err = NewDecoder[any]().Decode(&filter, url.Values{"url": {"http://[::1"}, "contact": {"not an address"}, "addr": {"x"}}, nil)
+	NotNil(t, err)
+
+	errs := err.(DecodeErrors)
+	Equal(t, 3, len(errs))
+	Contains(t, errs["contact"].Error(), "invalid value 'not an address' type 'mail.Address' namespace 'contact'")
+	True(t, errors.Is(errs["contact"], ErrInvalidValue))
+	True(t, errors.Is(errs["url"], ErrInvalidValue))
+}
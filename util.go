@@ -56,6 +56,11 @@ func hasValue(field reflect.Value) bool {
 			return true
 		}
 
+		// values of unexported embedded structs can not be compared through Interface
+		if !field.CanInterface() {
+			return !field.IsZero()
+		}
+
 		return field.Interface() != reflect.Zero(field.Type()).Interface()
 	}
 }
@@ -0,0 +1,94 @@
+package form
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UUID is a universally unique identifier, it can be used for fields directly or converted
+// to and from other [16]byte UUID types.
+//
+// Fields of [16]byte types implementing encoding.TextUnmarshaler, eg. google/uuid or gofrs/uuid,
+// are decoded with their own methods, [16]byte types implementing only fmt.Stringer are decoded as UUID.
+type UUID [16]byte
+
+// ParseUUID parses a UUID in canonical form, eg. "550e8400-e29b-41d4-a716-446655440000",
+// without hyphens, in braces or with "urn:uuid:" prefix. Invalid UUIDs are reported with ErrInvalidValue.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+
+	t := s
+
+	switch {
+	case len(t) == 45 && strings.EqualFold(t[:9], "urn:uuid:"):
+		t = t[9:]
+	case len(t) == 38 && t[0] == '{' && t[37] == '}':
+		t = t[1:37]
+	}
+
+	if len(t) == 36 {
+		if t[8] != '-' || t[13] != '-' || t[18] != '-' || t[23] != '-' {
+			return u, newError(ErrInvalidValue, "invalid UUID '%s'", s)
+		}
+
+		t = t[:8] + t[9:13] + t[14:18] + t[19:23] + t[24:]
+	}
+
+	if len(t) != 32 {
+		return u, newError(ErrInvalidValue, "invalid UUID length of '%s'", s)
+	}
+
+	if _, err := hex.Decode(u[:], []byte(t)); err != nil {
+		return u, newError(ErrInvalidValue, "invalid UUID '%s'", s)
+	}
+
+	return u, nil
+}
+
+// String returns the canonical form of UUID.
+func (u UUID) String() string {
+	var b [36]byte
+
+	hex.Encode(b[:8], u[:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+
+	return string(b[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = parsed
+
+	return nil
+}
+
+var (
+	uuidType     = reflect.TypeOf(UUID{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// isUUIDType reports whether type is a [16]byte UUID implementing only fmt.Stringer,
+// types with text methods are converted with them.
+func isUUIDType(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 &&
+		t.Implements(stringerType)
+}
@@ -0,0 +1,110 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+// stringerID is a [16]byte UUID type without text methods.
+type stringerID [16]byte
+
+func (id stringerID) String() string {
+	return UUID(id).String()
+}
+
+// textID is a [16]byte UUID type with text methods, as google/uuid is.
+type textID [16]byte
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte("text:" + UUID(id).String()), nil
+}
+
+func (id *textID) UnmarshalText(text []byte) error {
+	u, err := ParseUUID(string(text[len("text:"):]))
+	*id = textID(u)
+
+	return err
+}
+
+func TestParseUUID(t *testing.T) {
+	t.Parallel()
+
+	expected := UUID{0x55, 0x0e, 0x84, 0x00, 0xe2, 0x9b, 0x41, 0xd4, 0xa7, 0x16, 0x44, 0x66, 0x55, 0x44, 0x00, 0x00}
+
+	for _, s := range []string{
+		"550e8400-e29b-41d4-a716-446655440000",
+		"550E8400-E29B-41D4-A716-446655440000",
+		"550e8400e29b41d4a716446655440000",
+		"{550e8400-e29b-41d4-a716-446655440000}",
+		"urn:uuid:550e8400-e29b-41d4-a716-446655440000",
+	} {
+		u, err := ParseUUID(s)
+		NoError(t, err)
+		Equal(t, expected, u)
+	}
+
+	Equal(t, "550e8400-e29b-41d4-a716-446655440000", expected.String())
+
+	for _, s := range []string{"", "550e8400", "550e8400-e29b-41d4-a716_446655440000", "550e8400-e29b-41d4-a716-44665544000g"} {
+		_, err := ParseUUID(s)
+		NotNil(t, err)
+		True(t, errors.Is(err, ErrInvalidValue))
+	}
+}
+
+func TestUUIDFields(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		ID       UUID            `form:"id"`
+		Ref      *UUID           `form:"ref"`
+		Stringer stringerID      `form:"stringer"`
+		Text     textID          `form:"text"`
+		IDs      []stringerID    `form:"ids"`
+		ByID     map[UUID]string `form:"by_id"`
+		Bytes    [16]byte        `form:"bytes"`
+		Missing  *stringerID     `form:"missing"`
+	}
+
+	const id = "550e8400-e29b-41d4-a716-446655440000"
+
+	u, err := ParseUUID(id)
+	NoError(t, err)
+
+	var test Test
+
+	err = NewDecoder[any]().Decode(&test, url.Values{
+		"id":                {id},
+		"ref":               {id},
+		"stringer":          {id},
+		"text":              {"text:" + id},
+		"ids":               {id, id},
+		"by_id[" + id + "]": {"x"},
+		"bytes[1]":          {"7"},
+	}, nil)
+	NoError(t, err)
+	Equal(t, u, test.ID)
+	Equal(t, u, *test.Ref)
+	Equal(t, stringerID(u), test.Stringer)
+	Equal(t, textID(u), test.Text)
+	Equal(t, []stringerID{stringerID(u), stringerID(u)}, test.IDs)
+	Equal(t, map[UUID]string{u: "x"}, test.ByID)
+	Equal(t, [16]byte{1: 7}, test.Bytes)
+	Nil(t, test.Missing)
+
+	values, err := NewEncoder().Encode(test)
+	NoError(t, err)
+	Equal(t, id, values.Get("id"))
+	Equal(t, id, values.Get("ref"))
+	Equal(t, id, values.Get("stringer"))
+	Equal(t, "text:"+id, values.Get("text"))
+	Equal(t, []string{id, id}, values["ids"])
+	Equal(t, "x", values.Get("by_id["+id+"]"))
+
+	err = NewDecoder[any]().Decode(&test, url.Values{"stringer": {"nope"}, "id": {"nope"}}, nil)
+	NotNil(t, err)
+	Equal(t, 2, len(err.(DecodeErrors)))
+}
@@ -0,0 +1,32 @@
+package form
+
+// ValidateFunc validates a decoded value, see Decoder.SetValidator.
+type ValidateFunc func(v interface{}) error
+
+// ValidationError is returned when the validator set with Decoder.SetValidator fails,
+// the validator error is available with Unwrap or errors.As, eg. validator.ValidationErrors.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return "form: validation failed: " + e.Err.Error()
+}
+
+// Unwrap returns the validator error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validate runs the validator, if any, on the decoded value.
+func (d *Decoder[DecodeFuncArgument]) validate(v interface{}) error {
+	if d.validator == nil {
+		return nil
+	}
+
+	if err := d.validator(v); err != nil {
+		return &ValidationError{Err: err}
+	}
+
+	return nil
+}
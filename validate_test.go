@@ -0,0 +1,92 @@
+package form
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+type validateUser struct {
+	Name string `form:"name"`
+	Age  int    `form:"age"`
+}
+
+var errNameRequired = errors.New("name is required")
+
+func validateUserFunc(v interface{}) error {
+	if v.(*validateUser).Name == "" {
+		return errNameRequired
+	}
+
+	return nil
+}
+
+func TestDecoder_SetValidator(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder[any]()
+	decoder.SetValidator(validateUserFunc)
+
+	var user validateUser
+
+	err := decoder.Decode(&user, url.Values{"name": {"joe"}, "age": {"3"}}, nil)
+	NoError(t, err)
+	Equal(t, validateUser{Name: "joe", Age: 3}, user)
+
+	user = validateUser{}
+
+	err = decoder.Decode(&user, url.Values{"age": {"3"}}, nil)
+	NotNil(t, err)
+
+	var ve *ValidationError
+
+	True(t, errors.As(err, &ve))
+	True(t, errors.Is(err, errNameRequired))
+	Equal(t, "form: validation failed: name is required", err.Error())
+
+	// validation is not run when decoding fails
+	err = decoder.Decode(&user, url.Values{"age": {"x"}}, nil)
+	NotNil(t, err)
+	False(t, errors.As(err, &ve))
+
+	var de DecodeErrors
+
+	True(t, errors.As(err, &de))
+
+	// clones keep the validator
+	err = decoder.Clone().Decode(&validateUser{}, url.Values{}, nil)
+	True(t, errors.Is(err, errNameRequired))
+
+	err = NewDecoder[any]().Decode(&validateUser{}, url.Values{}, nil)
+	NoError(t, err)
+}
+
+func TestDecoder_SetValidatorRequest(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder[any]()
+	decoder.SetValidator(validateUserFunc)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"age":3}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	_, err := BindRequest[validateUser](decoder, r, nil)
+	True(t, errors.Is(err, errNameRequired))
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"age":"x"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	_, err = BindRequest[validateUser](decoder, r, nil)
+	NotNil(t, err)
+	False(t, errors.Is(err, errNameRequired))
+
+	r = httptest.NewRequest("GET", "/?name=joe", nil)
+
+	user, err := DecodeRequest[validateUser](decoder, r, nil)
+	NoError(t, err)
+	Equal(t, "joe", user.Name)
+}